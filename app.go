@@ -0,0 +1,113 @@
+package clui
+
+import (
+	"log"
+	"sync"
+)
+
+/*
+App is an independent user interface: its own screen, windows, theme,
+timers, hotkeys and main loop. One process can run several Apps at the
+same time, e.g one per network connection, each with its own Backend and
+its own main loop goroutine.
+
+An App draws on its Canvas with the colors of its ThemeManager, so App
+has all their methods, e.g App.DrawText or App.SysColor. The package
+functions(AddWindow, DrawText, Invoke, SetThemePath, etc.) call the
+methods of the default App created by InitLibrary.
+
+A control belongs to the App of its Window. Controls get their App with
+AppOf, and a custom control must do the same to draw itself or to open
+a dialog from an event handler:
+
+	func (c *MyControl) Draw() {
+	    a := clui.AppOf(c)
+	    a.SetTextColor(a.SysColor(clui.ColorText))
+	    a.DrawText(c.x, c.y, "text")
+	}
+
+Like controls, an App is not thread safe: build its user interface before
+the main loop starts, and after that change it from event handlers,
+timers, and closures scheduled with App.Invoke
+*/
+type App struct {
+	*Canvas
+	*ThemeManager
+	comp    *Composer
+	loop    *mainLoop
+	timers  *timerList
+	hotkeys *hotkeyMap
+	// see SetDoubleClickInterval and SetTooltipDelay
+	doubleClickInterval int64
+	tooltipDelay        int64
+	// see SetLogger
+	logger    *log.Logger
+	loggerMtx sync.Mutex
+}
+
+var (
+	// the App created by InitLibrary
+	defaultApp *App
+)
+
+// NewApp creates a new App that draws on the backend. If backend is nil
+// the default termbox one is used. Returns an error if the backend fails
+// to initialize
+func NewApp(backend Backend) (*App, error) {
+	return newApp(backend, newHotkeyMap())
+}
+
+func newApp(backend Backend, hotkeys *hotkeyMap) (*App, error) {
+	if backend == nil {
+		backend = DefaultBackend()
+	}
+
+	a := &App{
+		hotkeys:             hotkeys,
+		doubleClickInterval: int64(DefaultDoubleClickInterval),
+		tooltipDelay:        int64(DefaultTooltipDelay),
+	}
+	a.ThemeManager = newThemeManager()
+	a.comp = newComposer(a)
+	a.loop = newMainLoop(a)
+	a.timers = newTimerList(a.loop)
+
+	canvas, err := newCanvas(backend, a.ThemeManager)
+	if err != nil {
+		return nil, err
+	}
+	a.Canvas = canvas
+	return a, nil
+}
+
+// Close stops all timers of the App and closes its backend
+func (a *App) Close() {
+	a.timers.stopAll()
+	a.loop.close()
+	a.backend.Close()
+}
+
+// appOwner is a control that knows its App: a Window or a drop-down list
+type appOwner interface {
+	ownerApp() *App
+}
+
+func (c *BaseControl) ownerApp() *App {
+	return c.app
+}
+
+// AppOf returns the App the control belongs to: the App of its Window.
+// The controls that are not inside a Window of any App yet belong to the
+// default App
+func AppOf(c Control) *App {
+	for c != nil {
+		if o, ok := c.(appOwner); ok {
+			if a := o.ownerApp(); a != nil {
+				return a
+			}
+		}
+		c = c.Parent()
+	}
+
+	return defaultApp
+}
@@ -0,0 +1,72 @@
+package clui
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIndependentApps(t *testing.T) {
+	for i := 0; i < 3; i++ {
+		want := strings.Repeat(string(rune('a'+i)), 3)
+		t.Run(want, func(t *testing.T) {
+			t.Parallel()
+
+			b := NewHeadlessBackend(30, 8)
+			app, err := NewApp(b)
+			if err != nil {
+				t.Fatalf("Failed to create App: %v", err)
+			}
+			defer app.Close()
+
+			wnd := app.AddWindow(0, 0, 20, 5, "Session")
+			frame := CreateFrame(wnd, AutoSize, AutoSize, BorderNone, 1)
+			edit := CreateEditField(frame, 10, "", Fixed)
+			ActivateControl(wnd, edit)
+			if AppOf(edit) != app || AppOf(wnd) != app {
+				t.Fatal("Controls must belong to the App of their window")
+			}
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				app.MainLoop()
+			}()
+
+			fired := make(chan struct{})
+			app.AfterFunc(10*time.Millisecond, func() {
+				close(fired)
+			})
+			for _, ch := range want {
+				b.PostEvent(Event{Type: EventKey, Ch: ch})
+			}
+			select {
+			case <-fired:
+			case <-time.After(time.Second):
+				t.Fatal("Timers must fire in their App")
+			}
+
+			var text, screen string
+			for start := time.Now(); time.Since(start) < time.Second; time.Sleep(10 * time.Millisecond) {
+				res := make(chan [2]string)
+				app.Invoke(func() {
+					res <- [2]string{edit.Title(), b.Snapshot()}
+				})
+				r := <-res
+				text, screen = r[0], r[1]
+				if text == want && strings.Contains(screen, want) {
+					break
+				}
+			}
+			if text != want {
+				t.Errorf("Session must receive only its own keys: %q", text)
+			}
+			if !strings.Contains(screen, want) {
+				t.Errorf("Session must be painted on its own screen:\n%v", screen)
+			}
+
+			app.Stop()
+			<-done
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package clui
+
+import (
+	term "github.com/nsf/termbox-go"
+)
+
+/*
+Backend is an interface to a terminal or any other surface that is able to
+display a grid of character cells and to produce input events. The library
+draws everything through the active Backend, so an application can run clui
+on top of a terminal library other than termbox or without a real terminal
+at all (e.g, in tests).
+
+Colors and cells use termbox types because they are a part of the library
+API. A Backend that is not based on termbox must convert them itself.
+*/
+type Backend interface {
+	// Init prepares the backend for drawing and reading events
+	Init() error
+	// Close releases all resources and restores the terminal state
+	Close()
+	// Size returns the current screen width and height
+	Size() (width int, height int)
+	// SetCell changes the rune and colors of a screen cell
+	SetCell(x, y int, ch rune, fg, bg term.Attribute)
+	// Cell returns the rune and colors of a screen cell from the back
+	// buffer - what is going to be displayed after the next Flush
+	Cell(x, y int) term.Cell
+	// Clear fills the whole back buffer with spaces of the given colors
+	Clear(fg, bg term.Attribute) error
+	// SetCursor shows the text caret at given position. Use HideCursor
+	// to make the caret invisible
+	SetCursor(x, y int)
+	// HideCursor makes the text caret invisible
+	HideCursor()
+	// Flush synchronizes the back buffer with the screen
+	Flush() error
+	// PollEvent waits for the next input event and returns it
+	PollEvent() Event
+	// SetColorMode changes the number of colors used to draw cells and
+	// returns the new mode. ColorModeCurrent just returns the current mode.
+	// Canvas converts all colors to the current mode before calling SetCell
+	SetColorMode(mode ColorMode) ColorMode
+}
+
+// MouseMotionBackend is an optional interface of a Backend that is able
+// to report mouse movements without pressed buttons. The library turns the
+// reporting on after the backend is initialized, so controls receive
+// EventMouseEnter and EventMouseLeave as soon as the mouse cursor moves.
+// With other backends hover changes only on mouse clicks and drags
+type MouseMotionBackend interface {
+	// SetMouseMotion turns on or off reporting of all mouse movements
+	SetMouseMotion(on bool) error
+}
+
+// DefaultBackend returns a Backend that uses termbox library. It is used
+// by InitLibrary if no backend is provided. The backend does not turn on
+// 24-bit colors(see NewTermboxBackend)
+func DefaultBackend() Backend {
+	return NewTermboxBackend(false)
+}
+
+// NewTermboxBackend returns a Backend that uses termbox library. If
+// trueColor is true and the terminal supports 24-bit colors(see
+// DetectColorMode), the screen starts in ColorModeRGB. Otherwise RGB
+// colors are displayed as the closest 256 ones: in ColorModeRGB base
+// colors have standard RGB values instead of the terminal palette ones
+func NewTermboxBackend(trueColor bool) Backend {
+	return &termboxBackend{trueColor: trueColor}
+}
@@ -0,0 +1,273 @@
+package clui
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	term "github.com/nsf/termbox-go"
+)
+
+/*
+HeadlessBackend is a Backend that keeps the screen in memory and never
+touches a real terminal. It records every cell (rune, text and background
+colors) and makes it possible to run and test an application without TTY:
+inject synthetic keyboard and mouse events with SimulateEvent, SimulateKey
+and SimulateClick, and then compare the screen with a saved text snapshot
+with CompareSnapshot.
+
+Like a terminal, the backend has two buffers: controls draw to the back one,
+and Flush copies it to the screen. Snapshot and ScreenCell read the screen.
+*/
+type HeadlessBackend struct {
+	mtx              sync.RWMutex
+	width, height    int
+	back             []term.Cell
+	front            []term.Cell
+	cursorX, cursorY int
+	colorMode        ColorMode
+	events           chan Event
+}
+
+// NewHeadlessBackend creates a new in-memory screen of a given size
+func NewHeadlessBackend(width, height int) *HeadlessBackend {
+	b := new(HeadlessBackend)
+	b.events = make(chan Event, 64)
+	b.cursorX, b.cursorY = -1, -1
+	b.colorMode = ColorModeNormal
+	b.resizeBuffers(width, height)
+	return b
+}
+
+func (b *HeadlessBackend) resizeBuffers(width, height int) {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	b.width, b.height = width, height
+	b.back = make([]term.Cell, width*height)
+	b.front = make([]term.Cell, width*height)
+	for i := range b.back {
+		b.back[i] = term.Cell{Ch: ' ', Fg: ColorWhite, Bg: ColorBlack}
+		b.front[i] = b.back[i]
+	}
+}
+
+func (b *HeadlessBackend) Init() error {
+	return nil
+}
+
+func (b *HeadlessBackend) Close() {
+}
+
+func (b *HeadlessBackend) Size() (width int, height int) {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	return b.width, b.height
+}
+
+func (b *HeadlessBackend) SetCell(x, y int, ch rune, fg, bg term.Attribute) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return
+	}
+	b.back[y*b.width+x] = term.Cell{Ch: ch, Fg: fg, Bg: bg}
+}
+
+func (b *HeadlessBackend) Cell(x, y int) term.Cell {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return term.Cell{Ch: ' '}
+	}
+	return b.back[y*b.width+x]
+}
+
+func (b *HeadlessBackend) Clear(fg, bg term.Attribute) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for i := range b.back {
+		b.back[i] = term.Cell{Ch: ' ', Fg: fg, Bg: bg}
+	}
+	return nil
+}
+
+func (b *HeadlessBackend) SetCursor(x, y int) {
+	b.mtx.Lock()
+	b.cursorX, b.cursorY = x, y
+	b.mtx.Unlock()
+}
+
+func (b *HeadlessBackend) HideCursor() {
+	b.SetCursor(-1, -1)
+}
+
+func (b *HeadlessBackend) Flush() error {
+	b.mtx.Lock()
+	copy(b.front, b.back)
+	b.mtx.Unlock()
+	return nil
+}
+
+// SetColorMode changes the color mode. The mode affects only colors that
+// are recorded in cells. By default the backend works in ColorModeNormal
+func (b *HeadlessBackend) SetColorMode(mode ColorMode) ColorMode {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if mode != ColorModeCurrent {
+		b.colorMode = mode
+	}
+	return b.colorMode
+}
+
+// PollEvent waits for an event posted with PostEvent
+func (b *HeadlessBackend) PollEvent() Event {
+	return <-b.events
+}
+
+// PostEvent puts an event to the backend input queue. The event is
+// processed by MainLoop as if it came from a terminal. Use SimulateEvent
+// to process an event synchronously when MainLoop is not running
+func (b *HeadlessBackend) PostEvent(ev Event) {
+	b.events <- ev
+}
+
+// Resize changes the screen size and posts the resize event to the
+// backend input queue
+func (b *HeadlessBackend) Resize(width, height int) {
+	b.mtx.Lock()
+	b.resizeBuffers(width, height)
+	b.mtx.Unlock()
+
+	b.PostEvent(Event{Type: EventResize, Width: width, Height: height})
+}
+
+// CursorPos returns the current text caret position. Both coordinates
+// are -1 if the caret is hidden
+func (b *HeadlessBackend) CursorPos() (x int, y int) {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+	return b.cursorX, b.cursorY
+}
+
+// ScreenCell returns the rune and colors of a cell that was displayed by
+// the last Flush
+func (b *HeadlessBackend) ScreenCell(x, y int) term.Cell {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return term.Cell{Ch: ' '}
+	}
+	return b.front[y*b.width+x]
+}
+
+// Snapshot returns the text displayed on the screen: one line per screen
+// row with trailing spaces trimmed. Colors are not included. Like
+// a terminal, the cell after a wide character is not displayed
+func (b *HeadlessBackend) Snapshot() string {
+	b.mtx.RLock()
+	defer b.mtx.RUnlock()
+
+	var sb strings.Builder
+	line := make([]rune, 0, b.width)
+	for y := 0; y < b.height; y++ {
+		line = line[:0]
+		for x := 0; x < b.width; x++ {
+			ch := b.front[y*b.width+x].Ch
+			if ch == 0 {
+				ch = ' '
+			}
+			line = append(line, ch)
+			if RuneWidth(ch) == 2 {
+				x++
+			}
+		}
+		sb.WriteString(strings.TrimRight(string(line), " "))
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// CompareSnapshot compares the current screen with the text snapshot saved
+// in a golden file. If update is true, the golden file is overwritten with
+// the current screen instead. Returns an error that describes the first
+// different line if the screen does not match the file
+func (b *HeadlessBackend) CompareSnapshot(goldenFile string, update bool) error {
+	got := b.Snapshot()
+	if update {
+		return ioutil.WriteFile(goldenFile, []byte(got), 0644)
+	}
+
+	data, err := ioutil.ReadFile(goldenFile)
+	if err != nil {
+		return err
+	}
+	want := strings.Replace(string(data), "\r\n", "\n", -1)
+	if want == got {
+		return nil
+	}
+
+	gotLines := strings.Split(got, "\n")
+	wantLines := strings.Split(want, "\n")
+	for i := 0; i < len(gotLines) || i < len(wantLines); i++ {
+		var g, w string
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if g != w {
+			return fmt.Errorf("%s: line %d differs:\n got: %q\nwant: %q", goldenFile, i+1, g, w)
+		}
+	}
+
+	return fmt.Errorf("%s: snapshot mismatch", goldenFile)
+}
+
+// SimulateEvent processes the event synchronously as if it came from the
+// main loop and then repaints the screen. Useful to drive the UI from tests
+// without starting MainLoop
+func (a *App) SimulateEvent(ev Event) {
+	a.ProcessEvent(ev)
+	a.RefreshScreen()
+}
+
+// SimulateKey emulates a key press. For printable characters pass ch and
+// zero key, for special keys pass key and zero ch
+func (a *App) SimulateKey(key term.Key, ch rune) {
+	a.SimulateEvent(Event{Type: EventKey, Key: key, Ch: ch})
+}
+
+// SimulateClick emulates a left mouse button click at screen coordinates:
+// button press followed by button release
+func (a *App) SimulateClick(x, y int) {
+	a.SimulateEvent(Event{Type: EventMouse, Key: term.MouseLeft, X: x, Y: y})
+	a.SimulateEvent(Event{Type: EventMouse, Key: term.MouseRelease, X: x, Y: y})
+}
+
+// SimulateEvent calls App.SimulateEvent of the default App
+func SimulateEvent(ev Event) {
+	defaultApp.SimulateEvent(ev)
+}
+
+// SimulateKey calls App.SimulateKey of the default App
+func SimulateKey(key term.Key, ch rune) {
+	defaultApp.SimulateKey(key, ch)
+}
+
+// SimulateClick calls App.SimulateClick of the default App
+func SimulateClick(x, y int) {
+	defaultApp.SimulateClick(x, y)
+}
@@ -0,0 +1,790 @@
+package clui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	term "github.com/nsf/termbox-go"
+)
+
+// TerminalSize is the size of a remote terminal in character cells, e.g
+// from SSH "pty-req" and "window-change" requests
+type TerminalSize struct {
+	Width, Height int
+}
+
+/*
+StreamBackend is a Backend that drives a remote terminal over any
+io.ReadWriter: an SSH channel, a TCP connection, etc. It decodes the bytes
+the terminal sends(characters, escape sequences of special keys, xterm
+mouse reports) to events, and writes ANSI escape sequences to draw the
+screen. Flush sends only the cells changed since the previous Flush.
+
+The stream does not report terminal size, so the size is passed to
+NewStreamBackend, and every new size received from the resize channel
+generates EventResize. The remote terminal must be in raw mode: SSH
+clients do it when they request a PTY. The color mode is ColorModeNormal,
+call SetColorMode before creating an App to use more colors, e.g if TERM
+of the remote terminal contains "256color".
+
+Every connection needs its own App(see RunStream):
+
+	// inside SSH session handler after a PTY is requested
+	err := clui.RunStream(ctx, channel, size, resizes, func(app *clui.App) {
+	    wnd := app.AddWindow(0, 0, 40, 10, "Tool")
+	    ...
+	})
+
+The backend writes to the stream in its own goroutine, so a slow or
+stalled connection does not block NewApp or the main loop. If screen
+updates pile up faster than the stream sends them, the queued updates are
+dropped and the next Flush repaints the whole screen. Close restores
+the terminal state and waits until the output is written(but not longer
+than a second), it does not close the stream.
+*/
+type StreamBackend struct {
+	rw     io.ReadWriter
+	resize <-chan TerminalSize
+
+	mtx              sync.Mutex
+	width, height    int
+	back             []term.Cell
+	front            []term.Cell
+	cursorX, cursorY int
+	colorMode        ColorMode
+	// the screen must be cleared and repainted at the next Flush
+	redrawAll bool
+
+	events    chan Event
+	done      chan struct{}
+	closeOnce sync.Once
+
+	// output queued for the writer goroutine(see write). The first
+	// outKeep bytes are not screen updates and cannot be dropped
+	outMtx    sync.Mutex
+	out       []byte
+	outKeep   int
+	outErr    error
+	outClosed bool
+	outWake   chan struct{}
+	// closed when the writer goroutine exits, nil until Init starts it
+	outDone chan struct{}
+}
+
+// escapeDelay is how long a lone ESC waits for the rest of an escape
+// sequence before it becomes KeyEsc
+const escapeDelay = 100 * time.Millisecond
+
+// streamCloseTimeout is how long Close waits for the output to be written
+const streamCloseTimeout = time.Second
+
+// streamMaxQueue is the size of queued screen updates after which they are
+// dropped and replaced with one full repaint
+const streamMaxQueue = 1 << 20
+
+// errStreamClosed is returned by PollEvent after the backend is closed
+var errStreamClosed = errors.New("stream backend is closed")
+
+// invalidCell never equals a drawn cell. It marks the screen cells that
+// must be redrawn by the next Flush, e.g the right half of a wide character
+var invalidCell = term.Cell{Ch: -1}
+
+// NewStreamBackend creates a backend for a remote terminal of the given
+// size. resize can be nil if the terminal size never changes
+func NewStreamBackend(rw io.ReadWriter, size TerminalSize, resize <-chan TerminalSize) *StreamBackend {
+	b := new(StreamBackend)
+	b.rw = rw
+	b.resize = resize
+	b.events = make(chan Event, 64)
+	b.done = make(chan struct{})
+	b.outWake = make(chan struct{}, 1)
+	b.cursorX, b.cursorY = -1, -1
+	b.colorMode = ColorModeNormal
+	b.resizeBuffers(size.Width, size.Height)
+	return b
+}
+
+func (b *StreamBackend) resizeBuffers(width, height int) {
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	b.width, b.height = width, height
+	b.back = make([]term.Cell, width*height)
+	b.front = make([]term.Cell, width*height)
+	for i := range b.back {
+		b.back[i] = term.Cell{Ch: ' ', Fg: ColorWhite, Bg: ColorBlack}
+	}
+	b.redrawAll = true
+}
+
+// Init switches the terminal to the alternate screen, turns on mouse
+// reporting, and starts reading input and writing output. A write error is
+// reported later as EventError
+func (b *StreamBackend) Init() error {
+	b.outDone = make(chan struct{})
+	go b.writeOutput()
+	// alternate screen, hidden caret, mouse clicks and drags in SGR
+	// encoding
+	b.write([]byte("\x1b[?1049h\x1b[?25l\x1b[?1000h\x1b[?1002h\x1b[?1006h"))
+
+	go b.readInput()
+	if b.resize != nil {
+		go b.watchResize()
+	}
+	return nil
+}
+
+// SetMouseMotion turns on or off reporting mouse movements without pressed
+// buttons(see MouseMotionBackend)
+func (b *StreamBackend) SetMouseMotion(on bool) error {
+	seq := "\x1b[?1003l"
+	if on {
+		seq = "\x1b[?1003h"
+	}
+	return b.write([]byte(seq))
+}
+
+// Close restores the terminal state, stops reading input, and waits until
+// the writer goroutine sends the queued output. If the stream is stalled,
+// Close returns after streamCloseTimeout. The stream is left open
+func (b *StreamBackend) Close() {
+	b.closeOnce.Do(func() {
+		close(b.done)
+		if b.outDone == nil {
+			return
+		}
+
+		b.write([]byte("\x1b[?1006l\x1b[?1003l\x1b[?1002l\x1b[?1000l\x1b[0m\x1b[?25h\x1b[?1049l"))
+		b.outMtx.Lock()
+		b.outClosed = true
+		b.outMtx.Unlock()
+		b.wakeWriter()
+		select {
+		case <-b.outDone:
+		case <-time.After(streamCloseTimeout):
+		}
+	})
+}
+
+// write queues the data for the writer goroutine. Returns the error of
+// a previous write: after an error the output is dropped
+func (b *StreamBackend) write(data []byte) error {
+	return b.queue(data, true)
+}
+
+// queue appends the data to the output. Screen updates are queued with
+// keep false, so dropFrames can remove them if the stream is too slow
+func (b *StreamBackend) queue(data []byte, keep bool) error {
+	b.outMtx.Lock()
+	err := b.outErr
+	if err == nil {
+		b.out = append(b.out, data...)
+		if keep {
+			b.outKeep = len(b.out)
+		}
+	}
+	b.outMtx.Unlock()
+
+	b.wakeWriter()
+	return err
+}
+
+// dropFrames removes the queued screen updates if the stream is so slow
+// that they exceed streamMaxQueue. Returns true if the updates were
+// dropped, so the next one must repaint the whole screen
+func (b *StreamBackend) dropFrames() bool {
+	b.outMtx.Lock()
+	defer b.outMtx.Unlock()
+
+	if len(b.out)-b.outKeep <= streamMaxQueue {
+		return false
+	}
+	b.out = b.out[:b.outKeep]
+	return true
+}
+
+func (b *StreamBackend) wakeWriter() {
+	select {
+	case b.outWake <- struct{}{}:
+	default:
+	}
+}
+
+// writeOutput sends the queued output to the stream. The output queued
+// while the previous write is in progress is sent with one write. The
+// goroutine exits after Close when the queue is empty, or when the stream
+// fails
+func (b *StreamBackend) writeOutput() {
+	defer close(b.outDone)
+
+	for {
+		b.outMtx.Lock()
+		data, closed := b.out, b.outClosed
+		b.out, b.outKeep = nil, 0
+		b.outMtx.Unlock()
+
+		if len(data) != 0 {
+			if _, err := b.rw.Write(data); err != nil {
+				b.outMtx.Lock()
+				b.outErr = err
+				b.out, b.outKeep = nil, 0
+				b.outMtx.Unlock()
+				b.post(Event{Type: EventError, Err: err})
+				return
+			}
+			continue
+		}
+		if closed {
+			return
+		}
+		<-b.outWake
+	}
+}
+
+func (b *StreamBackend) Size() (width int, height int) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.width, b.height
+}
+
+func (b *StreamBackend) SetCell(x, y int, ch rune, fg, bg term.Attribute) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return
+	}
+	b.back[y*b.width+x] = term.Cell{Ch: ch, Fg: fg, Bg: bg}
+}
+
+func (b *StreamBackend) Cell(x, y int) term.Cell {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if x < 0 || y < 0 || x >= b.width || y >= b.height {
+		return term.Cell{Ch: ' '}
+	}
+	return b.back[y*b.width+x]
+}
+
+func (b *StreamBackend) Clear(fg, bg term.Attribute) error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	for i := range b.back {
+		b.back[i] = term.Cell{Ch: ' ', Fg: fg, Bg: bg}
+	}
+	return nil
+}
+
+func (b *StreamBackend) SetCursor(x, y int) {
+	b.mtx.Lock()
+	b.cursorX, b.cursorY = x, y
+	b.mtx.Unlock()
+}
+
+func (b *StreamBackend) HideCursor() {
+	b.SetCursor(-1, -1)
+}
+
+// Flush queues the changed cells for the writer goroutine. Returns the
+// error of a previous write to the stream
+func (b *StreamBackend) Flush() error {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	select {
+	case <-b.done:
+		return errStreamClosed
+	default:
+	}
+
+	if b.dropFrames() {
+		b.redrawAll = true
+	}
+	out := &streamWriter{mode: b.colorMode, width: b.width, x: -1, y: -1}
+	out.WriteString("\x1b[?25l")
+	if b.redrawAll {
+		out.WriteString("\x1b[0m\x1b[2J")
+		for i := range b.front {
+			b.front[i] = invalidCell
+		}
+		b.redrawAll = false
+	}
+
+	for y := 0; y < b.height; y++ {
+		for x := 0; x < b.width; {
+			i := y*b.width + x
+			c := b.back[i]
+			c.Ch = displayRune(c.Ch)
+			w := RuneWidth(c.Ch)
+			if x+w > b.width {
+				c.Ch, w = ' ', 1
+			}
+
+			if c != b.front[i] || (w == 2 && b.front[i+1] != invalidCell) {
+				out.putCell(x, y, c, w)
+				b.front[i] = c
+				if w == 2 {
+					b.front[i+1] = invalidCell
+				}
+			}
+			x += w
+		}
+	}
+
+	if b.cursorX >= 0 && b.cursorY >= 0 && b.cursorX < b.width && b.cursorY < b.height {
+		out.moveTo(b.cursorX, b.cursorY)
+		out.WriteString("\x1b[?25h")
+	}
+
+	return b.queue(out.Bytes(), false)
+}
+
+func (b *StreamBackend) SetColorMode(mode ColorMode) ColorMode {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	if mode != ColorModeCurrent {
+		b.colorMode = mode
+		b.redrawAll = true
+	}
+	return b.colorMode
+}
+
+// PollEvent waits for the next event from the terminal. If the stream
+// fails, e.g the connection is closed, it returns EventError
+func (b *StreamBackend) PollEvent() Event {
+	select {
+	case ev := <-b.events:
+		return ev
+	case <-b.done:
+		return Event{Type: EventError, Err: errStreamClosed}
+	}
+}
+
+// post puts the event to the input queue. Returns false if the backend
+// is closed
+func (b *StreamBackend) post(ev Event) bool {
+	select {
+	case b.events <- ev:
+		return true
+	case <-b.done:
+		return false
+	}
+}
+
+// readInput decodes the input to events. If the input ends with ESC, the
+// key is posted only if no other bytes come during escapeDelay: otherwise
+// the ESC starts an escape sequence
+func (b *StreamBackend) readInput() {
+	chunks := make(chan []byte)
+	errs := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := b.rw.Read(buf)
+			if n > 0 {
+				select {
+				case chunks <- append([]byte(nil), buf[:n]...):
+				case <-b.done:
+					return
+				}
+			}
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+	}()
+
+	var pending []byte
+	var escTimeout <-chan time.Time
+	for {
+		select {
+		case data := <-chunks:
+			var events []Event
+			events, pending = decodeInput(append(pending, data...))
+			for _, ev := range events {
+				if !b.post(ev) {
+					return
+				}
+			}
+			escTimeout = nil
+			if len(pending) == 1 && pending[0] == 0x1b {
+				escTimeout = time.After(escapeDelay)
+			}
+		case <-escTimeout:
+			pending, escTimeout = nil, nil
+			if !b.post(Event{Type: EventKey, Key: term.KeyEsc}) {
+				return
+			}
+		case err := <-errs:
+			b.post(Event{Type: EventError, Err: err})
+			return
+		case <-b.done:
+			return
+		}
+	}
+}
+
+func (b *StreamBackend) watchResize() {
+	for {
+		select {
+		case size, ok := <-b.resize:
+			if !ok {
+				return
+			}
+			b.mtx.Lock()
+			b.resizeBuffers(size.Width, size.Height)
+			w, h := b.width, b.height
+			b.mtx.Unlock()
+
+			if !b.post(Event{Type: EventResize, Width: w, Height: h}) {
+				return
+			}
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// displayRune replaces the characters that must not be sent to the
+// terminal as is: control characters and characters that join the
+// previous one would break the screen
+func displayRune(r rune) rune {
+	if r < ' ' || (r >= 0x7f && r < 0xa0) || isClusterExtender(r) {
+		return ' '
+	}
+	return r
+}
+
+// streamWriter builds the output of one Flush. It remembers the terminal
+// caret position and colors to skip unneeded escape sequences
+type streamWriter struct {
+	bytes.Buffer
+	mode      ColorMode
+	width     int
+	x, y      int
+	fg, bg    term.Attribute
+	attrValid bool
+}
+
+func (w *streamWriter) moveTo(x, y int) {
+	if w.x == x && w.y == y {
+		return
+	}
+	fmt.Fprintf(w, "\x1b[%d;%dH", y+1, x+1)
+	w.x, w.y = x, y
+}
+
+func (w *streamWriter) putCell(x, y int, c term.Cell, width int) {
+	w.moveTo(x, y)
+	if !w.attrValid || c.Fg != w.fg || c.Bg != w.bg {
+		w.WriteString(sgrSequence(c.Fg, c.Bg, w.mode))
+		w.fg, w.bg, w.attrValid = c.Fg, c.Bg, true
+	}
+	w.WriteRune(c.Ch)
+	w.x += width
+	// the caret does not move after the last column, so its position is
+	// unknown until the next explicit move
+	if w.x >= w.width {
+		w.x = -1
+	}
+}
+
+// sgrSequence returns the escape sequence that sets colors and text
+// attributes of the following characters
+func sgrSequence(fg, bg term.Attribute, mode ColorMode) string {
+	params := []byte("\x1b[0")
+	attrs := []struct {
+		attr term.Attribute
+		code string
+	}{
+		{term.AttrBold, "1"}, {term.AttrDim, "2"}, {term.AttrCursive, "3"},
+		{term.AttrUnderline, "4"}, {term.AttrBlink, "5"},
+		{term.AttrReverse, "7"}, {term.AttrHidden, "8"},
+	}
+	for _, a := range attrs {
+		if fg&a.attr != 0 {
+			params = append(params, ';')
+			params = append(params, a.code...)
+		}
+	}
+	params = appendSgrColor(params, fg&^attrMask, mode, false)
+	params = appendSgrColor(params, bg&^attrMask, mode, true)
+	return string(append(params, 'm'))
+}
+
+func appendSgrColor(params []byte, clr term.Attribute, mode ColorMode, back bool) []byte {
+	if clr == ColorDefault {
+		return params
+	}
+
+	base := 38
+	if back {
+		base = 48
+	}
+	params = append(params, ';')
+	switch {
+	case isRGBColor(clr):
+		r, g, b := term.AttributeToRGB(clr)
+		return append(params, fmt.Sprintf("%d;2;%d;%d;%d", base, r, g, b)...)
+	case mode == ColorMode256:
+		return append(params, fmt.Sprintf("%d;5;%d", base, clr-1)...)
+	case clr > term.ColorWhite:
+		// bright colors
+		return strconv.AppendInt(params, int64(base+52+int(clr-term.ColorDarkGray)), 10)
+	default:
+		return strconv.AppendInt(params, int64(base-8+int(clr-term.ColorBlack)), 10)
+	}
+}
+
+// special keys sent as ESC [ number ~
+var tildeKeys = map[int]term.Key{
+	1: term.KeyHome, 2: term.KeyInsert, 3: term.KeyDelete, 4: term.KeyEnd,
+	5: term.KeyPgup, 6: term.KeyPgdn, 7: term.KeyHome, 8: term.KeyEnd,
+	11: term.KeyF1, 12: term.KeyF2, 13: term.KeyF3, 14: term.KeyF4,
+	15: term.KeyF5, 17: term.KeyF6, 18: term.KeyF7, 19: term.KeyF8,
+	20: term.KeyF9, 21: term.KeyF10, 23: term.KeyF11, 24: term.KeyF12,
+}
+
+// special keys sent as ESC [ letter or ESC O letter
+var letterKeys = map[byte]term.Key{
+	'A': term.KeyArrowUp, 'B': term.KeyArrowDown, 'C': term.KeyArrowRight,
+	'D': term.KeyArrowLeft, 'H': term.KeyHome, 'F': term.KeyEnd,
+	'P': term.KeyF1, 'Q': term.KeyF2, 'R': term.KeyF3, 'S': term.KeyF4,
+}
+
+/*
+decodeInput converts bytes sent by a terminal to events. Returns the events
+and the tail of the input that is an incomplete character or escape
+sequence: it must be decoded with the next input.
+
+ESC followed by a character is the character with Alt modifier. ESC at the
+end of the input is returned in the tail: the rest of an escape sequence
+can come with the next input(see readInput).
+*/
+func decodeInput(in []byte) (events []Event, rest []byte) {
+	for len(in) > 0 {
+		ev, n := decodeEvent(in)
+		if n == 0 {
+			break
+		}
+		if ev.Type == EventKey || ev.Type == EventMouse {
+			events = append(events, ev)
+		}
+		in = in[n:]
+	}
+	return events, in
+}
+
+// decodeEvent decodes the first event of the input and returns the number
+// of bytes it takes: 0 if the input is incomplete. Unknown escape
+// sequences are skipped: their event type is EventNone
+func decodeEvent(in []byte) (Event, int) {
+	if in[0] != 0x1b {
+		return decodeKey(in)
+	}
+	// a lone ESC can be the first byte of a sequence that is split between
+	// reads, readInput turns it to KeyEsc after escapeDelay
+	if len(in) == 1 {
+		return Event{}, 0
+	}
+	if in[1] == 0x1b {
+		return Event{Type: EventKey, Key: term.KeyEsc}, 1
+	}
+
+	switch in[1] {
+	case '[':
+		return decodeCSI(in)
+	case 'O':
+		if len(in) < 3 {
+			return Event{}, 0
+		}
+		if key, ok := letterKeys[in[2]]; ok {
+			return Event{Type: EventKey, Key: key}, 3
+		}
+		return Event{Type: EventNone}, 3
+	}
+
+	ev, n := decodeKey(in[1:])
+	if n == 0 {
+		return ev, 0
+	}
+	ev.Mod |= term.ModAlt
+	return ev, n + 1
+}
+
+// decodeKey decodes a character or a control key
+func decodeKey(in []byte) (Event, int) {
+	if in[0] <= ' ' || in[0] == 0x7f {
+		return Event{Type: EventKey, Key: term.Key(in[0])}, 1
+	}
+	if !utf8.FullRune(in) {
+		return Event{}, 0
+	}
+	r, n := utf8.DecodeRune(in)
+	return Event{Type: EventKey, Ch: r}, n
+}
+
+// decodeCSI decodes the sequence that starts with ESC [
+func decodeCSI(in []byte) (Event, int) {
+	if len(in) > 2 && in[2] == 'M' {
+		return decodeX10Mouse(in)
+	}
+
+	// parameters and intermediate bytes are followed by the final byte
+	end := 2
+	for end < len(in) && in[end] >= 0x20 && in[end] <= 0x3f {
+		end++
+	}
+	if end == len(in) {
+		return Event{}, 0
+	}
+	n := end + 1
+	params, final := in[2:end], in[end]
+
+	if len(params) > 0 && params[0] == '<' {
+		return decodeSGRMouse(params[1:], final), n
+	}
+
+	args := parseParams(params)
+	mod := term.Modifier(0)
+	if len(args) > 1 {
+		mod = keyModifier(args[1])
+	}
+	switch {
+	case final == '~' && len(args) > 0:
+		if key, ok := tildeKeys[args[0]]; ok {
+			return Event{Type: EventKey, Key: key, Mod: mod}, n
+		}
+	case final == 'Z':
+		// Shift+Tab
+		return Event{Type: EventKey, Key: term.KeyTab, Mod: ModShift}, n
+	default:
+		if key, ok := letterKeys[final]; ok {
+			return Event{Type: EventKey, Key: key, Mod: mod}, n
+		}
+	}
+	return Event{Type: EventNone}, n
+}
+
+// parseParams splits numeric parameters of an escape sequence. Missing
+// parameters are zero
+func parseParams(params []byte) []int {
+	var args []int
+	for _, p := range bytes.Split(params, []byte{';'}) {
+		v, _ := strconv.Atoi(string(p))
+		args = append(args, v)
+	}
+	return args
+}
+
+// keyModifier converts xterm modifier parameter of a special key
+func keyModifier(param int) term.Modifier {
+	bits := param - 1
+	var mod term.Modifier
+	if bits&1 != 0 {
+		mod |= ModShift
+	}
+	if bits&2 != 0 {
+		mod |= term.ModAlt
+	}
+	if bits&4 != 0 {
+		mod |= ModCtrl
+	}
+	return mod
+}
+
+// decodeSGRMouse decodes a mouse report ESC [ < button ; x ; y M or m
+func decodeSGRMouse(params []byte, final byte) Event {
+	args := parseParams(params)
+	if len(args) != 3 || (final != 'M' && final != 'm') {
+		return Event{Type: EventNone}
+	}
+	ev := mouseEvent(args[0], args[1]-1, args[2]-1)
+	if final == 'm' {
+		ev.Key = term.MouseRelease
+	}
+	return ev
+}
+
+// decodeX10Mouse decodes a mouse report of old terminals that do not
+// support SGR encoding: ESC [ M button x y, every value is a byte plus 32
+func decodeX10Mouse(in []byte) (Event, int) {
+	if len(in) < 6 {
+		return Event{}, 0
+	}
+	return mouseEvent(int(in[3])-32, int(in[4])-33, int(in[5])-33), 6
+}
+
+// mouseEvent converts xterm mouse button code to an event
+func mouseEvent(button, x, y int) Event {
+	ev := Event{Type: EventMouse, X: x, Y: y}
+	if button&4 != 0 {
+		ev.Mod |= ModShift
+	}
+	if button&8 != 0 {
+		ev.Mod |= term.ModAlt
+	}
+	if button&16 != 0 {
+		ev.Mod |= ModCtrl
+	}
+	if button&32 != 0 {
+		ev.Mod |= term.ModMotion
+	}
+
+	if button&64 != 0 {
+		ev.Key = term.MouseWheelUp
+		if button&1 != 0 {
+			ev.Key = term.MouseWheelDown
+		}
+		return ev
+	}
+	switch button & 3 {
+	case 0:
+		ev.Key = term.MouseLeft
+	case 1:
+		ev.Key = term.MouseMiddle
+	case 2:
+		ev.Key = term.MouseRight
+	default:
+		ev.Key = term.MouseRelease
+	}
+	return ev
+}
+
+// RunStream serves one session of a remote terminal: creates an App that
+// draws on the stream(see StreamBackend), calls build with the App to
+// create windows, and runs the App main loop. It returns
+// when the user quits, when ctx is cancelled, or when the stream fails.
+// A closed stream is a normal end of the session and returns nil. The App
+// is closed before the function returns, the stream is left open
+func RunStream(ctx context.Context, rw io.ReadWriter, size TerminalSize, resize <-chan TerminalSize, build func(*App)) error {
+	app, err := NewApp(NewStreamBackend(rw, size, resize))
+	if err != nil {
+		return err
+	}
+	defer app.Close()
+
+	if build != nil {
+		build(app)
+	}
+	err = app.MainLoopContext(ctx)
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,308 @@
+package clui
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestDecodeInput(t *testing.T) {
+	cases := []struct {
+		in     string
+		events []Event
+		rest   string
+	}{
+		{"aж", []Event{{Type: EventKey, Ch: 'a'}, {Type: EventKey, Ch: 'ж'}}, ""},
+		{"\r\x7f \x11", []Event{{Type: EventKey, Key: term.KeyEnter}, {Type: EventKey, Key: term.KeyBackspace2},
+			{Type: EventKey, Key: term.KeySpace}, {Type: EventKey, Key: term.KeyCtrlQ}}, ""},
+		{"\x1b[A\x1bOB\x1b[1;5C\x1b[H", []Event{{Type: EventKey, Key: term.KeyArrowUp}, {Type: EventKey, Key: term.KeyArrowDown},
+			{Type: EventKey, Key: term.KeyArrowRight, Mod: ModCtrl}, {Type: EventKey, Key: term.KeyHome}}, ""},
+		{"\x1b[5;2~\x1b[3~\x1bOP\x1b[24~", []Event{{Type: EventKey, Key: term.KeyPgup, Mod: ModShift},
+			{Type: EventKey, Key: term.KeyDelete}, {Type: EventKey, Key: term.KeyF1}, {Type: EventKey, Key: term.KeyF12}}, ""},
+		{"\x1b1\x1b\x1b", []Event{{Type: EventKey, Ch: '1', Mod: term.ModAlt}, {Type: EventKey, Key: term.KeyEsc}}, "\x1b"},
+		{"\x1b[Z\x1b[99x", []Event{{Type: EventKey, Key: term.KeyTab, Mod: ModShift}}, ""},
+		{"\x1b[<0;5;3M\x1b[<0;5;3m\x1b[<35;1;1M\x1b[<65;2;2M", []Event{{Type: EventMouse, Key: term.MouseLeft, X: 4, Y: 2},
+			{Type: EventMouse, Key: term.MouseRelease, X: 4, Y: 2}, {Type: EventMouse, Key: term.MouseRelease, Mod: term.ModMotion},
+			{Type: EventMouse, Key: term.MouseWheelDown, X: 1, Y: 1}}, ""},
+		{"\x1b[M !#", []Event{{Type: EventMouse, Key: term.MouseLeft, X: 0, Y: 2}}, ""},
+		// incomplete character and escape sequence wait for the next input
+		{"a\xd0", []Event{{Type: EventKey, Ch: 'a'}}, "\xd0"},
+		{"\x1b[<0;5", nil, "\x1b[<0;5"},
+	}
+
+	for _, c := range cases {
+		events, rest := decodeInput([]byte(c.in))
+		if !reflect.DeepEqual(events, c.events) || string(rest) != c.rest {
+			t.Errorf("decodeInput(%q) == %v, %q, want %v, %q", c.in, events, rest, c.events, c.rest)
+		}
+	}
+}
+
+func TestStreamBackendSplitEscape(t *testing.T) {
+	in, input := io.Pipe()
+	b := NewStreamBackend(struct {
+		io.Reader
+		io.Writer
+	}{in, ioutil.Discard}, TerminalSize{Width: 10, Height: 2}, nil)
+	b.Init()
+	defer b.Close()
+
+	poll := func() Event {
+		events := make(chan Event, 1)
+		go func() {
+			events <- b.PollEvent()
+		}()
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(time.Second):
+			t.Fatal("No event from the stream")
+		}
+		return Event{}
+	}
+
+	// the escape sequence comes in two reads
+	io.WriteString(input, "\x1b")
+	io.WriteString(input, "[A")
+	if ev := poll(); ev.Type != EventKey || ev.Key != term.KeyArrowUp {
+		t.Errorf("Split escape sequence must be decoded as one key: %v", ev)
+	}
+
+	// nothing follows ESC
+	start := time.Now()
+	io.WriteString(input, "\x1b")
+	if ev := poll(); ev.Type != EventKey || ev.Key != term.KeyEsc {
+		t.Errorf("Lone ESC must become Esc key: %v", ev)
+	}
+	if time.Since(start) < escapeDelay {
+		t.Error("Lone ESC must wait for the rest of escape sequence")
+	}
+
+	io.WriteString(input, "a")
+	if ev := poll(); ev.Type != EventKey || ev.Ch != 'a' || ev.Mod != 0 {
+		t.Errorf("Key after Esc must not get Alt modifier: %v", ev)
+	}
+}
+
+// chanWriter passes every write of the stream writer goroutine to a test
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestStreamBackendFlush(t *testing.T) {
+	in, _ := io.Pipe()
+	out := make(chanWriter)
+	b := NewStreamBackend(struct {
+		io.Reader
+		io.Writer
+	}{in, out}, TerminalSize{Width: 10, Height: 2}, nil)
+	b.Init()
+	if s := <-out; !strings.Contains(s, "\x1b[?1049h") {
+		t.Errorf("Init must switch to the alternate screen: %q", s)
+	}
+
+	b.SetCell(1, 0, 'x', ColorRed|term.AttrBold, ColorBlue)
+	b.SetCell(3, 1, '日', term.ColorLightGreen, ColorBlack)
+	b.SetCursor(2, 1)
+	b.Flush()
+	s := <-out
+	for _, seq := range []string{"\x1b[2J", "\x1b[0;1;31;44mx", "\x1b[0;92;40m日", "\x1b[2;3H\x1b[?25h"} {
+		if !strings.Contains(s, seq) {
+			t.Errorf("Output must contain %q: %q", seq, s)
+		}
+	}
+
+	// only changed cells are sent
+	b.SetCell(5, 0, 'y', ColorRed|term.AttrBold, ColorBlue)
+	b.HideCursor()
+	b.Flush()
+	if s := <-out; s != "\x1b[?25l\x1b[1;6H\x1b[0;1;31;44my" {
+		t.Errorf("Output must contain only changed cells: %q", s)
+	}
+
+	// a narrow character replaces the right half of the wide one
+	b.SetCell(3, 1, 'a', ColorWhite, ColorBlack)
+	b.Flush()
+	if s := <-out; !strings.Contains(s, "a ") {
+		t.Errorf("Both halves of the wide character must be repainted: %q", s)
+	}
+
+	if seq := sgrSequence(term.Attribute(201), ColorRGB(1, 2, 3), ColorMode256); seq != "\x1b[0;38;5;200;48;2;1;2;3m" {
+		t.Errorf("Invalid color sequence: %q", seq)
+	}
+
+	go func() {
+		for range out {
+		}
+	}()
+	b.Close()
+	close(out)
+}
+
+func TestStreamBackendStalled(t *testing.T) {
+	in, _ := io.Pipe()
+	// nobody reads the output, so every write blocks
+	_, stalled := io.Pipe()
+
+	created := make(chan *App)
+	go func() {
+		app, err := NewApp(NewStreamBackend(struct {
+			io.Reader
+			io.Writer
+		}{in, stalled}, TerminalSize{Width: 20, Height: 5}, nil))
+		if err != nil {
+			t.Errorf("Failed to create App: %v", err)
+		}
+		app.AddWindow(0, 0, 10, 3, "Stalled")
+		created <- app
+	}()
+
+	select {
+	case app := <-created:
+		start := time.Now()
+		app.Close()
+		if time.Since(start) > 2*streamCloseTimeout {
+			t.Error("Close must not wait for a stalled stream forever")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Stalled stream must not block NewApp and drawing")
+	}
+}
+
+// gateWriter blocks every write until the gate is closed
+type gateWriter struct {
+	gate chan struct{}
+	out  lockedBuffer
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	return w.out.Write(p)
+}
+
+func TestStreamBackendSlowStream(t *testing.T) {
+	in, _ := io.Pipe()
+	w := &gateWriter{gate: make(chan struct{})}
+	b := NewStreamBackend(struct {
+		io.Reader
+		io.Writer
+	}{in, w}, TerminalSize{Width: 100, Height: 50}, nil)
+	b.Init()
+
+	// every frame changes all cells, so the queue grows while the stream
+	// is blocked
+	for i := 0; i < 60; i++ {
+		for y := 0; y < 50; y++ {
+			for x := 0; x < 100; x++ {
+				b.SetCell(x, y, rune('a'+i%26), term.Attribute(1+(x+i)%8), ColorBlack)
+			}
+		}
+		b.Flush()
+	}
+	b.outMtx.Lock()
+	queued := string(b.out)
+	b.outMtx.Unlock()
+	if len(queued) > 2*streamMaxQueue {
+		t.Errorf("Queued output must be limited: %v bytes", len(queued))
+	}
+	if !strings.Contains(queued, "\x1b[2J") {
+		t.Error("Dropped updates must be replaced with a full repaint")
+	}
+
+	close(w.gate)
+	b.Close()
+	if s := w.out.String(); !strings.Contains(s, "\x1b[?1049h") || !strings.HasSuffix(s, "\x1b[?1049l") {
+		t.Errorf("Terminal setup and restore must not be dropped: %v bytes", len(s))
+	}
+}
+
+// lockedBuffer is a stream output written by one goroutine and read by
+// another
+type lockedBuffer struct {
+	mtx sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+	return b.buf.String()
+}
+
+func TestRunStream(t *testing.T) {
+	in, input := io.Pipe()
+	var out lockedBuffer
+	resize := make(chan TerminalSize)
+
+	var edit *EditField
+	var width int
+	done := make(chan error)
+	go func() {
+		done <- RunStream(context.Background(), struct {
+			io.Reader
+			io.Writer
+		}{in, &out}, TerminalSize{Width: 30, Height: 8}, resize, func(app *App) {
+			wnd := app.AddWindow(0, 0, 20, 5, "Remote")
+			wnd.OnScreenResize(func(ev Event) {
+				width, _ = app.ScreenSize()
+			})
+			edit = CreateEditField(wnd, 10, "", Fixed)
+			ActivateControl(wnd, edit)
+		})
+	}()
+
+	io.WriteString(input, "ab\x1b[D\x1b[Dc")
+	waitFor := func(s string) bool {
+		for start := time.Now(); time.Since(start) < time.Second; time.Sleep(10 * time.Millisecond) {
+			if strings.Contains(out.String(), s) {
+				return true
+			}
+		}
+		return false
+	}
+	if !waitFor("Remote") || !waitFor("cab") {
+		t.Errorf("Typed text must be displayed: %q", out.String())
+	}
+	if !strings.Contains(out.String(), "\x1b[?1003h") {
+		t.Errorf("Library must turn on reporting mouse movements: %q", out.String())
+	}
+
+	resize <- TerminalSize{Width: 40, Height: 10}
+	io.WriteString(input, "d")
+	if !waitFor("cdab") {
+		t.Errorf("Session must continue after resize: %q", out.String())
+	}
+
+	input.Close()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Closed stream must end the session without error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Session must end after the stream is closed")
+	}
+	if width != 40 {
+		t.Errorf("Screen must be resized: %v", width)
+	}
+	if !strings.HasSuffix(out.String(), "\x1b[?1049l") {
+		t.Errorf("Terminal state must be restored: %q", out.String())
+	}
+}
@@ -0,0 +1,101 @@
+package clui
+
+import (
+	term "github.com/nsf/termbox-go"
+)
+
+// termboxBackend is the default Backend that draws to a real terminal
+// with termbox library
+type termboxBackend struct {
+	// turn on ColorModeRGB if the terminal supports it
+	trueColor bool
+}
+
+func (b *termboxBackend) Init() error {
+	err := term.Init()
+	if err != nil {
+		return err
+	}
+	term.SetInputMode(term.InputEsc | term.InputMouse)
+
+	// RGB mode draws base colors with fixed RGB values instead of the
+	// terminal palette, so it is turned on only if the application asks
+	mode := DetectColorMode()
+	if mode == ColorModeRGB && !b.trueColor {
+		mode = ColorMode256
+	}
+	b.SetColorMode(mode)
+	return nil
+}
+
+func (b *termboxBackend) Close() {
+	term.SetCursor(3, 3)
+	term.Close()
+}
+
+func (b *termboxBackend) Size() (width int, height int) {
+	return term.Size()
+}
+
+func (b *termboxBackend) SetCell(x, y int, ch rune, fg, bg term.Attribute) {
+	term.SetCell(x, y, ch, fg, bg)
+}
+
+func (b *termboxBackend) Cell(x, y int) term.Cell {
+	w, h := term.Size()
+	if x < 0 || y < 0 || x >= w || y >= h {
+		return term.Cell{Ch: ' '}
+	}
+	cells := term.CellBuffer()
+	return cells[y*w+x]
+}
+
+func (b *termboxBackend) Clear(fg, bg term.Attribute) error {
+	return term.Clear(fg, bg)
+}
+
+func (b *termboxBackend) SetCursor(x, y int) {
+	term.SetCursor(x, y)
+}
+
+func (b *termboxBackend) HideCursor() {
+	term.HideCursor()
+}
+
+func (b *termboxBackend) Flush() error {
+	return term.Flush()
+}
+
+func (b *termboxBackend) PollEvent() Event {
+	return termboxEventToLocal(term.PollEvent())
+}
+
+func termboxEventToLocal(ev term.Event) Event {
+	e := Event{Type: EventType(ev.Type), Ch: ev.Ch,
+		Key: ev.Key, Err: ev.Err, X: ev.MouseX, Y: ev.MouseY,
+		Mod: ev.Mod, Width: ev.Width, Height: ev.Height}
+	return e
+}
+
+func (b *termboxBackend) SetColorMode(mode ColorMode) ColorMode {
+	var out term.OutputMode
+	switch mode {
+	case ColorModeNormal:
+		out = term.OutputNormal
+	case ColorMode256:
+		out = term.Output256
+	case ColorModeRGB:
+		out = term.OutputRGB
+	default:
+		out = term.OutputCurrent
+	}
+
+	switch term.SetOutputMode(out) {
+	case term.Output256:
+		return ColorMode256
+	case term.OutputRGB:
+		return ColorModeRGB
+	default:
+		return ColorModeNormal
+	}
+}
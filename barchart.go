@@ -2,7 +2,6 @@ package clui
 
 import (
 	"fmt"
-	xs "github.com/huandu/xstrings"
 	term "github.com/nsf/termbox-go"
 	"sync/atomic"
 )
@@ -114,14 +113,15 @@ func (b *BarChart) Draw() {
 	b.mtx.RLock()
 	defer b.mtx.RUnlock()
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(b)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
-	fg, bg := RealColor(b.fg, b.Style(), ColorBarChartText), RealColor(b.bg, b.Style(), ColorBarChartBack)
-	SetTextColor(fg)
-	SetBackColor(bg)
+	fg, bg := a.RealColor(b.fg, b.Style(), ColorBarChartText), a.RealColor(b.bg, b.Style(), ColorBarChartBack)
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
 
-	FillRect(b.x, b.y, b.width, b.height, ' ')
+	a.FillRect(b.x, b.y, b.width, b.height, ' ')
 
 	if len(b.data) == 0 {
 		return
@@ -160,13 +160,14 @@ func (b *BarChart) drawBars() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(b)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	h := b.barHeight()
 	pos := start
-	parts := []rune(SysObject(ObjBarChart))
-	fg, bg := TextColor(), BackColor()
+	parts := []rune(a.SysObject(ObjBarChart))
+	fg, bg := a.TextColor(), a.BackColor()
 
 	for idx, d := range b.data {
 		if pos+barW > start+width {
@@ -187,9 +188,9 @@ func (b *BarChart) drawBars() {
 
 		barH := int(d.Value * coeff)
 		if b.onDrawCell == nil {
-			SetTextColor(fColor)
-			SetBackColor(bColor)
-			FillRect(b.x+pos, b.y+h-barH, barW, barH, ch)
+			a.SetTextColor(fColor)
+			a.SetBackColor(bColor)
+			a.FillRect(b.x+pos, b.y+h-barH, barW, barH, ch)
 		} else {
 			cellDef := BarDataCell{Item: d.Title, ID: idx,
 				Value: 0, BarMax: d.Value, TotalMax: max,
@@ -198,29 +199,29 @@ func (b *BarChart) drawBars() {
 				req := cellDef
 				req.Value = max * float64(dy+1) / float64(h)
 				b.onDrawCell(&req)
-				SetTextColor(req.Fg)
-				SetBackColor(req.Bg)
+				a.SetTextColor(req.Fg)
+				a.SetBackColor(req.Bg)
 				for dx := 0; dx < barW; dx++ {
-					PutChar(b.x+pos+dx, b.y+h-1-dy, req.Ch)
+					a.PutChar(b.x+pos+dx, b.y+h-1-dy, req.Ch)
 				}
 			}
 		}
 
 		if b.showTitles {
-			SetTextColor(fg)
-			SetBackColor(bg)
+			a.SetTextColor(fg)
+			a.SetBackColor(bg)
 			if b.showMarks {
 				c := parts[7]
-				PutChar(b.x+pos+barW/2, b.y+h, c)
+				a.PutChar(b.x+pos+barW/2, b.y+h, c)
 			}
 			var s string
 			shift := 0
-			if xs.Len(d.Title) > barW {
+			if TextWidth(d.Title) > barW {
 				s = CutText(d.Title, barW)
 			} else {
 				shift, s = AlignText(d.Title, barW, AlignCenter)
 			}
-			DrawRawText(b.x+pos+shift, b.y+h+1, s)
+			a.DrawRawText(b.x+pos+shift, b.y+h+1, s)
 		}
 
 		pos += barW + int(b.BarGap())
@@ -233,11 +234,12 @@ func (b *BarChart) drawLegend() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
-	fg, bg := RealColor(b.fg, b.Style(), ColorBarChartText), RealColor(b.bg, b.Style(), ColorBarChartBack)
+	a := AppOf(b)
+	a.PushAttributes()
+	defer a.PopAttributes()
+	fg, bg := a.RealColor(b.fg, b.Style(), ColorBarChartText), a.RealColor(b.bg, b.Style(), ColorBarChartBack)
 
-	parts := []rune(SysObject(ObjBarChart))
+	parts := []rune(a.SysObject(ObjBarChart))
 	defRune := parts[0]
 	for idx, d := range b.data {
 		if idx >= b.height {
@@ -248,13 +250,13 @@ func (b *BarChart) drawLegend() {
 		if c == 0 {
 			c = defRune
 		}
-		SetTextColor(d.Fg)
-		SetBackColor(d.Bg)
-		PutChar(b.x+pos+width, b.y+idx, c)
+		a.SetTextColor(d.Fg)
+		a.SetBackColor(d.Bg)
+		a.PutChar(b.x+pos+width, b.y+idx, c)
 		s := CutText(fmt.Sprintf(" - %v", d.Title), int(b.LegendWidth()))
-		SetTextColor(fg)
-		SetBackColor(bg)
-		DrawRawText(b.x+pos+width+1, b.y+idx, s)
+		a.SetTextColor(fg)
+		a.SetBackColor(bg)
+		a.DrawRawText(b.x+pos+width+1, b.y+idx, s)
 	}
 }
 
@@ -277,11 +279,12 @@ func (b *BarChart) drawValues() {
 
 	dy := 0
 	format := fmt.Sprintf("%%%v.2f", valVal)
+	a := AppOf(b)
 	for dy < h-1 {
 		v := float64(h-dy) / float64(h) * max
 		s := fmt.Sprintf(format, v)
 		s = CutText(s, valVal)
-		DrawRawText(b.x, b.y+dy, s)
+		a.DrawRawText(b.x, b.y+dy, s)
 
 		dy += 2
 	}
@@ -294,7 +297,8 @@ func (b *BarChart) drawRulers() {
 
 	pos, vWidth := b.calculateBarArea()
 
-	parts := []rune(SysObject(ObjBarChart))
+	a := AppOf(b)
+	parts := []rune(a.SysObject(ObjBarChart))
 	h := b.barHeight()
 
 	if pos > 0 {
@@ -307,16 +311,16 @@ func (b *BarChart) drawRulers() {
 
 	if pos > 0 {
 		for dy := 0; dy < h; dy++ {
-			PutChar(b.x+pos, b.y+dy, cV)
+			a.PutChar(b.x+pos, b.y+dy, cV)
 		}
 	}
 	if b.showTitles {
 		for dx := 0; dx < vWidth; dx++ {
-			PutChar(b.x+pos+dx, b.y+h, cH)
+			a.PutChar(b.x+pos+dx, b.y+h, cH)
 		}
 	}
 	if pos > 0 && b.showTitles {
-		PutChar(b.x+pos, b.y+h, cC)
+		a.PutChar(b.x+pos, b.y+h, cC)
 	}
 }
 
@@ -399,6 +403,7 @@ func (b *BarChart) calculateMultiplier() (float64, float64) {
 
 // AddData appends a new bar to a chart
 func (b *BarChart) AddData(val BarData) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -415,6 +420,7 @@ func (b *BarChart) ClearData() {
 
 // SetData assign a new bar list to a chart
 func (b *BarChart) SetData(data []BarData) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -435,6 +441,7 @@ func (b *BarChart) AutoSize() bool {
 // SetAutoSize enables or disables automatic bar
 // width calculation
 func (b *BarChart) SetAutoSize(auto bool) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -448,6 +455,7 @@ func (b *BarChart) BarGap() int32 {
 
 // SetBarGap sets the space width between two adjacent bars
 func (b *BarChart) SetBarGap(gap int32) {
+	b.Invalidate()
 	atomic.StoreInt32(&b.gap, gap)
 }
 
@@ -458,6 +466,7 @@ func (b *BarChart) MinBarWidth() int32 {
 
 // SetMinBarWidth changes the minimal bar width
 func (b *BarChart) SetMinBarWidth(size int32) {
+	b.Invalidate()
 	atomic.StoreInt32(&b.barWidth, size)
 }
 
@@ -470,6 +479,7 @@ func (b *BarChart) ValueWidth() int32 {
 
 // SetValueWidth changes width of the value panel on the left
 func (b *BarChart) SetValueWidth(width int32) {
+	b.Invalidate()
 	atomic.StoreInt32(&b.valueWidth, width)
 }
 
@@ -481,6 +491,7 @@ func (b *BarChart) ShowTitles() bool {
 
 // SetShowTitles turns on and off horizontal axis and bar titles
 func (b *BarChart) SetShowTitles(show bool) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -495,6 +506,7 @@ func (b *BarChart) LegendWidth() int32 {
 
 // SetLegendWidth sets new legend panel width
 func (b *BarChart) SetLegendWidth(width int32) {
+	b.Invalidate()
 	atomic.StoreInt32(&b.legendWidth, width)
 }
 
@@ -520,6 +532,7 @@ func (b *BarChart) ShowMarks() bool {
 
 // SetShowMarks turns on and off marks under horizontal axis
 func (b *BarChart) SetShowMarks(show bool) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -32,9 +32,16 @@ type BaseControl struct {
 	children      []Control
 	mtx           sync.RWMutex
 	onActive      func(active bool)
+	hint          string
+	popupMenu     *Menu
+	tooltip       string
+	onHover       func(hover bool)
 	style         string
 	clipped       bool
 	clipper       *rect
+	// the App of a Window or of a drop-down list. Other controls belong
+	// to the App of their parents
+	app *App
 }
 
 var (
@@ -59,6 +66,7 @@ func (c *BaseControl) Clipped() bool {
 
 func (c *BaseControl) SetStyle(style string) {
 	c.style = style
+	c.Invalidate()
 }
 
 func (c *BaseControl) Style() string {
@@ -75,6 +83,7 @@ func (c *BaseControl) Title() string {
 
 func (c *BaseControl) SetTitle(title string) {
 	c.title = title
+	c.Invalidate()
 }
 
 func (c *BaseControl) Size() (widht int, height int) {
@@ -90,8 +99,10 @@ func (c *BaseControl) SetSize(width, height int) {
 	}
 
 	if height != c.height || width != c.width {
+		c.Invalidate()
 		c.height = height
 		c.width = width
+		c.Invalidate()
 	}
 }
 
@@ -100,6 +111,9 @@ func (c *BaseControl) Pos() (x int, y int) {
 }
 
 func (c *BaseControl) SetPos(x, y int) {
+	c.Invalidate()
+	defer c.Invalidate()
+
 	if c.clipped && c.clipper != nil {
 		cx, cy, _, _ := c.Clipper()
 		px, py := c.Paddings()
@@ -147,6 +161,7 @@ func (c *BaseControl) Active() bool {
 
 func (c *BaseControl) SetActive(active bool) {
 	c.inactive = !active
+	c.Invalidate()
 
 	if c.onActive != nil {
 		c.onActive(active)
@@ -157,6 +172,62 @@ func (c *BaseControl) OnActive(fn func(active bool)) {
 	c.onActive = fn
 }
 
+// Hint returns the text that the status bar displays while the control
+// is active
+func (c *BaseControl) Hint() string {
+	return c.hint
+}
+
+// SetHint sets the text that the status bar displays while the control
+// or any of its children without own hint is active. The text can
+// contain color tags
+func (c *BaseControl) SetHint(hint string) {
+	c.hint = hint
+}
+
+// Tooltip returns the text displayed when the mouse cursor rests over
+// the control
+func (c *BaseControl) Tooltip() string {
+	return c.tooltip
+}
+
+// SetTooltip sets the text displayed near the mouse cursor when it rests
+// over the control or any of its children without own tooltip for
+// TooltipDelay. The text can contain color tags and line breaks
+func (c *BaseControl) SetTooltip(tooltip string) {
+	c.tooltip = tooltip
+}
+
+// OnHover sets the callback that is called when the mouse cursor enters
+// the control(hover is true) or leaves it(hover is false)
+func (c *BaseControl) OnHover(fn func(hover bool)) {
+	c.onHover = fn
+}
+
+func (c *BaseControl) hoverChanged(hover bool) {
+	if c.onHover != nil {
+		c.onHover(hover)
+	}
+}
+
+// PopupMenu returns the menu that is shown on right click inside the control
+func (c *BaseControl) PopupMenu() *Menu {
+	return c.popupMenu
+}
+
+// SetPopupMenu sets the menu that is shown at mouse cursor when a user
+// clicks the right mouse button inside the control or any of its children
+// without own popup menu. The menu is not shown if the control processes
+// EventContextMenu itself. Accelerators of the previous menu are removed if
+// no other control uses it
+func (c *BaseControl) SetPopupMenu(menu *Menu) {
+	if c.popupMenu != menu {
+		c.popupMenu.detach()
+		menu.attach(AppOf(c))
+	}
+	c.popupMenu = menu
+}
+
 func (c *BaseControl) TabStop() bool {
 	return !c.tabSkip
 }
@@ -177,6 +248,7 @@ func (c *BaseControl) SetEnabled(enabled bool) {
 	defer c.mtx.Unlock()
 
 	c.disabled = !enabled
+	c.Invalidate()
 }
 
 func (c *BaseControl) Visible() bool {
@@ -186,6 +258,15 @@ func (c *BaseControl) Visible() bool {
 	return !c.hidden
 }
 
+// setHidden changes the visibility of the control without updating the
+// layout and the focus, e.g for TabView pages that share the same area
+func (c *BaseControl) setHidden(hidden bool) {
+	c.mtx.Lock()
+	c.hidden = hidden
+	c.mtx.Unlock()
+	c.Invalidate()
+}
+
 func (c *BaseControl) SetVisible(visible bool) {
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
@@ -195,6 +276,7 @@ func (c *BaseControl) SetVisible(visible bool) {
 	}
 
 	c.hidden = !visible
+	c.Invalidate()
 	if c.parent == nil {
 		return
 	}
@@ -204,11 +286,12 @@ func (c *BaseControl) SetVisible(visible bool) {
 		p = p.Parent()
 	}
 
+	a := AppOf(c)
 	go func() {
 		if FindFirstActiveControl(c) != nil && !c.inactive {
-			PutEvent(Event{Type: EventKey, Key: term.KeyTab})
+			a.PutEvent(Event{Type: EventKey, Key: term.KeyTab})
 		}
-		PutEvent(Event{Type: EventLayout, Target: p})
+		a.PutEvent(Event{Type: EventLayout, Target: p})
 	}()
 }
 
@@ -280,6 +363,7 @@ func (c *BaseControl) Align() Align {
 
 func (c *BaseControl) SetAlign(align Align) {
 	c.align = align
+	c.Invalidate()
 }
 
 func (c *BaseControl) TextColor() term.Attribute {
@@ -288,6 +372,7 @@ func (c *BaseControl) TextColor() term.Attribute {
 
 func (c *BaseControl) SetTextColor(clr term.Attribute) {
 	c.fg = clr
+	c.Invalidate()
 }
 
 func (c *BaseControl) BackColor() term.Attribute {
@@ -296,6 +381,7 @@ func (c *BaseControl) BackColor() term.Attribute {
 
 func (c *BaseControl) SetBackColor(clr term.Attribute) {
 	c.bg = clr
+	c.Invalidate()
 }
 
 func (c *BaseControl) childCount() int {
@@ -397,9 +483,17 @@ func (c *BaseControl) AddChild(control Control) {
 		c.children = append(c.children, control)
 	}
 
-	var ctrl Control
+	fitParents(c)
+
+	if c.clipped && c.clipper == nil {
+		c.setClipper()
+	}
+}
+
+// fitParents enlarges ctrl and all its parents if their content does not
+// fit, and then recalculates the layout of the top parent
+func fitParents(ctrl Control) {
 	var mainCtrl Control
-	ctrl = c
 	for ctrl != nil {
 		ww, hh := ctrl.MinimalSize()
 		cw, ch := ctrl.Size()
@@ -423,10 +517,6 @@ func (c *BaseControl) AddChild(control Control) {
 		mainCtrl.ResizeChildren()
 		mainCtrl.PlaceChildren()
 	}
-
-	if c.clipped && c.clipper == nil {
-		c.setClipper()
-	}
 }
 
 func (c *BaseControl) Children() []Control {
@@ -520,8 +610,9 @@ func (c *BaseControl) DrawChildren() {
 		return
 	}
 
-	PushClip()
-	defer PopClip()
+	a := AppOf(c)
+	a.PushClip()
+	defer a.PopClip()
 
 	cp := ClippedParent(c)
 	var cTarget Control
@@ -532,7 +623,7 @@ func (c *BaseControl) DrawChildren() {
 	}
 
 	x, y, w, h := cTarget.Clipper()
-	SetClipRect(x, y, w, h)
+	a.SetClipRect(x, y, w, h)
 
 	for _, child := range c.children {
 		child.Draw()
@@ -610,11 +701,13 @@ func (c *BaseControl) ActiveColors() (term.Attribute, term.Attribute) {
 // SetActiveTextColor changes text color of the active control
 func (c *BaseControl) SetActiveTextColor(clr term.Attribute) {
 	c.fgActive = clr
+	c.Invalidate()
 }
 
 // SetActiveBackColor changes background color of the active control
 func (c *BaseControl) SetActiveBackColor(clr term.Attribute) {
 	c.bgActive = clr
+	c.Invalidate()
 }
 
 func (c *BaseControl) removeChild(control Control) {
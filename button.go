@@ -1,7 +1,6 @@
 package clui
 
 import (
-	xs "github.com/huandu/xstrings"
 	term "github.com/nsf/termbox-go"
 	"sync/atomic"
 	"time"
@@ -40,7 +39,7 @@ func CreateButton(parent Control, width, height int, title string, scale int) *B
 		height = 4
 	}
 	if width == AutoSize {
-		width = xs.Len(title) + 2 + 1
+		width = TextWidth(title) + 2 + 1
 	}
 
 	if height < 4 {
@@ -70,51 +69,52 @@ func (b *Button) Draw() {
 
 	b.mtx.RLock()
 	defer b.mtx.RUnlock()
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(b)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	x, y := b.Pos()
 	w, h := b.Size()
 
 	fg, bg := b.fg, b.bg
-	shadow := RealColor(b.shadowColor, b.Style(), ColorButtonShadow)
+	shadow := a.RealColor(b.shadowColor, b.Style(), ColorButtonShadow)
 	if b.disabled {
-		fg, bg = RealColor(fg, b.Style(), ColorButtonDisabledText), RealColor(bg, b.Style(), ColorButtonDisabledBack)
+		fg, bg = a.RealColor(fg, b.Style(), ColorButtonDisabledText), a.RealColor(bg, b.Style(), ColorButtonDisabledBack)
 	} else if b.Active() {
-		fg, bg = RealColor(b.fgActive, b.Style(), ColorButtonActiveText), RealColor(b.bgActive, b.Style(), ColorButtonActiveBack)
+		fg, bg = a.RealColor(b.fgActive, b.Style(), ColorButtonActiveText), a.RealColor(b.bgActive, b.Style(), ColorButtonActiveBack)
 	} else {
-		fg, bg = RealColor(fg, b.Style(), ColorButtonText), RealColor(bg, b.Style(), ColorButtonBack)
+		fg, bg = a.RealColor(fg, b.Style(), ColorButtonText), a.RealColor(bg, b.Style(), ColorButtonBack)
 	}
 
 	dy := int((h - 1) / 2)
-	SetTextColor(fg)
+	a.SetTextColor(fg)
 	shift, text := AlignColorizedText(b.title, w-1, b.align)
 	if b.isPressed() == 0 {
 		switch b.shadowType {
 		case ShadowFull:
-			SetBackColor(shadow)
-			FillRect(x+1, y+h-1, w-1, 1, ' ')
-			FillRect(x+w-1, y+1, 1, h-1, ' ')
+			a.SetBackColor(shadow)
+			a.FillRect(x+1, y+h-1, w-1, 1, ' ')
+			a.FillRect(x+w-1, y+1, 1, h-1, ' ')
 		case ShadowHalf:
-			parts := []rune(SysObject(ObjButton))
+			parts := []rune(a.SysObject(ObjButton))
 			var bottomCh, rightCh rune
 			if len(parts) < 2 {
 				bottomCh, rightCh = '▀', '█'
 			} else {
 				bottomCh, rightCh = parts[0], parts[1]
 			}
-			SetTextColor(shadow)
-			FillRect(x+1, y+h-1, w-1, 1, bottomCh)
-			FillRect(x+w-1, y+1, 1, h-2, rightCh)
+			a.SetTextColor(shadow)
+			a.FillRect(x+1, y+h-1, w-1, 1, bottomCh)
+			a.FillRect(x+w-1, y+1, 1, h-2, rightCh)
 		}
-		SetTextColor(fg)
-		SetBackColor(bg)
-		FillRect(x, y, w-1, h-1, ' ')
-		DrawText(x+shift, y+dy, text)
+		a.SetTextColor(fg)
+		a.SetBackColor(bg)
+		a.FillRect(x, y, w-1, h-1, ' ')
+		a.DrawText(x+shift, y+dy, text)
 	} else {
-		SetBackColor(bg)
-		FillRect(x+1, y+1, w-1, h-1, ' ')
-		DrawText(x+1+shift, y+1+dy, b.title)
+		a.SetBackColor(bg)
+		a.FillRect(x+1, y+1, w-1, h-1, ' ')
+		a.DrawText(x+1+shift, y+1+dy, b.title)
 	}
 }
 
@@ -137,17 +137,13 @@ func (b *Button) ProcessEvent(event Event) bool {
 		return false
 	}
 
+	a := AppOf(b)
 	if event.Type == EventKey {
 		if event.Key == term.KeySpace && b.isPressed() == 0 {
 			b.setPressed(1)
-			ev := Event{Type: EventRedraw}
-
-			go func() {
-				PutEvent(ev)
-				time.Sleep(100 * time.Millisecond)
+			a.AfterFunc(100*time.Millisecond, func() {
 				b.setPressed(0)
-				PutEvent(ev)
-			}()
+			})
 
 			if b.onClick != nil {
 				b.onClick(event)
@@ -155,16 +151,16 @@ func (b *Button) ProcessEvent(event Event) bool {
 			return true
 		} else if event.Key == term.KeyEsc && b.isPressed() != 0 {
 			b.setPressed(0)
-			ReleaseEvents()
+			a.ReleaseEvents()
 			return true
 		}
 	} else if event.Type == EventMouse {
 		if event.Key == term.MouseLeft {
 			b.setPressed(1)
-			GrabEvents(b)
+			a.GrabEvents(b)
 			return true
 		} else if event.Key == term.MouseRelease && b.isPressed() != 0 {
-			ReleaseEvents()
+			a.ReleaseEvents()
 			if event.X >= b.x && event.Y >= b.y && event.X < b.x+b.width && event.Y < b.y+b.height {
 				if b.onClick != nil {
 					b.onClick(event)
@@ -191,6 +187,7 @@ func (b *Button) ShadowType() ButtonShadow {
 
 // SetShadowType changes the shadow the button drops
 func (b *Button) SetShadowType(sh ButtonShadow) {
+	b.Invalidate()
 	b.mtx.Lock()
 	b.shadowType = sh
 	b.mtx.Unlock()
@@ -1,10 +1,8 @@
 package clui
 
 import (
-	xs "github.com/huandu/xstrings"
 	term "github.com/nsf/termbox-go"
 	"strings"
-	"unicode"
 )
 
 type attr struct {
@@ -30,260 +28,329 @@ type Canvas struct {
 	clipH     int
 	attrStack []attr
 	clipStack []rect
-}
-
-var (
-	canvas *Canvas
-)
-
-func initCanvas() bool {
-	err := term.Init()
-	if err != nil {
-		return false
+	// the area that can be changed: all clipping rectangles are inside it.
+	// It is smaller than the screen while only a part of the screen is
+	// repainted
+	limit     rect
+	backend   Backend
+	colorMode ColorMode
+	// colors converted to the current color mode
+	outText term.Attribute
+	outBack term.Attribute
+	// the theme for frames and scrollbars
+	theme *ThemeManager
+}
+
+// newCanvas initializes the backend and creates a Canvas that draws on it
+// with the theme
+func newCanvas(backend Backend, theme *ThemeManager) (*Canvas, error) {
+	if err := backend.Init(); err != nil {
+		return nil, err
+	}
+	if m, ok := backend.(MouseMotionBackend); ok {
+		if err := m.SetMouseMotion(true); err != nil {
+			backend.Close()
+			return nil, err
+		}
 	}
-	term.SetInputMode(term.InputEsc | term.InputMouse)
 
-	canvas = new(Canvas)
-	Reset()
+	c := new(Canvas)
+	c.backend = backend
+	c.theme = theme
+	c.colorMode = backend.SetColorMode(ColorModeCurrent)
+	c.Reset()
 
-	return true
+	return c, nil
 }
 
 // PushAttributes saves the current back and fore colors. Useful when used with
 // PopAttributes: you can save colors then change them to anything you like and
 // as the final step just restore original colors
-func PushAttributes() {
-	p := attr{text: canvas.textColor, back: canvas.backColor}
-	canvas.attrStack = append(canvas.attrStack, p)
+func (c *Canvas) PushAttributes() {
+	p := attr{text: c.textColor, back: c.backColor}
+	c.attrStack = append(c.attrStack, p)
 }
 
 // PopAttributes restores saved with PushAttributes colors. Function does
 // nothing if there is no saved colors
-func PopAttributes() {
-	if len(canvas.attrStack) == 0 {
+func (c *Canvas) PopAttributes() {
+	if len(c.attrStack) == 0 {
 		return
 	}
-	a := canvas.attrStack[len(canvas.attrStack)-1]
-	canvas.attrStack = canvas.attrStack[:len(canvas.attrStack)-1]
-	SetTextColor(a.text)
-	SetBackColor(a.back)
+	a := c.attrStack[len(c.attrStack)-1]
+	c.attrStack = c.attrStack[:len(c.attrStack)-1]
+	c.SetTextColor(a.text)
+	c.SetBackColor(a.back)
 }
 
 // PushClip saves the current clipping window
-func PushClip() {
-	c := rect{x: canvas.clipX, y: canvas.clipY, w: canvas.clipW, h: canvas.clipH}
-	canvas.clipStack = append(canvas.clipStack, c)
+func (c *Canvas) PushClip() {
+	r := rect{x: c.clipX, y: c.clipY, w: c.clipW, h: c.clipH}
+	c.clipStack = append(c.clipStack, r)
 }
 
 // PopClip restores saved with PushClip clipping window
-func PopClip() {
-	if len(canvas.clipStack) == 0 {
+func (c *Canvas) PopClip() {
+	if len(c.clipStack) == 0 {
 		return
 	}
-	c := canvas.clipStack[len(canvas.clipStack)-1]
-	canvas.clipStack = canvas.clipStack[:len(canvas.clipStack)-1]
-	SetClipRect(c.x, c.y, c.w, c.h)
+	r := c.clipStack[len(c.clipStack)-1]
+	c.clipStack = c.clipStack[:len(c.clipStack)-1]
+	c.SetClipRect(r.x, r.y, r.w, r.h)
 }
 
 // Reset reinitializes canvas: set clipping rectangle to the whole
 // terminal window, clears clip and color saved data, sets colors
 // to default ones
-func Reset() {
-	canvas.width, canvas.height = term.Size()
-	canvas.clipX, canvas.clipY = 0, 0
-	canvas.clipW, canvas.clipH = canvas.width, canvas.height
-	canvas.textColor = ColorWhite
-	canvas.backColor = ColorBlack
+func (c *Canvas) Reset() {
+	c.width, c.height = c.backend.Size()
+	c.limit = rect{w: c.width, h: c.height}
+	c.clipX, c.clipY = 0, 0
+	c.clipW, c.clipH = c.width, c.height
+	c.SetTextColor(ColorWhite)
+	c.SetBackColor(ColorBlack)
 
-	canvas.attrStack = make([]attr, 0)
-	canvas.clipStack = make([]rect, 0)
+	c.attrStack = make([]attr, 0)
+	c.clipStack = make([]rect, 0)
 }
 
 // InClipRect returns true if x and y position is inside current clipping
 // rectangle
-func InClipRect(x, y int) bool {
-	return x >= canvas.clipX && y >= canvas.clipY &&
-		x < canvas.clipX+canvas.clipW &&
-		y < canvas.clipY+canvas.clipH
+func (c *Canvas) InClipRect(x, y int) bool {
+	return x >= c.clipX && y >= c.clipY &&
+		x < c.clipX+c.clipW &&
+		y < c.clipY+c.clipH
 }
 
-func clip(x, y, w, h int) (cx int, cy int, cw int, ch int) {
-	if x+w < canvas.clipX || x > canvas.clipX+canvas.clipW ||
-		y+h < canvas.clipY || y > canvas.clipY+canvas.clipH {
+func (c *Canvas) clip(x, y, w, h int) (cx int, cy int, cw int, ch int) {
+	if x+w <= c.clipX || x >= c.clipX+c.clipW ||
+		y+h <= c.clipY || y >= c.clipY+c.clipH {
 		return 0, 0, 0, 0
 	}
 
-	if x < canvas.clipX {
-		w = w - (canvas.clipX - x)
-		x = canvas.clipX
+	if x < c.clipX {
+		w = w - (c.clipX - x)
+		x = c.clipX
 	}
-	if y < canvas.clipY {
-		h = h - (canvas.clipY - y)
-		y = canvas.clipY
+	if y < c.clipY {
+		h = h - (c.clipY - y)
+		y = c.clipY
 	}
-	if x+w > canvas.clipX+canvas.clipW {
-		w = canvas.clipW - (x - canvas.clipX)
+	if x+w > c.clipX+c.clipW {
+		w = c.clipW - (x - c.clipX)
 	}
-	if y+h > canvas.clipY+canvas.clipH {
-		h = canvas.clipH - (y - canvas.clipY)
+	if y+h > c.clipY+c.clipH {
+		h = c.clipH - (y - c.clipY)
 	}
 
 	return x, y, w, h
 }
 
-// Flush makes termbox to draw everything to screen
-func Flush() {
-	term.Flush()
+// Flush makes the backend to draw everything to screen
+func (c *Canvas) Flush() {
+	c.backend.Flush()
 }
 
 // SetSize sets the new Canvas size. If new size does not
 // equal old size then Canvas is recreated and cleared
 // with default colors. Both Canvas width and height must
 // be greater than 2
-func SetScreenSize(width int, height int) {
-	if canvas.width == width && canvas.height == height {
+func (c *Canvas) SetScreenSize(width int, height int) {
+	if c.width == width && c.height == height {
 		return
 	}
 
-	canvas.width = width
-	canvas.height = height
+	c.width = width
+	c.height = height
+
+	c.clipStack = make([]rect, 0)
+	c.limit = rect{w: width, h: height}
+	c.SetClipRect(0, 0, width, height)
+}
 
-	canvas.clipStack = make([]rect, 0)
-	SetClipRect(0, 0, width, height)
+// setDrawLimit restricts drawing to the area, e.g while repainting
+// invalidated part of the screen. The clipping rectangle is reset to the
+// area
+func (c *Canvas) setDrawLimit(area rect) {
+	c.limit = area.intersect(rect{w: c.width, h: c.height})
+	c.clipStack = make([]rect, 0)
+	c.SetClipRect(c.limit.x, c.limit.y, c.limit.w, c.limit.h)
 }
 
 // Size returns current Canvas size
-func ScreenSize() (width int, height int) {
-	return canvas.width, canvas.height
+func (c *Canvas) ScreenSize() (width int, height int) {
+	return c.width, c.height
 }
 
 // SetCursorPos sets text caret position. Used by controls like EditField
-func SetCursorPos(x int, y int) {
-	term.SetCursor(x, y)
+func (c *Canvas) SetCursorPos(x int, y int) {
+	c.backend.SetCursor(x, y)
+}
+
+// HideCursor makes text caret invisible
+func (c *Canvas) HideCursor() {
+	c.backend.HideCursor()
 }
 
 // PutChar sets value for the Canvas cell: rune and its colors. Returns result of
 // operation: e.g, if the symbol position is outside Canvas the operation fails
 // and the function returns false
-func PutChar(x, y int, r rune) bool {
-	if InClipRect(x, y) {
-		term.SetCell(x, y, r, canvas.textColor, canvas.backColor)
+func (c *Canvas) PutChar(x, y int, r rune) bool {
+	if c.InClipRect(x, y) {
+		c.backend.SetCell(x, y, r, c.outText, c.outBack)
 		return true
 	}
 
 	return false
 }
 
-func putCharUnsafe(x, y int, r rune) {
-	term.SetCell(x, y, r, canvas.textColor, canvas.backColor)
+func (c *Canvas) putCharUnsafe(x, y int, r rune) {
+	c.backend.SetCell(x, y, r, c.outText, c.outBack)
 }
 
 // Symbol returns the character and its attributes by its coordinates
-func Symbol(x, y int) (term.Cell, bool) {
-	if x >= 0 && x < canvas.width && y >= 0 && y < canvas.height {
-		cells := term.CellBuffer()
-		return cells[y*canvas.width+x], true
+func (c *Canvas) Symbol(x, y int) (term.Cell, bool) {
+	if x >= 0 && x < c.width && y >= 0 && y < c.height {
+		return c.backend.Cell(x, y), true
 	}
 	return term.Cell{Ch: ' '}, false
 }
 
-// SetTextColor changes current text color
-func SetTextColor(clr term.Attribute) {
-	canvas.textColor = clr
+// SetTextColor changes current text color. The color can be a base
+// color, a color from 256-color palette (see Color256), or 24-bit color
+// (see ColorRGB). If the screen cannot display the color, the closest
+// available one is used
+func (c *Canvas) SetTextColor(clr term.Attribute) {
+	c.textColor = clr
+	c.outText = convertColor(clr, c.colorMode, false)
 }
 
-// SetBackColor changes current background color
-func SetBackColor(clr term.Attribute) {
-	canvas.backColor = clr
+// SetBackColor changes current background color. See SetTextColor for
+// supported colors
+func (c *Canvas) SetBackColor(clr term.Attribute) {
+	c.backColor = clr
+	c.outBack = convertColor(clr, c.colorMode, true)
 }
 
-func TextColor() term.Attribute {
-	return canvas.textColor
+// ScreenColorMode returns the number of colors the screen displays
+func (c *Canvas) ScreenColorMode() ColorMode {
+	return c.colorMode
 }
 
-func BackColor() term.Attribute {
-	return canvas.backColor
+// SetScreenColorMode changes the number of colors the screen displays.
+// Returns the mode that is actually set. Use DetectColorMode to find out
+// what the terminal supports. ColorModeRGB is not turned on automatically
+// because in this mode base colors are displayed with standard RGB values
+// instead of the terminal palette ones: call the function or create the
+// backend with NewTermboxBackend(true) to use it
+func (c *Canvas) SetScreenColorMode(mode ColorMode) ColorMode {
+	c.colorMode = c.backend.SetColorMode(mode)
+	c.SetTextColor(c.textColor)
+	c.SetBackColor(c.backColor)
+	return c.colorMode
+}
+
+func (c *Canvas) clearScreen(fg, bg term.Attribute) {
+	c.backend.Clear(convertColor(fg, c.colorMode, false), convertColor(bg, c.colorMode, true))
+}
+
+func (c *Canvas) TextColor() term.Attribute {
+	return c.textColor
+}
+
+func (c *Canvas) BackColor() term.Attribute {
+	return c.backColor
 }
 
 // SetClipRect defines a new clipping rect. Maybe useful with PopClip and
-// PushClip functions
-func SetClipRect(x, y, w, h int) {
-	if x < 0 {
-		x = 0
-	}
-	if y < 0 {
-		y = 0
-	}
-	if x+w > canvas.width {
-		w = canvas.width - x
-	}
-	if y+h > canvas.height {
-		h = canvas.height - h
-	}
+// PushClip functions. The clipping rectangle is always inside the screen
+// and inside the repainted area
+func (c *Canvas) SetClipRect(x, y, w, h int) {
+	r := rect{x: x, y: y, w: w, h: h}.intersect(c.limit)
 
-	canvas.clipX = x
-	canvas.clipY = y
-	canvas.clipW = w
-	canvas.clipH = h
+	c.clipX = r.x
+	c.clipY = r.y
+	c.clipW = r.w
+	c.clipH = r.h
 }
 
 // ClipRect returns the current clipping rectangle
-func ClipRect() (x int, y int, w int, h int) {
-	return canvas.clipX, canvas.clipY, canvas.clipW, canvas.clipH
+func (c *Canvas) ClipRect() (x int, y int, w int, h int) {
+	return c.clipX, c.clipY, c.clipW, c.clipH
 }
 
 // DrawHorizontalLine draws the part of the horizontal line that is inside
 // current clipping rectangle
-func DrawHorizontalLine(x, y, w int, r rune) {
-	x, y, w, _ = clip(x, y, w, 1)
+func (c *Canvas) DrawHorizontalLine(x, y, w int, r rune) {
+	x, y, w, _ = c.clip(x, y, w, 1)
 	if w == 0 {
 		return
 	}
 
 	for i := x; i < x+w; i++ {
-		putCharUnsafe(i, y, r)
+		c.putCharUnsafe(i, y, r)
 	}
 }
 
 // DrawVerticalLine draws the part of the vertical line that is inside current
 // clipping rectangle
-func DrawVerticalLine(x, y, h int, r rune) {
-	x, y, _, h = clip(x, y, 1, h)
+func (c *Canvas) DrawVerticalLine(x, y, h int, r rune) {
+	x, y, _, h = c.clip(x, y, 1, h)
 	if h == 0 {
 		return
 	}
 
 	for i := y; i < y+h; i++ {
-		putCharUnsafe(x, i, r)
+		c.putCharUnsafe(x, i, r)
 	}
 }
 
+// putCluster draws the first character of a grapheme cluster that takes
+// w cells. A wide character cut by the clipping rectangle is replaced
+// with a space, so it does not overlap the text around the clipping
+// rectangle. Returns false if the cluster is outside the clipping
+// rectangle
+func (c *Canvas) putCluster(x, y int, r rune, w int) bool {
+	first, last := c.InClipRect(x, y), c.InClipRect(x+w-1, y)
+	switch {
+	case first && last:
+		c.putCharUnsafe(x, y, r)
+	case first:
+		c.putCharUnsafe(x, y, ' ')
+	case last:
+		c.putCharUnsafe(x+w-1, y, ' ')
+	default:
+		return false
+	}
+	return true
+}
+
 // DrawText draws the part of text that is inside the current clipping
 // rectangle. DrawText always paints colorized string. If you want to draw
-// raw string then use DrawRawText function
-func DrawText(x, y int, text string) {
-	PushAttributes()
-	defer PopAttributes()
+// raw string then use DrawRawText function. Wide characters take two
+// cells, and a grapheme cluster is displayed as its first character(see
+// TextWidth)
+func (c *Canvas) DrawText(x, y int, text string) {
+	c.PushAttributes()
+	defer c.PopAttributes()
 
-	defText, defBack := TextColor(), BackColor()
-	firstdrawn := InClipRect(x, y)
+	defText, defBack := c.TextColor(), c.BackColor()
+	firstdrawn := c.InClipRect(x, y)
 
+	var st graphemeState
 	parser := NewColorParser(text, defText, defBack)
 	elem := parser.NextElement()
 	for elem.Type != ElemEndOfText {
 		if elem.Type == ElemPrintable {
-			SetTextColor(elem.Fg)
-			SetBackColor(elem.Bg)
-			drawn := PutChar(x, y, elem.Ch)
-
-			if unicode.Is(unicode.Scripts["Han"], elem.Ch) {
-				x += 2
-			} else {
-				x += 1
-			}
-
-			if firstdrawn && !drawn {
-				break
+			if w := st.width(elem.Ch); w > 0 {
+				c.SetTextColor(elem.Fg)
+				c.SetBackColor(elem.Bg)
+				drawn := c.putCluster(x, y, elem.Ch, w)
+				x += w
+
+				if firstdrawn && !drawn {
+					break
+				}
 			}
 		}
 
@@ -295,48 +362,45 @@ func DrawText(x, y int, text string) {
 // rectangle. DrawRawText always paints string as is - no color changes.
 // If you want to draw string with color changing commands included then
 // use DrawText function
-func DrawRawText(x, y int, text string) {
-	cx, cy, cw, ch := ClipRect()
+func (c *Canvas) DrawRawText(x, y int, text string) {
+	cx, cy, cw, ch := c.ClipRect()
 	if x >= cx+cw || y < cy || y >= cy+ch {
 		return
 	}
 
-	length := xs.Len(text)
-	if x+length < cx {
-		return
-	}
-
-	if x < cx {
-		text = xs.Slice(text, cx-x, -1)
-		length = length - (cx - x)
-		x = cx
-	}
-	text = CutText(text, cw)
-
-	dx := 0
-	for _, ch := range text {
-		putCharUnsafe(x+dx, y, ch)
-		dx++
+	var st graphemeState
+	for _, r := range text {
+		w := st.width(r)
+		if w == 0 {
+			continue
+		}
+		if x >= cx+cw {
+			break
+		}
+		c.putCluster(x, y, r, w)
+		x += w
 	}
 }
 
 // DrawTextVertical draws the part of text that is inside the current clipping
 // rectangle. DrawTextVertical always paints colorized string. If you want to draw
-// raw string then use DrawRawTextVertical function
-func DrawTextVertical(x, y int, text string) {
-	PushAttributes()
-	defer PopAttributes()
+// raw string then use DrawRawTextVertical function. Every grapheme cluster
+// takes one row
+func (c *Canvas) DrawTextVertical(x, y int, text string) {
+	c.PushAttributes()
+	defer c.PopAttributes()
 
-	defText, defBack := TextColor(), BackColor()
-	firstdrawn := InClipRect(x, y)
+	defText, defBack := c.TextColor(), c.BackColor()
+	firstdrawn := c.InClipRect(x, y)
 
+	var st graphemeState
 	parser := NewColorParser(text, defText, defBack)
 	elem := parser.NextElement()
 	for elem.Type != ElemEndOfText {
-		if elem.Type == ElemPrintable {
-			SetTextColor(elem.Fg)
-			SetBackColor(elem.Bg)
-			drawn := PutChar(x, y, elem.Ch)
+		if elem.Type == ElemPrintable && st.width(elem.Ch) > 0 {
+			c.SetTextColor(elem.Fg)
+			c.SetBackColor(elem.Bg)
+			drawn := c.PutChar(x, y, elem.Ch)
 			y += 1
 			if firstdrawn && !drawn {
 				break
@@ -351,38 +415,34 @@ func DrawTextVertical(x, y int, text string) {
 // rectangle. DrawRawTextVertical always paints string as is - no color changes.
 // If you want to draw string with color changing commands included then
 // use DrawTextVertical function
-func DrawRawTextVertical(x, y int, text string) {
-	cx, cy, cw, ch := ClipRect()
+func (c *Canvas) DrawRawTextVertical(x, y int, text string) {
+	cx, cy, cw, ch := c.ClipRect()
 	if y >= cy+ch || x < cx || x >= cx+cw {
 		return
 	}
 
-	length := xs.Len(text)
-	if y+length < cy {
-		return
-	}
-
-	if y < cy {
-		text = xs.Slice(text, cy-y, -1)
-		length = length - (cy - y)
-		y = cy
-	}
-	text = CutText(text, ch)
-
-	dy := 0
-	for _, ch := range text {
-		putCharUnsafe(x, y+dy, ch)
-		dy++
+	var st graphemeState
+	for _, r := range text {
+		if st.width(r) == 0 {
+			continue
+		}
+		if y >= cy+ch {
+			break
+		}
+		if y >= cy {
+			c.putCharUnsafe(x, y, r)
+		}
+		y++
 	}
 }
 
 // DrawFrame paints the frame without changing area inside it
-func DrawFrame(x, y, w, h int, border BorderStyle) {
+func (c *Canvas) DrawFrame(x, y, w, h int, border BorderStyle) {
 	var chars string
 	if border == BorderThick {
-		chars = SysObject(ObjDoubleBorder)
+		chars = c.theme.SysObject(ObjDoubleBorder)
 	} else if border == BorderThin {
-		chars = SysObject(ObjSingleBorder)
+		chars = c.theme.SysObject(ObjSingleBorder)
 	} else if border == BorderNone {
 		chars = "      "
 	} else {
@@ -392,57 +452,57 @@ func DrawFrame(x, y, w, h int, border BorderStyle) {
 	parts := []rune(chars)
 	H, V, UL, UR, DL, DR := parts[0], parts[1], parts[2], parts[3], parts[4], parts[5]
 
-	if InClipRect(x, y) {
-		putCharUnsafe(x, y, UL)
+	if c.InClipRect(x, y) {
+		c.putCharUnsafe(x, y, UL)
 	}
-	if InClipRect(x+w-1, y+h-1) {
-		putCharUnsafe(x+w-1, y+h-1, DR)
+	if c.InClipRect(x+w-1, y+h-1) {
+		c.putCharUnsafe(x+w-1, y+h-1, DR)
 	}
-	if InClipRect(x, y+h-1) {
-		putCharUnsafe(x, y+h-1, DL)
+	if c.InClipRect(x, y+h-1) {
+		c.putCharUnsafe(x, y+h-1, DL)
 	}
-	if InClipRect(x+w-1, y) {
-		putCharUnsafe(x+w-1, y, UR)
+	if c.InClipRect(x+w-1, y) {
+		c.putCharUnsafe(x+w-1, y, UR)
 	}
 
 	var xx, yy, ww, hh int
-	xx, yy, ww, _ = clip(x+1, y, w-2, 1)
+	xx, yy, ww, _ = c.clip(x+1, y, w-2, 1)
 	if ww > 0 {
-		DrawHorizontalLine(xx, yy, ww, H)
+		c.DrawHorizontalLine(xx, yy, ww, H)
 	}
-	xx, yy, ww, _ = clip(x+1, y+h-1, w-2, 1)
+	xx, yy, ww, _ = c.clip(x+1, y+h-1, w-2, 1)
 	if ww > 0 {
-		DrawHorizontalLine(xx, yy, ww, H)
+		c.DrawHorizontalLine(xx, yy, ww, H)
 	}
-	xx, yy, _, hh = clip(x, y+1, 1, h-2)
+	xx, yy, _, hh = c.clip(x, y+1, 1, h-2)
 	if hh > 0 {
-		DrawVerticalLine(xx, yy, hh, V)
+		c.DrawVerticalLine(xx, yy, hh, V)
 	}
-	xx, yy, _, hh = clip(x+w-1, y+1, 1, h-2)
+	xx, yy, _, hh = c.clip(x+w-1, y+1, 1, h-2)
 	if hh > 0 {
-		DrawVerticalLine(xx, yy, hh, V)
+		c.DrawVerticalLine(xx, yy, hh, V)
 	}
 }
 
 // DrawScrollBar displays a scrollbar. pos is the position of the thumb.
 // The function detects direction of the scrollbar automatically: if w is greater
 // than h then it draws horizontal scrollbar and vertical otherwise
-func DrawScrollBar(x, y, w, h, pos int) {
-	xx, yy, ww, hh := clip(x, y, w, h)
+func (c *Canvas) DrawScrollBar(x, y, w, h, pos int) {
+	xx, yy, ww, hh := c.clip(x, y, w, h)
 	if ww < 1 || hh < 1 {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	c.PushAttributes()
+	defer c.PopAttributes()
 
-	fg, bg := RealColor(ColorDefault, "", ColorScrollText), RealColor(ColorDefault, "", ColorScrollBack)
+	fg, bg := c.theme.RealColor(ColorDefault, "", ColorScrollText), c.theme.RealColor(ColorDefault, "", ColorScrollBack)
 	// TODO: add thumb styling
-	// fgThumb, bgThumb := RealColor(ColorDefault, "", ColorThumbText), RealColor(ColorDefault, "", ColorThumbBack)
-	SetTextColor(fg)
-	SetBackColor(bg)
+	// fgThumb, bgThumb := c.theme.RealColor(ColorDefault, "", ColorThumbText), c.theme.RealColor(ColorDefault, "", ColorThumbBack)
+	c.SetTextColor(fg)
+	c.SetBackColor(bg)
 
-	parts := []rune(SysObject(ObjScrollBar))
+	parts := []rune(c.theme.SysObject(ObjScrollBar))
 	chLine, chThumb, chUp, chDown := parts[0], parts[1], parts[2], parts[3]
 	chLeft, chRight := parts[4], parts[5]
 
@@ -457,11 +517,11 @@ func DrawScrollBar(x, y, w, h, pos int) {
 		dy = h - 1
 	}
 
-	if InClipRect(x, y) {
-		putCharUnsafe(x, y, chStart)
+	if c.InClipRect(x, y) {
+		c.putCharUnsafe(x, y, chStart)
 	}
-	if InClipRect(x+dx, y+dy) {
-		putCharUnsafe(x+dx, y+dy, chEnd)
+	if c.InClipRect(x+dx, y+dy) {
+		c.putCharUnsafe(x+dx, y+dy, chEnd)
 	}
 	if xx == x && w > h {
 		xx = x + 1
@@ -479,34 +539,34 @@ func DrawScrollBar(x, y, w, h, pos int) {
 	}
 
 	if w > h {
-		DrawHorizontalLine(xx, yy, ww, chLine)
+		c.DrawHorizontalLine(xx, yy, ww, chLine)
 	} else {
-		DrawVerticalLine(xx, yy, hh, chLine)
+		c.DrawVerticalLine(xx, yy, hh, chLine)
 	}
 
 	if pos >= 0 {
 		if w > h {
-			if pos < w-2 && InClipRect(x+1+pos, y) {
-				putCharUnsafe(x+1+pos, y, chThumb)
+			if pos < w-2 && c.InClipRect(x+1+pos, y) {
+				c.putCharUnsafe(x+1+pos, y, chThumb)
 			}
 		} else {
-			if pos < h-2 && InClipRect(x, y+1+pos) {
-				putCharUnsafe(x, y+1+pos, chThumb)
+			if pos < h-2 && c.InClipRect(x, y+1+pos) {
+				c.putCharUnsafe(x, y+1+pos, chThumb)
 			}
 		}
 	}
 }
 
 // FillRect paints the area with r character using the current colors
-func FillRect(x, y, w, h int, r rune) {
-	x, y, w, h = clip(x, y, w, h)
+func (c *Canvas) FillRect(x, y, w, h int, r rune) {
+	x, y, w, h = c.clip(x, y, w, h)
 	if w < 1 || y < -1 {
 		return
 	}
 
 	for yy := y; yy < y+h; yy++ {
 		for xx := x; xx < x+w; xx++ {
-			putCharUnsafe(xx, yy, r)
+			c.putCharUnsafe(xx, yy, r)
 		}
 	}
 }
@@ -521,7 +581,7 @@ func TextExtent(text string) (int, int) {
 	w := 0
 	for _, p := range parts {
 		s := UnColorizeText(p)
-		l := len(s)
+		l := TextWidth(s)
 		if l > w {
 			w = l
 		}
@@ -529,3 +589,153 @@ func TextExtent(text string) (int, int) {
 
 	return h, w
 }
+
+// PushAttributes calls Canvas.PushAttributes of the default App
+func PushAttributes() {
+	defaultApp.PushAttributes()
+}
+
+// PopAttributes calls Canvas.PopAttributes of the default App
+func PopAttributes() {
+	defaultApp.PopAttributes()
+}
+
+// PushClip calls Canvas.PushClip of the default App
+func PushClip() {
+	defaultApp.PushClip()
+}
+
+// PopClip calls Canvas.PopClip of the default App
+func PopClip() {
+	defaultApp.PopClip()
+}
+
+// Reset calls Canvas.Reset of the default App
+func Reset() {
+	defaultApp.Reset()
+}
+
+// InClipRect calls Canvas.InClipRect of the default App
+func InClipRect(x, y int) bool {
+	return defaultApp.InClipRect(x, y)
+}
+
+// Flush calls Canvas.Flush of the default App
+func Flush() {
+	defaultApp.Flush()
+}
+
+// SetScreenSize calls Canvas.SetScreenSize of the default App
+func SetScreenSize(width int, height int) {
+	defaultApp.SetScreenSize(width, height)
+}
+
+// ScreenSize calls Canvas.ScreenSize of the default App
+func ScreenSize() (width int, height int) {
+	return defaultApp.ScreenSize()
+}
+
+// SetCursorPos calls Canvas.SetCursorPos of the default App
+func SetCursorPos(x int, y int) {
+	defaultApp.SetCursorPos(x, y)
+}
+
+// HideCursor calls Canvas.HideCursor of the default App
+func HideCursor() {
+	defaultApp.HideCursor()
+}
+
+// PutChar calls Canvas.PutChar of the default App
+func PutChar(x, y int, r rune) bool {
+	return defaultApp.PutChar(x, y, r)
+}
+
+// Symbol calls Canvas.Symbol of the default App
+func Symbol(x, y int) (term.Cell, bool) {
+	return defaultApp.Symbol(x, y)
+}
+
+// SetTextColor calls Canvas.SetTextColor of the default App
+func SetTextColor(clr term.Attribute) {
+	defaultApp.SetTextColor(clr)
+}
+
+// SetBackColor calls Canvas.SetBackColor of the default App
+func SetBackColor(clr term.Attribute) {
+	defaultApp.SetBackColor(clr)
+}
+
+// ScreenColorMode calls Canvas.ScreenColorMode of the default App
+func ScreenColorMode() ColorMode {
+	return defaultApp.ScreenColorMode()
+}
+
+// SetScreenColorMode calls Canvas.SetScreenColorMode of the default App
+func SetScreenColorMode(mode ColorMode) ColorMode {
+	return defaultApp.SetScreenColorMode(mode)
+}
+
+// TextColor calls Canvas.TextColor of the default App
+func TextColor() term.Attribute {
+	return defaultApp.TextColor()
+}
+
+// BackColor calls Canvas.BackColor of the default App
+func BackColor() term.Attribute {
+	return defaultApp.BackColor()
+}
+
+// SetClipRect calls Canvas.SetClipRect of the default App
+func SetClipRect(x, y, w, h int) {
+	defaultApp.SetClipRect(x, y, w, h)
+}
+
+// ClipRect calls Canvas.ClipRect of the default App
+func ClipRect() (x int, y int, w int, h int) {
+	return defaultApp.ClipRect()
+}
+
+// DrawHorizontalLine calls Canvas.DrawHorizontalLine of the default App
+func DrawHorizontalLine(x, y, w int, r rune) {
+	defaultApp.DrawHorizontalLine(x, y, w, r)
+}
+
+// DrawVerticalLine calls Canvas.DrawVerticalLine of the default App
+func DrawVerticalLine(x, y, h int, r rune) {
+	defaultApp.DrawVerticalLine(x, y, h, r)
+}
+
+// DrawText calls Canvas.DrawText of the default App
+func DrawText(x, y int, text string) {
+	defaultApp.DrawText(x, y, text)
+}
+
+// DrawRawText calls Canvas.DrawRawText of the default App
+func DrawRawText(x, y int, text string) {
+	defaultApp.DrawRawText(x, y, text)
+}
+
+// DrawTextVertical calls Canvas.DrawTextVertical of the default App
+func DrawTextVertical(x, y int, text string) {
+	defaultApp.DrawTextVertical(x, y, text)
+}
+
+// DrawRawTextVertical calls Canvas.DrawRawTextVertical of the default App
+func DrawRawTextVertical(x, y int, text string) {
+	defaultApp.DrawRawTextVertical(x, y, text)
+}
+
+// DrawFrame calls Canvas.DrawFrame of the default App
+func DrawFrame(x, y, w, h int, border BorderStyle) {
+	defaultApp.DrawFrame(x, y, w, h, border)
+}
+
+// DrawScrollBar calls Canvas.DrawScrollBar of the default App
+func DrawScrollBar(x, y, w, h, pos int) {
+	defaultApp.DrawScrollBar(x, y, w, h, pos)
+}
+
+// FillRect calls Canvas.FillRect of the default App
+func FillRect(x, y, w, h int, r rune) {
+	defaultApp.FillRect(x, y, w, h, r)
+}
@@ -1,7 +1,6 @@
 package clui
 
 import (
-	xs "github.com/huandu/xstrings"
 	term "github.com/nsf/termbox-go"
 )
 
@@ -32,7 +31,7 @@ func CreateCheckBox(parent Control, width int, title string, scale int) *CheckBo
 	c.parent = parent
 
 	if width == AutoSize {
-		width = xs.Len(title) + 4
+		width = TextWidth(title) + 4
 	}
 
 	c.SetSize(width, 1) // TODO: only one line checkboxes are supported at that moment
@@ -60,41 +59,42 @@ func (c *CheckBox) Draw() {
 	c.mtx.RLock()
 	defer c.mtx.RUnlock()
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(c)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	x, y := c.Pos()
 	w, h := c.Size()
 
-	fg, bg := RealColor(c.fg, c.Style(), ColorControlText), RealColor(c.bg, c.Style(), ColorControlBack)
+	fg, bg := a.RealColor(c.fg, c.Style(), ColorControlText), a.RealColor(c.bg, c.Style(), ColorControlBack)
 	if !c.Enabled() {
-		fg, bg = RealColor(c.fg, c.Style(), ColorControlDisabledText), RealColor(c.bg, c.Style(), ColorControlDisabledBack)
+		fg, bg = a.RealColor(c.fg, c.Style(), ColorControlDisabledText), a.RealColor(c.bg, c.Style(), ColorControlDisabledBack)
 	} else if c.Active() {
-		fg, bg = RealColor(c.fg, c.Style(), ColorControlActiveText), RealColor(c.bg, c.Style(), ColorControlActiveBack)
+		fg, bg = a.RealColor(c.fg, c.Style(), ColorControlActiveText), a.RealColor(c.bg, c.Style(), ColorControlActiveBack)
 	}
 
-	parts := []rune(SysObject(ObjCheckBox))
+	parts := []rune(a.SysObject(ObjCheckBox))
 
 	cOpen, cClose, cEmpty, cCheck, cUnknown := parts[0], parts[1], parts[2], parts[3], parts[4]
 	cState := []rune{cEmpty, cCheck, cUnknown}
 
-	SetTextColor(fg)
-	SetBackColor(bg)
-	FillRect(x, y, w, h, ' ')
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(x, y, w, h, ' ')
 	if w < 3 {
 		return
 	}
 
-	PutChar(x, y, cOpen)
-	PutChar(x+2, y, cClose)
-	PutChar(x+1, y, cState[c.state])
+	a.PutChar(x, y, cOpen)
+	a.PutChar(x+2, y, cClose)
+	a.PutChar(x+1, y, cState[c.state])
 
 	if w < 5 {
 		return
 	}
 
 	shift, text := AlignColorizedText(c.title, w-4, c.align)
-	DrawText(x+4+shift, y, text)
+	a.DrawText(x+4+shift, y, text)
 }
 
 //ProcessEvent processes all events come from the control parent. If a control
@@ -128,6 +128,7 @@ func (c *CheckBox) ProcessEvent(event Event) bool {
 // Value must be 0 or 1 if Allow3State is off,
 // and 0, 1, or 2 if Allow3State is on
 func (c *CheckBox) SetState(val int) {
+	c.Invalidate()
 	c.mtx.Lock()
 	defer c.mtx.Unlock()
 
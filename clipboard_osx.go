@@ -0,0 +1,17 @@
+// +build darwin
+
+package clui
+
+// the system clipboard is not used on macOS(see edit_osx.go), so the text
+// is shared only between the application controls
+var clipboardText string
+
+// clipboardWrite puts the text to the application clipboard
+func clipboardWrite(text string) {
+	clipboardText = text
+}
+
+// clipboardRead returns the text from the application clipboard
+func clipboardRead() (string, bool) {
+	return clipboardText, true
+}
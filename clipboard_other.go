@@ -0,0 +1,18 @@
+// +build !darwin
+
+package clui
+
+import (
+	"github.com/atotto/clipboard"
+)
+
+// clipboardWrite puts the text to the system clipboard
+func clipboardWrite(text string) {
+	clipboard.WriteAll(text)
+}
+
+// clipboardRead returns the text from the system clipboard
+func clipboardRead() (string, bool) {
+	s, err := clipboard.ReadAll()
+	return s, err == nil
+}
@@ -1,18 +1,25 @@
 package clui
 
-import (
-	term "github.com/nsf/termbox-go"
-)
+// InitLibrary initializes the library: creates the default App(see App)
+// with its theme manager, composer, main loop, and screen. By default the
+// screen is a terminal managed by termbox. An application can pass its own
+// Backend to draw somewhere else: only the first backend is used, the rest
+// are ignored
+func InitLibrary(backend ...Backend) bool {
+	var b Backend
+	if len(backend) != 0 {
+		b = backend[0]
+	}
+	a, err := newApp(b, defaultHotkeys)
+	if err != nil {
+		return false
+	}
 
-func InitLibrary() bool {
-	initThemeManager()
-	initComposer()
-	initMainLoop()
-	return initCanvas()
+	defaultApp = a
+	return true
 }
 
 // Close closes console management and makes a console cursor visible
 func DeinitLibrary() {
-	term.SetCursor(3, 3)
-	term.Close()
+	defaultApp.Close()
 }
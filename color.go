@@ -0,0 +1,211 @@
+package clui
+
+import (
+	"os"
+	"runtime"
+	"strings"
+
+	term "github.com/nsf/termbox-go"
+)
+
+// Besides 8 base colors a color attribute can keep a color from 256-color
+// palette or a 24-bit RGB color. RGB colors use termbox encoding, so they
+// can be sent to termbox in RGB mode as is. Palette colors keep the color
+// index in the same bits and are marked with a separate flag
+const (
+	attrMask     term.Attribute = term.AttrBold | term.AttrBlink | term.AttrHidden | term.AttrDim | term.AttrUnderline | term.AttrCursive | term.AttrReverse
+	attrRGB      term.Attribute = 1 << 41
+	attrPalette  term.Attribute = 1 << 42
+	colorShift                  = 16
+	baseColorMax term.Attribute = term.ColorLightGray
+)
+
+// standard xterm values of the first 16 palette colors
+var basePalette = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0},
+	{0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0},
+	{92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// ColorRGB returns an attribute for 24-bit color. The color is displayed
+// as is only if the screen works in ColorModeRGB, otherwise the closest
+// color available in the current mode is used
+func ColorRGB(r, g, b uint8) term.Attribute {
+	return term.RGBToAttribute(r, g, b)
+}
+
+// Color256 returns an attribute for a color from xterm 256-color palette.
+// The first 16 colors are the base colors, 16-231 is 6x6x6 color cube, and
+// 232-255 is grayscale ramp. In ColorModeNormal the closest of 8 base
+// colors is used
+func Color256(index uint8) term.Attribute {
+	return attrPalette | term.Attribute(index)<<colorShift
+}
+
+// DetectColorMode returns the best color mode supported by the terminal.
+// Detection is based on COLORTERM and TERM environment variables
+func DetectColorMode() ColorMode {
+	if runtime.GOOS == "windows" {
+		return ColorModeNormal
+	}
+
+	ct := strings.ToLower(os.Getenv("COLORTERM"))
+	if ct == "truecolor" || ct == "24bit" {
+		return ColorModeRGB
+	}
+	if strings.Contains(os.Getenv("TERM"), "256color") {
+		return ColorMode256
+	}
+
+	return ColorModeNormal
+}
+
+func isRGBColor(clr term.Attribute) bool {
+	return clr&attrRGB != 0
+}
+
+func isPaletteColor(clr term.Attribute) bool {
+	return clr&attrPalette != 0 && clr&attrRGB == 0
+}
+
+// isColorSet returns true if the attribute includes any color, not only
+// modifiers like bold or underline
+func isColorSet(clr term.Attribute) bool {
+	return clr&^attrMask != 0
+}
+
+func paletteIndex(clr term.Attribute) uint8 {
+	return uint8(clr >> colorShift)
+}
+
+func paletteToRGB(idx uint8) (uint8, uint8, uint8) {
+	if idx < 16 {
+		c := basePalette[idx]
+		return c[0], c[1], c[2]
+	}
+	if idx >= 232 {
+		v := 8 + 10*(idx-232)
+		return v, v, v
+	}
+
+	idx -= 16
+	return cubeLevels[idx/36], cubeLevels[(idx/6)%6], cubeLevels[idx%6]
+}
+
+func colorDistance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
+
+func nearestCubeLevel(v uint8) int {
+	best, dist := 0, 256
+	for i, l := range cubeLevels {
+		d := int(v) - int(l)
+		if d < 0 {
+			d = -d
+		}
+		if d < dist {
+			best, dist = i, d
+		}
+	}
+	return best
+}
+
+// rgbTo256 returns the index of the closest color from the 6x6x6 cube or
+// grayscale ramp of 256-color palette
+func rgbTo256(r, g, b uint8) uint8 {
+	ri, gi, bi := nearestCubeLevel(r), nearestCubeLevel(g), nearestCubeLevel(b)
+	cube := uint8(16 + 36*ri + 6*gi + bi)
+	cr, cg, cb := paletteToRGB(cube)
+
+	avg := (int(r) + int(g) + int(b)) / 3
+	grayIdx := 23
+	if avg < 8 {
+		grayIdx = 0
+	} else if avg < 238 {
+		grayIdx = (avg - 8) / 10
+	}
+	gray := uint8(232 + grayIdx)
+	gr, gg, gb := paletteToRGB(gray)
+
+	if colorDistance(r, g, b, gr, gg, gb) < colorDistance(r, g, b, cr, cg, cb) {
+		return gray
+	}
+	return cube
+}
+
+// rgbTo16 returns the index of the closest color of the first 16 colors
+// of the palette
+func rgbTo16(r, g, b uint8) uint8 {
+	best, dist := 0, -1
+	for i, c := range basePalette {
+		d := colorDistance(r, g, b, c[0], c[1], c[2])
+		if dist == -1 || d < dist {
+			best, dist = i, d
+		}
+	}
+	return uint8(best)
+}
+
+// convertColor converts the color attribute to the one that the screen can
+// display in a given mode. Bright colors in ColorModeNormal are emulated
+// with bold attribute for text and are replaced with base colors for
+// background because on some terminals bold background blinks
+func convertColor(clr term.Attribute, mode ColorMode, back bool) term.Attribute {
+	mods := clr & attrMask
+	clr = clr &^ attrMask
+	if clr == ColorDefault {
+		return mods
+	}
+
+	var r, g, b uint8
+	switch {
+	case isRGBColor(clr):
+		if mode == ColorModeRGB {
+			return clr | mods
+		}
+		r, g, b = term.AttributeToRGB(clr)
+	case isPaletteColor(clr):
+		idx := paletteIndex(clr)
+		if mode == ColorMode256 || (mode == ColorModeNormal && idx < 16) {
+			return paletteColorForMode(idx, mode, back) | mods
+		}
+		r, g, b = paletteToRGB(idx)
+	case clr <= baseColorMax:
+		if mode != ColorModeRGB {
+			return clr | mods
+		}
+		idx := uint8(clr - 1)
+		// terminals usually display bold text with bright colors
+		if !back && idx < 8 && mods&term.AttrBold != 0 {
+			idx += 8
+		}
+		r, g, b = paletteToRGB(idx)
+	default:
+		return clr | mods
+	}
+
+	switch mode {
+	case ColorModeRGB:
+		return term.RGBToAttribute(r, g, b) | mods
+	case ColorMode256:
+		return (term.Attribute(rgbTo256(r, g, b)) + 1) | mods
+	default:
+		return paletteColorForMode(rgbTo16(r, g, b), mode, back) | mods
+	}
+}
+
+func paletteColorForMode(idx uint8, mode ColorMode, back bool) term.Attribute {
+	if mode == ColorMode256 || idx < 8 {
+		return term.Attribute(idx) + 1
+	}
+
+	clr := term.Attribute(idx-8) + 1
+	if !back {
+		clr |= term.AttrBold
+	}
+	return clr
+}
@@ -0,0 +1,44 @@
+package clui
+
+import (
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestConvertColor(t *testing.T) {
+	cases := []struct {
+		in   term.Attribute
+		mode ColorMode
+		back bool
+		want term.Attribute
+	}{
+		// base colors are untouched in palette modes
+		{ColorRed, ColorModeNormal, false, ColorRed},
+		{ColorRedBold, ColorMode256, false, ColorRedBold},
+		{ColorDefault, ColorModeRGB, false, ColorDefault},
+		{ColorRed, ColorModeRGB, false, ColorRGB(205, 0, 0)},
+		{ColorRedBold, ColorModeRGB, false, ColorRGB(255, 0, 0) | term.AttrBold},
+		{ColorRedBold, ColorModeRGB, true, ColorRGB(205, 0, 0) | term.AttrBold},
+		// palette colors
+		{Color256(202), ColorMode256, false, 203},
+		{Color256(202), ColorModeRGB, false, ColorRGB(255, 95, 0)},
+		{Color256(1), ColorModeNormal, false, ColorRed},
+		{Color256(9), ColorModeNormal, false, ColorRedBold},
+		{Color256(9), ColorModeNormal, true, ColorRed},
+		{Color256(240), ColorMode256, false, 241},
+		// RGB colors
+		{ColorRGB(255, 136, 0), ColorModeRGB, false, ColorRGB(255, 136, 0)},
+		{ColorRGB(255, 135, 0), ColorMode256, false, 209},
+		{ColorRGB(128, 128, 128), ColorMode256, false, 245},
+		{ColorRGB(0, 0, 250), ColorModeNormal, false, ColorBlue},
+		{ColorRGB(250, 250, 0) | term.AttrUnderline, ColorModeNormal, false, ColorYellowBold | term.AttrUnderline},
+	}
+
+	for _, c := range cases {
+		got := convertColor(c.in, c.mode, c.back)
+		if got != c.want {
+			t.Errorf("convertColor (%v in mode %v, back %v) == <%v>, want <%v>", ColorToString(c.in), c.mode, c.back, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package clui
+
+import (
+	term "github.com/nsf/termbox-go"
+)
+
+/*
+ComboBox is an EditField with a drop-down list of items. The list is
+displayed over other controls and windows under the ComboBox(or above it if
+there is not enough space at the bottom of the screen).
+
+The drop-down list is opened with F4, Alt+Down arrow or by clicking the
+button at the right edge of the control. Up and down arrows, PgUp and PgDn
+select the previous or next item, Enter or click on an item closes the list
+and puts the item text to the edit field, Esc closes the list without
+changes. Up and down arrows select items even if the list is closed.
+
+In editable mode a user can type any text. While typing, the list is opened
+and the first item that starts with the typed text is selected(the search
+is case insensitive, see ListBox.PartialFindItem). In read-only mode a user
+cannot change the text directly, pressing a character selects the first
+item that starts with the character, and Space or mouse click toggles
+the drop-down list.
+
+ComboBox calls onChange when its text changes: Event field Msg contains the
+new text. ComboBox calls onSelectItem after a user selects an item: Y is
+the item number, Msg is the item text, X is 1 if the item was clicked with
+mouse and 0 if it was selected with keyboard.
+*/
+type ComboBox struct {
+	BaseControl
+	edit       *EditField
+	list       *ListBox
+	drop       *dropDown
+	readonly   bool
+	dropHeight int
+
+	onChange     func(Event)
+	onSelectItem func(Event)
+}
+
+/*
+CreateComboBox creates a new editable ComboBox.
+parent - is container that keeps the control.
+width - is minimal width of the control including drop-down button.
+text - initial text.
+scale - the way of scaling the control when the parent is resized. Use DoNotScale constant if the
+control should keep its original size.
+*/
+func CreateComboBox(parent Control, width int, text string, scale int) *ComboBox {
+	c := new(ComboBox)
+	c.BaseControl = NewBaseControl()
+
+	if width == AutoSize {
+		width = TextWidth(text) + 2
+	}
+	if width < 3 {
+		width = 3
+	}
+
+	c.edit = CreateEditField(nil, width-1, text, Fixed)
+	c.edit.SetConstraints(1, 1)
+	c.edit.OnChange(func(ev Event) {
+		if c.onChange != nil {
+			c.onChange(ev)
+		}
+	})
+	c.list = newDropDownList()
+	c.drop = &dropDown{owner: c, list: c.list, onClick: func() {
+		if c.list.SelectedItem() != -1 {
+			c.itemSelected(1)
+		}
+		c.closeList()
+	}, onClose: func() {
+		// the text typed by a user may differ from the found item
+		if c.list.SelectedItemText() != c.edit.Title() {
+			c.list.currSelection = -1
+		}
+	}}
+	c.dropHeight = 7
+
+	c.SetSize(width, 1)
+	c.SetConstraints(width, 1)
+	c.parent = parent
+	c.SetTabStop(true)
+	c.SetScale(scale)
+
+	if parent != nil {
+		parent.AddChild(c)
+	}
+
+	return c
+}
+
+// Title returns the ComboBox text
+func (c *ComboBox) Title() string {
+	return c.edit.Title()
+}
+
+// SetTitle changes the ComboBox text and emits OnChange event if the new
+// value does not equal to old one. If the list contains the same text, the
+// item becomes selected
+func (c *ComboBox) SetTitle(title string) {
+	c.edit.SetTitle(title)
+	c.list.currSelection = -1
+	c.list.SelectItem(c.list.FindItem(title, false))
+}
+
+// SetSize changes control size. Constant KeepValue can be
+// used as placeholder to indicate that the control attrubute
+// should be unchanged.
+// Method does nothing if new size is less than minimal size
+// ComboBox height cannot be changed - it equals 1 always
+func (c *ComboBox) SetSize(width, height int) {
+	if width != KeepValue && (width > 1000 || width < c.minW) {
+		return
+	}
+
+	if width != KeepValue {
+		c.width = width
+	}
+
+	c.height = 1
+}
+
+// SetActive activates and deactivates the control. Deactivated ComboBox
+// closes its drop-down list
+func (c *ComboBox) SetActive(active bool) {
+	c.BaseControl.SetActive(active)
+	c.edit.SetActive(active)
+	if !active {
+		c.closeList()
+	}
+}
+
+// syncEdit copies the ComboBox state to its edit field
+func (c *ComboBox) syncEdit() {
+	e := c.edit
+	e.x, e.y = c.x, c.y
+	e.SetSize(c.width-1, 1)
+	e.fg, e.bg = c.fg, c.bg
+	e.style = c.style
+	e.readonly = c.readonly
+	e.SetEnabled(c.Enabled())
+}
+
+// Draw repaints the control on its View surface
+func (c *ComboBox) Draw() {
+	if c.hidden {
+		return
+	}
+
+	a := AppOf(c)
+	a.PushAttributes()
+	defer a.PopAttributes()
+
+	c.syncEdit()
+	c.edit.Draw()
+
+	parts := []rune(a.SysObject(ObjEdit))
+	chDown := "V"
+	if len(parts) > 2 {
+		chDown = string(parts[2])
+	}
+
+	fg, bg := a.RealColor(c.fg, c.Style(), ColorEditText), a.RealColor(c.bg, c.Style(), ColorEditBack)
+	if !c.Enabled() {
+		fg, bg = a.RealColor(c.fg, c.Style(), ColorDisabledText), a.RealColor(c.bg, c.Style(), ColorDisabledBack)
+	} else if c.Active() {
+		fg, bg = a.RealColor(c.fg, c.Style(), ColorEditActiveText), a.RealColor(c.bg, c.Style(), ColorEditActiveBack)
+	}
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.DrawRawText(c.x+c.width-1, c.y, chDown)
+}
+
+/*
+ProcessEvent processes all events come from the control parent. If a control
+processes an event it should return true. If the method returns false it means
+that the control do not want or cannot process the event and the caller sends
+the event to the control parent
+*/
+func (c *ComboBox) ProcessEvent(event Event) bool {
+	if !c.Active() || !c.Enabled() {
+		return false
+	}
+
+	c.syncEdit()
+	switch event.Type {
+	case EventKey:
+		return c.processKey(event)
+	case EventMouse:
+		if event.Key == term.MouseLeft && (c.readonly || event.X == c.x+c.width-1) {
+			c.SetDroppedDown(!c.DroppedDown())
+		}
+		return true
+	}
+
+	return false
+}
+
+func (c *ComboBox) processKey(event Event) bool {
+	opened := c.DroppedDown()
+
+	switch event.Key {
+	case term.KeyTab:
+		c.closeList()
+		return false
+	case term.KeyEsc:
+		if opened {
+			c.closeList()
+			return true
+		}
+		return false
+	case term.KeyEnter:
+		if !opened {
+			return false
+		}
+		if c.list.SelectedItem() != -1 {
+			c.itemSelected(0)
+		}
+		c.closeList()
+		return true
+	case term.KeyF4:
+		c.SetDroppedDown(!opened)
+		return true
+	case term.KeyArrowDown:
+		if event.Mod&term.ModAlt != 0 {
+			c.SetDroppedDown(true)
+		} else {
+			c.moveSelection(1)
+		}
+		return true
+	case term.KeyArrowUp:
+		c.moveSelection(-1)
+		return true
+	case term.KeyPgdn, term.KeyPgup:
+		if !opened {
+			return false
+		}
+		dy := c.list.height
+		if event.Key == term.KeyPgup {
+			dy = -dy
+		}
+		c.moveSelection(dy)
+		return true
+	case term.KeyHome, term.KeyEnd:
+		if opened || c.readonly {
+			if event.Key == term.KeyHome {
+				c.moveSelection(-c.list.ItemCount())
+			} else {
+				c.moveSelection(c.list.ItemCount())
+			}
+			return true
+		}
+	}
+
+	if c.readonly {
+		if event.Key == term.KeySpace {
+			c.SetDroppedDown(!opened)
+			return true
+		}
+		if event.Ch == 0 {
+			return false
+		}
+		if idx := c.list.PartialFindItem(string(event.Ch), false); idx != -1 && idx != c.list.SelectedItem() {
+			c.list.SelectItem(idx)
+			c.itemSelected(0)
+		}
+		return true
+	}
+
+	old := c.edit.Title()
+	res := c.edit.ProcessEvent(event)
+	if text := c.edit.Title(); text != old {
+		c.findItem(text)
+	}
+	return res
+}
+
+// findItem opens the drop-down list and selects the first item that
+// starts with text
+func (c *ComboBox) findItem(text string) {
+	c.list.currSelection = -1
+	if text == "" || c.list.ItemCount() == 0 {
+		return
+	}
+
+	c.list.SelectItem(c.list.PartialFindItem(text, false))
+	c.SetDroppedDown(true)
+}
+
+// moveSelection selects the item dy items below(or above if dy is
+// negative) the current one
+func (c *ComboBox) moveSelection(dy int) {
+	cnt := c.list.ItemCount()
+	if cnt == 0 {
+		return
+	}
+
+	curr := c.list.SelectedItem()
+	idx := curr + dy
+	if curr == -1 && dy < 0 {
+		idx = 0
+	}
+	if idx < 0 {
+		idx = 0
+	} else if idx >= cnt {
+		idx = cnt - 1
+	}
+	if idx == curr {
+		return
+	}
+
+	c.list.SelectItem(idx)
+	c.itemSelected(0)
+}
+
+// itemSelected puts the text of the selected item to the edit field and
+// emits OnSelectItem event. byMouse is 1 if the item was clicked
+func (c *ComboBox) itemSelected(byMouse int) {
+	idx := c.list.SelectedItem()
+	text := c.list.SelectedItemText()
+	c.edit.SetTitle(text)
+
+	if c.onSelectItem != nil {
+		c.onSelectItem(Event{X: byMouse, Y: idx, Msg: text})
+	}
+}
+
+func (c *ComboBox) closeList() {
+	a := AppOf(c)
+	if a != nil {
+		a.comp.closeDropDown(c)
+	}
+}
+
+// DroppedDown returns true if the drop-down list is open
+func (c *ComboBox) DroppedDown() bool {
+	a := AppOf(c)
+	return a != nil && a.comp.dropDownOpen(c)
+}
+
+// SetDroppedDown opens or closes the drop-down list. An empty list is
+// never opened
+func (c *ComboBox) SetDroppedDown(open bool) {
+	if !open {
+		c.closeList()
+		return
+	}
+
+	a := AppOf(c)
+	a.comp.openDropDown(c.drop, c.dropHeight)
+}
+
+// DropDownHeight returns the maximum number of items visible in the
+// drop-down list at a time
+func (c *ComboBox) DropDownHeight() int {
+	return c.dropHeight
+}
+
+// SetDropDownHeight changes the maximum number of items visible in the
+// drop-down list at a time
+func (c *ComboBox) SetDropDownHeight(height int) {
+	if height > 0 {
+		c.dropHeight = height
+	}
+}
+
+// ReadOnly returns true if a user can only select items from the list and
+// cannot type any text
+func (c *ComboBox) ReadOnly() bool {
+	return c.readonly
+}
+
+// SetReadOnly changes the ComboBox mode. In read-only mode a user can
+// only select items from the list
+func (c *ComboBox) SetReadOnly(readonly bool) {
+	c.readonly = readonly
+}
+
+// AddItem adds a new item to the drop-down list.
+// Returns true if the operation is successful
+func (c *ComboBox) AddItem(item string) bool {
+	return c.list.AddItem(item)
+}
+
+// RemoveItem deletes an item which number is id in item list
+// Returns true if item is deleted
+func (c *ComboBox) RemoveItem(id int) bool {
+	sel := c.list.SelectedItem()
+	if !c.list.RemoveItem(id) {
+		return false
+	}
+
+	if sel == id {
+		c.list.currSelection = -1
+	} else if sel > id {
+		c.list.currSelection--
+	}
+	return true
+}
+
+// Clear deletes all items from the drop-down list. The ComboBox text is
+// not changed
+func (c *ComboBox) Clear() {
+	c.closeList()
+	c.list.Clear()
+}
+
+// Item returns item text by its index.
+// If index is out of range an empty string and false are returned
+func (c *ComboBox) Item(id int) (string, bool) {
+	return c.list.Item(id)
+}
+
+// ItemCount returns the number of items in the drop-down list
+func (c *ComboBox) ItemCount() int {
+	return c.list.ItemCount()
+}
+
+// SelectItem selects the item which number in the list equals id and puts
+// its text to the edit field. OnSelectItem event is not emitted.
+// Returns true if the item is selected successfully
+func (c *ComboBox) SelectItem(id int) bool {
+	if !c.list.SelectItem(id) {
+		return false
+	}
+
+	c.edit.SetTitle(c.list.SelectedItemText())
+	return true
+}
+
+// SelectedItem returns the number of the selected item or -1 if the
+// ComboBox text does not come from the list
+func (c *ComboBox) SelectedItem() int {
+	return c.list.SelectedItem()
+}
+
+// OnChange sets the callback that is called when ComboBox text is changed
+func (c *ComboBox) OnChange(fn func(Event)) {
+	c.onChange = fn
+}
+
+// OnSelectItem sets a callback that is called every time a user selects
+// an item from the list
+func (c *ComboBox) OnSelectItem(fn func(Event)) {
+	c.onSelectItem = fn
+}
@@ -0,0 +1,100 @@
+package clui
+
+import (
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestComboBox(t *testing.T) {
+	b := initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 30, 6, "Fruits")
+	wnd.SetPack(Vertical)
+	combo := CreateComboBox(wnd, 20, "", Fixed)
+	CreateEditField(wnd, 20, "Under the list", Fixed)
+	for _, s := range []string{"Apple", "Banana", "Cherry", "Blueberry"} {
+		combo.AddItem(s)
+	}
+	ActivateControl(wnd, combo)
+
+	var changed []string
+	combo.OnChange(func(ev Event) {
+		changed = append(changed, ev.Msg)
+	})
+	var selected []Event
+	combo.OnSelectItem(func(ev Event) {
+		selected = append(selected, ev)
+	})
+
+	SimulateKey(term.KeyF4, 0)
+	if !combo.DroppedDown() {
+		t.Fatal("F4 must open the drop-down list")
+	}
+	SimulateKey(term.KeyArrowDown, 0)
+	checkSnapshot(t, b, "combobox")
+	SimulateKey(term.KeyEnter, 0)
+	if combo.DroppedDown() || combo.Title() != "Apple" {
+		t.Errorf("Enter must close the list and select the item: '%v'", combo.Title())
+	}
+	if len(selected) != 2 || selected[1].Y != 0 || selected[1].Msg != "Apple" {
+		t.Errorf("Invalid select events: %v", selected)
+	}
+
+	// typing opens the list and selects the first matching item
+	SimulateKey(term.KeyCtrlR, 0)
+	SimulateKey(0, 'b')
+	SimulateKey(0, 'l')
+	if !combo.DroppedDown() || combo.SelectedItem() != 3 || combo.Title() != "bl" {
+		t.Errorf("Typing must select matching item: %v '%v'", combo.SelectedItem(), combo.Title())
+	}
+	SimulateKey(term.KeyEsc, 0)
+	if combo.DroppedDown() || combo.SelectedItem() != -1 || combo.Title() != "bl" {
+		t.Errorf("Esc must close the list and keep the text: %v '%v'", combo.SelectedItem(), combo.Title())
+	}
+	if changed[len(changed)-1] != "bl" {
+		t.Errorf("Invalid change events: %v", changed)
+	}
+
+	// the button opens the list, a click on an item selects it
+	selected = nil
+	x, y := combo.Pos()
+	w, _ := combo.Size()
+	SimulateClick(x+w-1, y)
+	if !combo.DroppedDown() {
+		t.Fatal("Click on the button must open the list")
+	}
+	SimulateClick(5, 4)
+	if combo.DroppedDown() || combo.Title() != "Cherry" {
+		t.Errorf("Click must select the item: '%v'", combo.Title())
+	}
+	if len(selected) != 1 || selected[0].X != 1 || selected[0].Y != 2 {
+		t.Errorf("Invalid select events: %v", selected)
+	}
+	if !combo.Active() {
+		t.Error("Click on the list must not reach controls under it")
+	}
+
+	// a click outside the list closes it
+	SimulateKey(term.KeyF4, 0)
+	SimulateClick(35, 8)
+	if combo.DroppedDown() {
+		t.Error("Click outside the list must close it")
+	}
+
+	combo.SetReadOnly(true)
+	SimulateKey(0, 'b')
+	SimulateKey(0, 'x')
+	if combo.Title() != "Banana" || combo.SelectedItem() != 1 {
+		t.Errorf("Read-only ComboBox must select item by the first letter: '%v'", combo.Title())
+	}
+	SimulateKey(term.KeySpace, 0)
+	if !combo.DroppedDown() {
+		t.Error("Space must open the list in read-only mode")
+	}
+	SimulateKey(term.KeyTab, 0)
+	if combo.DroppedDown() || combo.Active() {
+		t.Error("Tab must close the list and move focus")
+	}
+}
@@ -0,0 +1,172 @@
+package clui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	term "github.com/nsf/termbox-go"
+)
+
+// the maximum number of completion candidates visible at a time
+const completerHeight = 8
+
+/*
+SetCompleter sets the function that returns completion candidates for the
+text before the cursor. While a user types, the candidates are displayed
+in a list under the EditField. Up and down arrows cycle through the list,
+Tab, Enter or a click on an item replaces the text before the cursor with
+the selected candidate, Esc closes the list. The list is not displayed if
+there are no candidates or the only candidate equals the typed text.
+nil removes the completer. See PathCompleter for a ready to use file
+system completer
+*/
+func (e *EditField) SetCompleter(fn func(prefix string) []string) {
+	e.completer = fn
+	if fn == nil {
+		e.closeCompletion()
+		return
+	}
+
+	if e.compList == nil {
+		e.compList = newDropDownList()
+		e.compDrop = &dropDown{owner: e, list: e.compList, onClick: func() {
+			e.acceptCompletion()
+		}}
+	}
+}
+
+// completionOpen returns true if the list of completion candidates
+// is displayed
+func (e *EditField) completionOpen() bool {
+	a := AppOf(e)
+	return a != nil && e.compDrop != nil && a.comp.dropDownOpen(e)
+}
+
+func (e *EditField) closeCompletion() {
+	a := AppOf(e)
+	if a != nil {
+		a.comp.closeDropDown(e)
+	}
+}
+
+// updateCompletion asks the completer for candidates for the text before
+// the cursor and displays them. The first candidate is selected
+func (e *EditField) updateCompletion() {
+	a := AppOf(e)
+	if e.completer == nil || a == nil {
+		return
+	}
+
+	prefix := string([]rune(e.title)[:e.cursorPos])
+	items := e.completer(prefix)
+	if len(items) == 0 || (len(items) == 1 && items[0] == prefix) {
+		e.closeCompletion()
+		return
+	}
+
+	e.compList.Clear()
+	for _, item := range items {
+		e.compList.AddItem(item)
+	}
+	e.compList.SelectItem(0)
+	a.comp.openDropDown(e.compDrop, completerHeight)
+}
+
+// processCompletionKey handles keys that control the open list of
+// completion candidates. Returns false if the list is closed or the key
+// must be processed by the EditField
+func (e *EditField) processCompletionKey(event Event) bool {
+	if !e.completionOpen() {
+		return false
+	}
+
+	switch event.Key {
+	case term.KeyTab, term.KeyEnter:
+		e.acceptCompletion()
+	case term.KeyEsc:
+		e.closeCompletion()
+	case term.KeyArrowDown:
+		e.cycleCompletion(1)
+	case term.KeyArrowUp:
+		e.cycleCompletion(-1)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// cycleCompletion selects the candidate dy items below(or above if dy is
+// negative) the current one. The selection wraps around
+func (e *EditField) cycleCompletion(dy int) {
+	cnt := e.compList.ItemCount()
+	idx := (e.compList.SelectedItem() + dy) % cnt
+	if idx < 0 {
+		idx += cnt
+	}
+	e.compList.SelectItem(idx)
+}
+
+// acceptCompletion closes the list and replaces the text before the
+// cursor with the selected candidate
+func (e *EditField) acceptCompletion() {
+	text := e.compList.SelectedItemText()
+	e.closeCompletion()
+	if text == "" || !e.beginEdit(editOther) {
+		return
+	}
+
+	e.selStart = 0
+	e.insertText(text)
+}
+
+/*
+PathCompleter returns a completer for EditField.SetCompleter that
+completes names of files and directories. The typed text is a path: the
+candidates are the entries of its directory(the working directory if the
+path does not contain a directory) which names start with the last path
+element. Directory names end with path separator, so a user can continue
+typing names inside the directory. Hidden files are listed only if the
+last element starts with a dot. fileMasks filters files the same way as
+in FileSelectDialog: a list of masks separated with commas or colons,
+e.g "*.txt,*.md". Empty string, * and *.* match any file
+*/
+func PathCompleter(fileMasks string) func(string) []string {
+	masks := parseFileMasks(fileMasks)
+
+	return func(prefix string) []string {
+		dir, base := filepath.Split(prefix)
+		readDir := dir
+		if readDir == "" {
+			readDir = "."
+		}
+
+		f, err := os.Open(readDir)
+		if err != nil {
+			return nil
+		}
+		finfos, err := f.Readdir(0)
+		f.Close()
+		if err != nil {
+			return nil
+		}
+
+		sortFileInfos(finfos)
+		var res []string
+		for _, finfo := range finfos {
+			name := finfo.Name()
+			if !strings.HasPrefix(name, base) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".")) {
+				continue
+			}
+
+			if finfo.IsDir() {
+				res = append(res, dir+name+string(os.PathSeparator))
+			} else if fileMatchesMasks(name, masks) {
+				res = append(res, dir+name)
+			}
+		}
+
+		return res
+	}
+}
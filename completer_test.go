@@ -0,0 +1,117 @@
+package clui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestEditFieldCompleter(t *testing.T) {
+	b := initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 30, 6, "Command")
+	wnd.SetPack(Vertical)
+	edit := CreateEditField(wnd, 20, "", Fixed)
+	next := CreateEditField(wnd, 20, "", Fixed)
+	words := []string{"commit", "config", "clone", "checkout"}
+	edit.SetCompleter(func(prefix string) []string {
+		var res []string
+		for _, w := range words {
+			if strings.HasPrefix(w, prefix) {
+				res = append(res, w)
+			}
+		}
+		return res
+	})
+	ActivateControl(wnd, edit)
+
+	typeText("co")
+	if !edit.completionOpen() || edit.compList.ItemCount() != 2 {
+		t.Fatal("Typing must display the candidates")
+	}
+	SimulateKey(term.KeyArrowUp, 0)
+	if edit.compList.SelectedItemText() != "config" {
+		t.Errorf("Arrows must cycle through candidates: %q", edit.compList.SelectedItemText())
+	}
+	RefreshScreen()
+	checkSnapshot(t, b, "completer")
+	SimulateKey(term.KeyTab, 0)
+	if edit.Title() != "config" || edit.cursorPos != 6 || edit.completionOpen() {
+		t.Errorf("Tab must accept the candidate: %q at %v", edit.Title(), edit.cursorPos)
+	}
+	if !edit.Active() {
+		t.Error("Tab that accepts a candidate must not move focus")
+	}
+	SimulateKey(term.KeyCtrlZ, 0)
+	if edit.Title() != "co" {
+		t.Errorf("Completion must be undoable: %q", edit.Title())
+	}
+
+	// the only candidate that equals the text is not displayed
+	SimulateKey(term.KeyCtrlR, 0)
+	typeText("clone")
+	if edit.completionOpen() {
+		t.Error("The list must be closed if the text is complete")
+	}
+	SimulateKey(term.KeyBackspace2, 0)
+	SimulateKey(term.KeyEsc, 0)
+	if edit.completionOpen() || edit.Title() != "clon" {
+		t.Errorf("Esc must close the list: %q", edit.Title())
+	}
+	typeText("x")
+	if edit.completionOpen() {
+		t.Error("The list must be closed without candidates")
+	}
+
+	// a click on a candidate accepts it
+	SimulateKey(term.KeyCtrlR, 0)
+	typeText("c")
+	lx, ly := edit.compList.Pos()
+	SimulateClick(lx+1, ly+3)
+	if edit.Title() != "checkout" {
+		t.Errorf("Click must accept the candidate: %q", edit.Title())
+	}
+	SimulateKey(term.KeyTab, 0)
+	if !next.Active() {
+		t.Error("Tab must move focus if the list is closed")
+	}
+}
+
+func TestPathCompleter(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"notes.txt", "note.md", "nothing.go", ".notes"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "notebook"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	sep := string(os.PathSeparator)
+	prefix := dir + sep
+	cases := []struct {
+		masks string
+		typed string
+		res   []string
+	}{
+		{"", "note", []string{"notebook" + sep, "note.md", "notes.txt"}},
+		{"*.txt,*.go", "no", []string{"notebook" + sep, "notes.txt", "nothing.go"}},
+		{"", ".", []string{".notes"}},
+		{"", "x", nil},
+	}
+	for _, c := range cases {
+		res := PathCompleter(c.masks)(prefix + c.typed)
+		for i := range res {
+			res[i] = strings.TrimPrefix(res[i], prefix)
+		}
+		if !reflect.DeepEqual(res, c.res) {
+			t.Errorf("Invalid candidates for %q with masks %q: %v", c.typed, c.masks, res)
+		}
+	}
+}
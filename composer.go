@@ -3,20 +3,23 @@ package clui
 import (
 	term "github.com/nsf/termbox-go"
 	"sync"
+	"time"
 )
 
 // Composer is a service object that manages Views and console, processes
-// events, and provides service methods. One application must have only
-// one object of this type
+// events, and provides service methods. Every App has its own object of
+// this type
 type Composer struct {
 	// list of visible Views
 	windows      []Control
 	windowBorder BorderStyle
 	consumer     Control
-	// last pressed key - to make repeatable actions simpler, e.g, at first
-	// one presses Ctrl+S and then just repeatedly presses arrow lest to
+	// keys of unfinished hotkey sequence. After a sticky hotkey the keys
+	// are kept to make repeatable actions simpler, e.g, at first one
+	// presses Ctrl+S and then just repeatedly presses arrow left to
 	// resize Window
-	lastKey term.Key
+	pendingKeys []KeyPress
+	stickyKeys  bool
 	// coordinates when the mouse button was down, e.g to detect
 	// mouse click
 	mdownX, mdownY int
@@ -24,75 +27,100 @@ type Composer struct {
 	lastX, lastY int
 	// Type of dragging
 	dragType DragType
+	// menu bar at the top of the screen and open menus: the first one is
+	// a drop-down of menu bar item or a popup menu, the others are
+	// submenus. barItem is the highlighted menu bar item(-1 - none)
+	menuBar *MenuBar
+	menus   []*Menu
+	barItem int
+	// status bar at the bottom of the screen
+	statusBar *StatusBar
+	// open drop-down list, e.g of a ComboBox. eatRelease is true if the
+	// drop-down list has processed the mouse button press, so the following
+	// button release must not reach windows
+	dropDown   *dropDown
+	eatRelease bool
+	// the last pressed mouse button, and the time and place of the last
+	// left button press to detect double clicks
+	lastButton     term.Key
+	clickTime      time.Time
+	clickX, clickY int
+	// the control under the mouse cursor and the tooltip: its text(empty
+	// if the tooltip is hidden), position and the timer that displays it
+	hover      Control
+	tipText    string
+	tipX, tipY int
+	tipTimer   *Timer
+	// the area of the screen that must be repainted. Controls invalidate
+	// areas while Window.Draw holds mtx, so dirty has its own mutex
+	dirty    damage
+	dirtyMtx sync.Mutex
 	// For safe Window manipulations
 	mtx sync.RWMutex
+	// the App that owns the Composer
+	app *App
 }
 
-var (
-	comp *Composer
-)
-
-func initComposer() {
-	comp = new(Composer)
-	comp.windows = make([]Control, 0)
-	comp.windowBorder = BorderAuto
-	comp.consumer = nil
-	comp.lastKey = term.KeyEsc
+func newComposer(a *App) *Composer {
+	c := new(Composer)
+	c.app = a
+	c.windows = make([]Control, 0)
+	c.windowBorder = BorderAuto
+	c.consumer = nil
+	c.barItem = -1
+	return c
 }
 
 // WindowManager returns main Window manager (that is Composer). Use it at
 // your own risk because it provides an access to some low level Window
 // manipulations.
-// Note: Now it is not thread safe to call Composer methods from a few threads.
-func WindowManager() *Composer {
-	return comp
+// Note: it is not thread safe to call Composer methods from a few threads.
+// Call them from the main loop goroutine, or wrap with Invoke if the call
+// is made from other goroutine.
+func (a *App) WindowManager() *Composer {
+	return a.comp
 }
 
 // GrabEvents makes control c as the exclusive event reciever. After calling
 // this function the control will recieve all mouse and keyboard events even
 // if it is not active or mouse is outside it. Useful to implement dragging
 // or alike stuff
-func GrabEvents(c Control) {
-	comp.consumer = c
+func (a *App) GrabEvents(c Control) {
+	a.comp.consumer = c
 }
 
 // ReleaseEvents stops a control being exclusive evetn reciever and backs all
 // to normal event processing
-func ReleaseEvents() {
-	comp.consumer = nil
+func (a *App) ReleaseEvents() {
+	a.comp.consumer = nil
 }
 
-func termboxEventToLocal(ev term.Event) Event {
-	e := Event{Type: EventType(ev.Type), Ch: ev.Ch,
-		Key: ev.Key, Err: ev.Err, X: ev.MouseX, Y: ev.MouseY,
-		Mod: ev.Mod, Width: ev.Width, Height: ev.Height}
-	return e
-}
+// RefreshScreen repaints everything on the screen immediately and flushes
+// the screen once. Usually it is better to mark changed areas with
+// InvalidateRect or Invalidate: the main loop repaints only them
+func (a *App) RefreshScreen() {
+	c := a.comp
+	c.dirtyMtx.Lock()
+	c.dirty = damage{painting: true}
+	c.dirtyMtx.Unlock()
+	defer c.setPainting(false)
 
-// Repaints everything on the screen
-func RefreshScreen() {
-	comp.BeginUpdate()
-	term.Clear(ColorWhite, ColorBlack)
-	comp.EndUpdate()
+	c.BeginUpdate()
+	a.clearScreen(ColorWhite, ColorBlack)
+	c.EndUpdate()
 
-	windows := comp.getWindowList()
+	windows := c.getWindowList()
 	for _, wnd := range windows {
-		v := wnd.(*Window)
-		if v.Visible() {
+		if wnd.Visible() {
 			wnd.Draw()
-
-			WindowManager().BeginUpdate()
-			PushAttributes()
-			term.Flush()
-			PopAttributes()
-			WindowManager().EndUpdate()
-
 		}
 	}
 
-	comp.BeginUpdate()
-	term.Flush()
-	comp.EndUpdate()
+	c.drawOverlays()
+
+	c.BeginUpdate()
+	a.Flush()
+	c.EndUpdate()
 }
 
 // AddWindow constucts a new Window, adds it to the composer automatically,
@@ -100,23 +128,61 @@ func RefreshScreen() {
 // posX and posY are top left coordinates of the Window
 // width and height are Window size
 // title is a Window title
-func AddWindow(posX, posY, width, height int, title string) *Window {
-	window := CreateWindow(posX, posY, width, height, title)
-	window.SetBorder(comp.windowBorder)
+func (a *App) AddWindow(posX, posY, width, height int, title string) *Window {
+	window := a.comp.newWindow(posX, posY, width, height, title)
+	a.comp.addWindow(window)
+	return window
+}
 
-	comp.BeginUpdate()
-	comp.windows = append(comp.windows, window)
-	comp.EndUpdate()
-	window.Draw()
-	term.Flush()
+// WindowManager calls App.WindowManager of the default App
+func WindowManager() *Composer {
+	return defaultApp.WindowManager()
+}
+
+// GrabEvents calls App.GrabEvents of the default App
+func GrabEvents(c Control) {
+	defaultApp.GrabEvents(c)
+}
 
-	comp.activateWindow(window)
+// ReleaseEvents calls App.ReleaseEvents of the default App
+func ReleaseEvents() {
+	defaultApp.ReleaseEvents()
+}
+
+// RefreshScreen calls App.RefreshScreen of the default App
+func RefreshScreen() {
+	defaultApp.RefreshScreen()
+}
 
-	RefreshScreen()
+// AddWindow calls App.AddWindow of the default App
+func AddWindow(posX, posY, width, height int, title string) *Window {
+	return defaultApp.AddWindow(posX, posY, width, height, title)
+}
 
+// newWindow creates a Window of the App with the default border that
+// fits the work area but does not add it to the list of managed Windows
+func (c *Composer) newWindow(posX, posY, width, height int, title string) *Window {
+	if _, top, _, _ := c.workArea(); posY < top {
+		posY = top
+	}
+	window := createWindow(c.app, posX, posY, width, height, title)
+	window.SetBorder(c.windowBorder)
 	return window
 }
 
+// addWindow adds the Window on top of the others and activates it
+func (c *Composer) addWindow(window *Window) {
+	c.BeginUpdate()
+	c.windows = append(c.windows, window)
+	c.EndUpdate()
+	window.Draw()
+	c.app.Flush()
+
+	c.activateWindow(window)
+
+	c.app.InvalidateScreen()
+}
+
 // Border returns the default window border
 func (c *Composer) BorderStyle() BorderStyle {
 	return c.windowBorder
@@ -250,7 +316,7 @@ func (c *Composer) moveActiveWindowToBottom() bool {
 
 	event = Event{Type: EventActivate, X: 1} // send 'activated'
 	c.sendEventToActiveWindow(event)
-	RefreshScreen()
+	c.app.InvalidateScreen()
 
 	return true
 }
@@ -302,7 +368,7 @@ func (c *Composer) resizeTopWindow(ev Event) bool {
 		view.SetSize(w, h)
 		event := Event{Type: EventResize, X: w, Y: h}
 		c.sendEventToActiveWindow(event)
-		RefreshScreen()
+		c.app.InvalidateScreen()
 	}
 
 	return true
@@ -319,14 +385,14 @@ func (c *Composer) moveTopWindow(ev Event) bool {
 		x, y := view.Pos()
 		w, h := view.Size()
 		x1, y1 := x, y
-		cx, cy := term.Size()
-		if ev.Key == term.KeyArrowUp && y > 0 {
+		ax, ay, aw, ah := c.workArea()
+		if ev.Key == term.KeyArrowUp && y > ay {
 			y--
-		} else if ev.Key == term.KeyArrowDown && y+h < cy {
+		} else if ev.Key == term.KeyArrowDown && y+h < ay+ah {
 			y++
-		} else if ev.Key == term.KeyArrowLeft && x > 0 {
+		} else if ev.Key == term.KeyArrowLeft && x > ax {
 			x--
-		} else if ev.Key == term.KeyArrowRight && x+w < cx {
+		} else if ev.Key == term.KeyArrowRight && x+w < ax+aw {
 			x++
 		}
 
@@ -334,7 +400,7 @@ func (c *Composer) moveTopWindow(ev Event) bool {
 			view.SetPos(x, y)
 			event := Event{Type: EventMove, X: x, Y: y}
 			c.sendEventToActiveWindow(event)
-			RefreshScreen()
+			c.app.InvalidateScreen()
 		}
 		return true
 	}
@@ -342,6 +408,23 @@ func (c *Composer) moveTopWindow(ev Event) bool {
 	return false
 }
 
+// canQuit asks all windows starting from the top one whether the
+// application can quit. Any window can veto quitting with OnQuit callback
+func (c *Composer) canQuit(ev Event) bool {
+	windows := c.getWindowList()
+	for i := len(windows) - 1; i >= 0; i-- {
+		wnd, ok := windows[i].(*Window)
+		if !ok || wnd.onQuit == nil {
+			continue
+		}
+		if !wnd.onQuit(ev) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (c *Composer) closeTopWindow() {
 	if len(c.windows) > 1 {
 		view := c.topWindow()
@@ -355,12 +438,19 @@ func (c *Composer) closeTopWindow() {
 			c.sendEventToActiveWindow(event)
 		}
 
-		RefreshScreen()
+		c.app.InvalidateScreen()
 	} else {
-		go Stop()
+		c.app.Stop()
 	}
 }
 
+// insideWorkArea returns true if a window with given position and size
+// does not cross the work area border
+func (c *Composer) insideWorkArea(x, y, w, h int) bool {
+	ax, ay, aw, ah := c.workArea()
+	return x >= ax && y >= ay && x+w < ax+aw && y+h < ay+ah
+}
+
 func (c *Composer) processWindowDrag(ev Event) {
 	if ev.Mod != term.ModMotion || c.dragType == DragNone {
 		return
@@ -374,25 +464,24 @@ func (c *Composer) processWindowDrag(ev Event) {
 	w := c.topWindow()
 	newX, newY := w.Pos()
 	newW, newH := w.Size()
-	cw, ch := ScreenSize()
 
 	switch c.dragType {
 	case DragMove:
 		newX = newX + dx
 		newY = newY + dy
-		if newX >= 0 && newY >= 0 && newX+newW < cw && newY+newH < ch {
+		if c.insideWorkArea(newX, newY, newW, newH) {
 			c.lastX = ev.X
 			c.lastY = ev.Y
 
 			w.SetPos(newX, newY)
 			event := Event{Type: EventMove, X: newX, Y: newY}
 			c.sendEventToActiveWindow(event)
-			RefreshScreen()
+			c.app.InvalidateScreen()
 		}
 	case DragResizeLeft:
 		newX = newX + dx
 		newW = newW - dx
-		if newX >= 0 && newY >= 0 && newX+newW < cw && newY+newH < ch {
+		if c.insideWorkArea(newX, newY, newW, newH) {
 			c.lastX = ev.X
 			c.lastY = ev.Y
 
@@ -402,36 +491,36 @@ func (c *Composer) processWindowDrag(ev Event) {
 			c.sendEventToActiveWindow(event)
 			event.Type = EventResize
 			c.sendEventToActiveWindow(event)
-			RefreshScreen()
+			c.app.InvalidateScreen()
 		}
 	case DragResizeRight:
 		newW = newW + dx
-		if newX >= 0 && newY >= 0 && newX+newW < cw && newY+newH < ch {
+		if c.insideWorkArea(newX, newY, newW, newH) {
 			c.lastX = ev.X
 			c.lastY = ev.Y
 
 			w.SetSize(newW, newH)
 			event := Event{Type: EventResize}
 			c.sendEventToActiveWindow(event)
-			RefreshScreen()
+			c.app.InvalidateScreen()
 		}
 	case DragResizeBottom:
 		newH = newH + dy
-		if newX >= 0 && newY >= 0 && newX+newW < cw && newY+newH < ch {
+		if c.insideWorkArea(newX, newY, newW, newH) {
 			c.lastX = ev.X
 			c.lastY = ev.Y
 
 			w.SetSize(newW, newH)
 			event := Event{Type: EventResize}
 			c.sendEventToActiveWindow(event)
-			RefreshScreen()
+			c.app.InvalidateScreen()
 		}
 	case DragResizeTopLeft:
 		newX = newX + dx
 		newW = newW - dx
 		newY = newY + dy
 		newH = newH - dy
-		if newX >= 0 && newY >= 0 && newX+newW < cw && newY+newH < ch {
+		if c.insideWorkArea(newX, newY, newW, newH) {
 			c.lastX = ev.X
 			c.lastY = ev.Y
 
@@ -441,13 +530,13 @@ func (c *Composer) processWindowDrag(ev Event) {
 			c.sendEventToActiveWindow(event)
 			event.Type = EventResize
 			c.sendEventToActiveWindow(event)
-			RefreshScreen()
+			c.app.InvalidateScreen()
 		}
 	case DragResizeBottomLeft:
 		newX = newX + dx
 		newW = newW - dx
 		newH = newH + dy
-		if newX >= 0 && newY >= 0 && newX+newW < cw && newY+newH < ch {
+		if c.insideWorkArea(newX, newY, newW, newH) {
 			c.lastX = ev.X
 			c.lastY = ev.Y
 
@@ -457,25 +546,25 @@ func (c *Composer) processWindowDrag(ev Event) {
 			c.sendEventToActiveWindow(event)
 			event.Type = EventResize
 			c.sendEventToActiveWindow(event)
-			RefreshScreen()
+			c.app.InvalidateScreen()
 		}
 	case DragResizeBottomRight:
 		newW = newW + dx
 		newH = newH + dy
-		if newX >= 0 && newY >= 0 && newX+newW < cw && newY+newH < ch {
+		if c.insideWorkArea(newX, newY, newW, newH) {
 			c.lastX = ev.X
 			c.lastY = ev.Y
 
 			w.SetSize(newW, newH)
 			event := Event{Type: EventResize}
 			c.sendEventToActiveWindow(event)
-			RefreshScreen()
+			c.app.InvalidateScreen()
 		}
 	case DragResizeTopRight:
 		newY = newY + dy
 		newW = newW + dx
 		newH = newH - dy
-		if newX >= 0 && newY >= 0 && newX+newW < cw && newY+newH < ch {
+		if c.insideWorkArea(newX, newY, newW, newH) {
 			c.lastX = ev.X
 			c.lastY = ev.Y
 
@@ -485,17 +574,31 @@ func (c *Composer) processWindowDrag(ev Event) {
 			c.sendEventToActiveWindow(event)
 			event.Type = EventResize
 			c.sendEventToActiveWindow(event)
-			RefreshScreen()
+			c.app.InvalidateScreen()
 		}
 	}
 }
 
 func (c *Composer) processMouse(ev Event) {
 	if c.consumer != nil {
-		tmp := c.consumer
-		tmp.ProcessEvent(ev)
-		tmp.Draw()
-		term.Flush()
+		c.consumer.ProcessEvent(ev)
+		return
+	}
+
+	if ev.Mod != term.ModMotion && (ev.Key == term.MouseLeft || ev.Key == term.MouseRight || ev.Key == term.MouseMiddle) {
+		c.lastButton = ev.Key
+	}
+	c.trackHover(ev)
+	if isHoverEvent(ev) && c.dragType == DragNone {
+		return
+	}
+
+	if c.processMenuMouse(ev) || c.processDropDownMouse(ev) || c.processStatusMouse(ev) {
+		return
+	}
+
+	if dx, dy := WheelDelta(ev); dx != 0 || dy != 0 {
+		c.processWheel(ev)
 		return
 	}
 
@@ -504,6 +607,12 @@ func (c *Composer) processMouse(ev Event) {
 		view = c.topWindow()
 	}
 
+	if (ev.Key == term.MouseRight || ev.Key == term.MouseMiddle) && ev.Mod != term.ModMotion &&
+		hit == HitInside && view == c.topWindow() && c.dragType == DragNone {
+		c.processButtonClick(view, ev)
+		return
+	}
+
 	if c.topWindow() == view {
 		if ev.Key == term.MouseRelease && c.dragType != DragNone {
 			c.dragType = DragNone
@@ -562,10 +671,14 @@ func (c *Composer) processMouse(ev Event) {
 		c.mdownX = ev.X
 		c.mdownY = ev.Y
 		c.sendEventToActiveWindow(ev)
+		if ev.Mod != term.ModMotion && c.isDoubleClick(ev) {
+			c.sendMouseEvent(c.topWindow(), ev, EventDoubleClick)
+		}
 		return
 	} else if ev.Key == term.MouseRelease {
 		c.sendEventToActiveWindow(ev)
-		if c.lastX != ev.X && c.lastY != ev.Y {
+		// only the left button clicks controls
+		if c.lastButton != term.MouseLeft || (c.lastX != ev.X && c.lastY != ev.Y) {
 			return
 		}
 
@@ -578,11 +691,37 @@ func (c *Composer) processMouse(ev Event) {
 	}
 }
 
-// Stop sends termination event to Composer. Composer should stop
-// console management and quit application
+// processWheel sends a mouse wheel event to the control under the mouse
+// cursor even if the control or its window is inactive. If the control
+// cannot scroll, the event goes to its parent, and so on up to the window
+func (c *Composer) processWheel(ev Event) {
+	view, hit := c.checkWindowUnderMouse(ev.X, ev.Y)
+	if view == nil || hit != HitInside {
+		return
+	}
+	if top := c.topWindow(); view != top && top.Modal() {
+		return
+	}
+
+	for ctrl := ChildAt(view, ev.X, ev.Y); ctrl != nil && ctrl != view; ctrl = ctrl.Parent() {
+		ev.Target = ctrl
+		if ctrl.ProcessEvent(ev) {
+			c.app.InvalidateScreen()
+			return
+		}
+	}
+}
+
+// Stop sends termination event to the App main loop. The main loop
+// quits unless a Window vetoes it(see Window.OnQuit). It can be called
+// from any goroutine
+func (a *App) Stop() {
+	a.loop.put(Event{Type: EventQuit})
+}
+
+// Stop calls App.Stop of the default App
 func Stop() {
-	ev := Event{Type: EventQuit}
-	go PutEvent(ev)
+	defaultApp.Stop()
 }
 
 // DestroyWindow removes the Window from the list of managed Windows
@@ -599,7 +738,7 @@ func (c *Composer) DestroyWindow(view Control) {
 	}
 
 	if len(newOrder) == 0 {
-		go Stop()
+		c.app.Stop()
 		return
 	}
 
@@ -612,106 +751,169 @@ func (c *Composer) DestroyWindow(view Control) {
 // IsDeadKey returns true if the pressed key is the first key in
 // the key sequence understood by composer. Dead key is never sent to
 // any control
+func (a *App) IsDeadKey(key term.Key) bool {
+	_, prefix := a.hotkeys.find([]KeyPress{{Key: key}})
+	return prefix
+}
+
+// IsDeadKey calls App.IsDeadKey of the default App
 func IsDeadKey(key term.Key) bool {
-	if key == term.KeyCtrlS || key == term.KeyCtrlP ||
-		key == term.KeyCtrlW || key == term.KeyCtrlQ {
+	return defaultApp.IsDeadKey(key)
+}
+
+func (c *Composer) resetHotkey() {
+	c.pendingKeys = nil
+	c.stickyKeys = false
+}
+
+// runHotkey checks if the key sequence is a hotkey or the beginning of
+// a hotkey, and returns true if the last key must not be sent to
+// the active window
+func (c *Composer) runHotkey(keys []KeyPress, ev Event) bool {
+	b, prefix := c.app.hotkeys.find(keys)
+	if b != nil {
+		var processed bool
+		if b.custom {
+			processed = b.fn(ev)
+		} else {
+			processed = c.runHotkeyAction(b.action)
+		}
+		if processed {
+			if b.sticky && len(keys) > 1 {
+				c.pendingKeys = keys[:len(keys)-1]
+				c.stickyKeys = true
+			} else {
+				c.resetHotkey()
+			}
+			return true
+		}
+	}
+
+	if prefix {
+		c.pendingKeys = keys
+		c.stickyKeys = false
 		return true
 	}
 
 	return false
 }
 
-func (c *Composer) processKey(ev Event) {
-	if ev.Key == term.KeyEsc {
-		if IsDeadKey(c.lastKey) {
-			c.lastKey = term.KeyEsc
-			return
+func (c *Composer) runHotkeyAction(action HotkeyAction) bool {
+	switch action {
+	case HotkeyQuit:
+		c.app.Stop()
+	case HotkeyWindowHide:
+		c.moveActiveWindowToBottom()
+	case HotkeyWindowMaximize:
+		w, ok := c.topWindow().(*Window)
+		if ok && w.Sizable() && (w.TitleButtons()&ButtonMaximize == ButtonMaximize) {
+			maxxed := w.Maximized()
+			w.SetMaximized(!maxxed)
+			c.app.InvalidateScreen()
+		}
+	case HotkeyWindowClose:
+		c.closeTopWindow()
+	case HotkeyWindowMoveUp:
+		c.moveTopWindow(Event{Type: EventKey, Key: term.KeyArrowUp})
+	case HotkeyWindowMoveDown:
+		c.moveTopWindow(Event{Type: EventKey, Key: term.KeyArrowDown})
+	case HotkeyWindowMoveLeft:
+		c.moveTopWindow(Event{Type: EventKey, Key: term.KeyArrowLeft})
+	case HotkeyWindowMoveRight:
+		c.moveTopWindow(Event{Type: EventKey, Key: term.KeyArrowRight})
+	case HotkeyWindowShorter:
+		c.resizeTopWindow(Event{Type: EventKey, Key: term.KeyArrowUp})
+	case HotkeyWindowTaller:
+		c.resizeTopWindow(Event{Type: EventKey, Key: term.KeyArrowDown})
+	case HotkeyWindowNarrower:
+		c.resizeTopWindow(Event{Type: EventKey, Key: term.KeyArrowLeft})
+	case HotkeyWindowWider:
+		c.resizeTopWindow(Event{Type: EventKey, Key: term.KeyArrowRight})
+	case HotkeyMenuBar:
+		if c.menuBar == nil || len(c.menuBar.items) == 0 {
+			return false
 		}
+		c.selectBarItem(0, false)
+		c.app.InvalidateScreen()
+	case HotkeyTabPrev, HotkeyTabNext, HotkeyTab1, HotkeyTab2, HotkeyTab3,
+		HotkeyTab4, HotkeyTab5, HotkeyTab6, HotkeyTab7, HotkeyTab8, HotkeyTab9:
+		return c.switchTab(action)
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (c *Composer) sendKey(ev Event) {
+	if c.consumer != nil {
+		c.consumer.ProcessEvent(ev)
+	} else if c.topWindow() != nil {
+		c.sendEventToActiveWindow(ev)
 	}
+}
 
-	if IsDeadKey(ev.Key) && !IsDeadKey(c.lastKey) {
-		c.lastKey = ev.Key
+func (c *Composer) processKey(ev Event) {
+	c.hideTooltip()
+	if c.menuActive() {
+		c.processMenuKey(ev)
 		return
 	}
 
-	if !IsDeadKey(ev.Key) {
-		if c.consumer != nil {
-			tmp := c.consumer
-			tmp.ProcessEvent(ev)
-			tmp.Draw()
-			term.Flush()
-		} else {
-			c.sendEventToActiveWindow(ev)
-			c.topWindow().Draw()
-			term.Flush()
-		}
-	}
-
-	newKey := term.KeyEsc
-	switch c.lastKey {
-	case term.KeyCtrlQ:
-		switch ev.Key {
-		case term.KeyCtrlQ:
-			Stop()
-		default:
-			newKey = ev.Key
-		}
-	case term.KeyCtrlS:
-		switch ev.Key {
-		case term.KeyArrowUp, term.KeyArrowDown, term.KeyArrowLeft, term.KeyArrowRight:
-			c.resizeTopWindow(ev)
-		default:
-			newKey = ev.Key
-		}
-	case term.KeyCtrlP:
-		switch ev.Key {
-		case term.KeyArrowUp, term.KeyArrowDown, term.KeyArrowLeft, term.KeyArrowRight:
-			c.moveTopWindow(ev)
-		default:
-			newKey = ev.Key
-		}
-	case term.KeyCtrlW:
-		switch ev.Key {
-		case term.KeyCtrlH:
-			c.moveActiveWindowToBottom()
-		case term.KeyCtrlM:
-			w := c.topWindow().(*Window)
-			if w.Sizable() && (w.TitleButtons()&ButtonMaximize == ButtonMaximize) {
-				maxxed := w.Maximized()
-				w.SetMaximized(!maxxed)
-				RefreshScreen()
+	key := keyPressFromEvent(ev)
+
+	if len(c.pendingKeys) != 0 {
+		if key == (KeyPress{Key: term.KeyEsc}) {
+			c.resetHotkey()
+			return
+		}
+
+		keys := append(copyKeys(c.pendingKeys), key)
+		if c.runHotkey(keys, ev) {
+			return
+		}
+
+		// not a hotkey: the keys that were held back go to the window
+		if !c.stickyKeys {
+			for _, k := range c.pendingKeys {
+				c.sendKey(Event{Type: EventKey, Key: k.Key, Ch: k.Ch, Mod: k.Mod})
 			}
-		case term.KeyCtrlC:
-			c.closeTopWindow()
-		default:
-			newKey = ev.Key
 		}
+		c.resetHotkey()
 	}
 
-	if newKey != term.KeyEsc {
-		event := Event{Key: c.lastKey, Type: EventKey}
-		c.sendEventToActiveWindow(event)
-		event.Key = newKey
-		c.sendEventToActiveWindow(event)
-		c.lastKey = term.KeyEsc
+	if c.runHotkey([]KeyPress{key}, ev) {
+		return
+	}
+
+	// Alt+hotkey of a menu bar item
+	if c.processMenuKey(ev) {
+		return
 	}
+
+	c.sendKey(ev)
 }
 
-func ProcessEvent(ev Event) {
+// ProcessEvent processes the event as if the App main loop has received
+// it from the backend
+func (a *App) ProcessEvent(ev Event) {
 	switch ev.Type {
 	case EventCloseWindow:
-		comp.closeTopWindow()
+		a.comp.closeTopWindow()
 	case EventRedraw:
-		RefreshScreen()
+		a.InvalidateScreen()
 	case EventResize:
-		SetScreenSize(ev.Width, ev.Height)
-		for _, c := range comp.windows {
+		a.SetScreenSize(ev.Width, ev.Height)
+		a.InvalidateScreen()
+		a.comp.closeMenus()
+		a.comp.closeDropDown(nil)
+		_, _, areaW, areaH := a.comp.workArea()
+		for _, c := range a.comp.windows {
 			wnd := c.(*Window)
 			if wnd.Maximized() {
-				wnd.SetSize(ev.Width, ev.Height)
+				wnd.SetSize(areaW, areaH)
 				wnd.ResizeChildren()
 				wnd.PlaceChildren()
-				RefreshScreen()
 			}
 
 			if wnd.onScreenResize != nil {
@@ -719,17 +921,53 @@ func ProcessEvent(ev Event) {
 			}
 
 		}
+	case EventInvoke:
+		a.loop.runInvoked()
+	case EventTimer:
+		a.timers.fire(ev.X)
 	case EventKey:
-		comp.processKey(ev)
+		a.comp.invalidateInputTargets(ev)
+		a.comp.processKey(ev)
+		a.comp.invalidateInputTargets(ev)
 	case EventMouse:
-		comp.processMouse(ev)
+		a.comp.invalidateInputTargets(ev)
+		a.comp.processMouse(ev)
+		a.comp.invalidateInputTargets(ev)
 	case EventLayout:
-		for _, c := range comp.windows {
+		for _, c := range a.comp.windows {
 			if c == ev.Target {
 				c.ResizeChildren()
 				c.PlaceChildren()
+				invalidateControl(c)
 				break
 			}
 		}
 	}
 }
+
+// ProcessEvent calls App.ProcessEvent of the default App
+func ProcessEvent(ev Event) {
+	defaultApp.ProcessEvent(ev)
+}
+
+// invalidateInputTargets marks as changed the windows that a key or mouse
+// event can change: the top window, the window under the mouse cursor, the
+// control that captured the input, and the status bar that displays the
+// hint of the active control. Menus, drop-down lists and tooltips are
+// displayed over windows, so while they are open the whole screen is
+// repainted
+func (c *Composer) invalidateInputTargets(ev Event) {
+	if c.dropDown != nil || len(c.menus) != 0 || c.tipText != "" {
+		c.app.InvalidateScreen()
+		return
+	}
+
+	invalidateControl(c.topWindow())
+	invalidateControl(c.consumer)
+	if ev.Type == EventMouse {
+		if wnd, _ := c.checkWindowUnderMouse(ev.X, ev.Y); wnd != nil {
+			invalidateControl(wnd)
+		}
+	}
+	c.invalidateStatusBar()
+}
@@ -49,6 +49,11 @@ type (
 	DragType  int
 	// ButtonShadow is a type of shadow that a Button drops
 	ButtonShadow int
+	// ColorMode is the number of colors the screen can display
+	ColorMode int
+	// HotkeyAction is a built-in Composer command that is run by a global
+	// hotkey, e.g, closing or moving the active Window
+	HotkeyAction int
 )
 
 const (
@@ -69,7 +74,9 @@ type Event struct {
 	// Event type - the first events are mapped to termbox Event and then a few
 	// own events added to the end
 	Type EventType
-	// Mod - is a key modifier. Only Alt modifier is supported
+	// Mod - is a key modifier. termbox supports only Alt modifier for keys.
+	// Mouse events may have ModMotion. Other backends may report ModShift
+	// and ModCtrl
 	Mod term.Modifier
 	// Msg is a text part of the event. Used by few events: e.g, ListBox click
 	// sends a value of clicked item
@@ -90,6 +97,15 @@ type Event struct {
 	Target Control
 }
 
+// Modifiers that termbox does not report, but other backends can
+const (
+	// ModShift is set in Event.Mod if Shift key is held
+	ModShift term.Modifier = 0x04
+	// ModCtrl is set in Event.Mod of arrow and other non-character keys if
+	// Ctrl key is held
+	ModCtrl term.Modifier = 0x08
+)
+
 // BorderStyle constants
 const (
 	BorderAuto BorderStyle = iota - 1
@@ -119,6 +135,57 @@ const (
 	ColorWhiteBold   = term.ColorWhite | term.AttrBold
 )
 
+// ColorMode constants
+const (
+	// ColorModeCurrent is used to get the current mode without changing it
+	ColorModeCurrent ColorMode = iota
+	// ColorModeNormal - 8 base colors, bright colors are emulated with bold
+	ColorModeNormal
+	// ColorMode256 - xterm 256-color palette
+	ColorMode256
+	// ColorModeRGB - 24-bit colors
+	ColorModeRGB
+)
+
+// HotkeyAction constants
+const (
+	// Close the application
+	HotkeyQuit HotkeyAction = iota
+	// Move the active Window to the bottom of window stack
+	HotkeyWindowHide
+	// Maximize or restore the active Window
+	HotkeyWindowMaximize
+	// Close the active Window
+	HotkeyWindowClose
+	// Move the active Window by one character
+	HotkeyWindowMoveUp
+	HotkeyWindowMoveDown
+	HotkeyWindowMoveLeft
+	HotkeyWindowMoveRight
+	// Change the active Window size by one character
+	HotkeyWindowShorter
+	HotkeyWindowTaller
+	HotkeyWindowNarrower
+	HotkeyWindowWider
+	// Activate the menu bar
+	HotkeyMenuBar
+	// Select the previous or the next page of the TabView that contains
+	// the active control
+	HotkeyTabPrev
+	HotkeyTabNext
+	// Select a page of the TabView that contains the active control by
+	// the page number
+	HotkeyTab1
+	HotkeyTab2
+	HotkeyTab3
+	HotkeyTab4
+	HotkeyTab5
+	HotkeyTab6
+	HotkeyTab7
+	HotkeyTab8
+	HotkeyTab9
+)
+
 // HitResult constants
 const (
 	HitOutside HitResult = iota
@@ -156,6 +223,12 @@ const (
 	AlignCenter
 )
 
+// TabView tab strip position
+const (
+	TabsTop = iota
+	TabsBottom
+)
+
 // Output direction
 // Used for Label text output direction and for Radio items distribution,
 // and for container controls
@@ -178,6 +251,8 @@ const (
 	ObjSparkChart   = "SparkChart"
 	ObjTableView    = "TableView"
 	ObjButton       = "Button"
+	ObjMenu         = "Menu"
+	ObjTreeView     = "TreeView"
 )
 
 // Available color identifiers that can be used in themes
@@ -199,6 +274,8 @@ const (
 	ColorEditActiveText = "EditActiveText"
 	ColorSelectionText  = "SelectionText"
 	ColorSelectionBack  = "SelectionBack"
+	ColorEditErrorBack  = "EditErrorBack"
+	ColorEditErrorText  = "EditErrorText"
 
 	// button control
 	ColorButtonBack         = "ButtonBack"
@@ -253,6 +330,28 @@ const (
 	ColorTableLineText       = "TableLineText"
 	ColorTableHeaderText     = "TableHeaderText"
 	ColorTableHeaderBack     = "TableHeaderBack"
+
+	// menu bar and menus
+	ColorMenuBack         = "MenuBack"
+	ColorMenuText         = "MenuText"
+	ColorMenuActiveBack   = "MenuActiveBack"
+	ColorMenuActiveText   = "MenuActiveText"
+	ColorMenuDisabledText = "MenuDisabledText"
+
+	// status bar
+	ColorStatusBack    = "StatusBack"
+	ColorStatusText    = "StatusText"
+	ColorStatusKeyText = "StatusKeyText"
+
+	// tooltip
+	ColorTooltipBack = "TooltipBack"
+	ColorTooltipText = "TooltipText"
+
+	// tab strip of TabView
+	ColorTabBack       = "TabBack"
+	ColorTabText       = "TabText"
+	ColorTabActiveBack = "TabActiveBack"
+	ColorTabActiveText = "TabActiveText"
 )
 
 // EventType is event that window or control may process
@@ -298,6 +397,29 @@ const (
 	// A scroll-able control's child has been activated, then notify its parent to handle
 	// the scrolling
 	EventActivateChild
+	// Run closures scheduled with Invoke on the main loop goroutine
+	EventInvoke
+	// A timer created with AfterFunc or Every has elapsed. X is the timer ID
+	EventTimer
+	// A user clicked the right mouse button over a control. X and Y are
+	// relative to the control that receives the event, Mod keeps modifiers
+	// reported by the terminal. If no control processes the event, the
+	// popup menu of the control or its closest parent is displayed
+	EventContextMenu
+	// A user clicked the middle mouse button over a control. X and Y are
+	// relative to the control that receives the event
+	EventMiddleClick
+	// A user clicked the left mouse button twice at the same place within
+	// the double click interval(see SetDoubleClickInterval). The event
+	// comes after the second button press. X and Y are relative to the
+	// control that receives the event
+	EventDoubleClick
+	// The mouse cursor has entered the control. X and Y are relative to
+	// the control. The event comes even if the control is inactive
+	EventMouseEnter
+	// The mouse cursor has left the control. The event comes even if the
+	// control is inactive
+	EventMouseLeave
 )
 
 // ConfirmationDialog and SelectDialog exit codes
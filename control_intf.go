@@ -31,6 +31,10 @@ type Control interface {
 	Active() bool
 	// SetActive activates and deactivates control
 	SetActive(active bool)
+	// Hint returns the text displayed in the status bar while a control
+	// is active
+	Hint() string
+	SetHint(hint string)
 	// TabStop returns if a control can be selected by traversing
 	// controls using TAB key
 	TabStop() bool
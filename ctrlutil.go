@@ -54,6 +54,41 @@ func ItemByThumbPosition(position, itemCount, length int) int {
 	return newPos
 }
 
+// WheelDelta returns the scroll direction of a mouse wheel event: dy is -1
+// for wheel up and 1 for wheel down. If Shift is held the event scrolls
+// horizontally, and dx is set instead of dy. Both values are zero if the
+// event is not a mouse wheel one
+func WheelDelta(ev Event) (dx, dy int) {
+	if ev.Type != EventMouse {
+		return 0, 0
+	}
+
+	switch ev.Key {
+	case term.MouseWheelUp:
+		dy = -1
+	case term.MouseWheelDown:
+		dy = 1
+	default:
+		return 0, 0
+	}
+
+	if ev.Mod&ModShift != 0 {
+		return dy, 0
+	}
+	return 0, dy
+}
+
+// clampScroll limits a scroll position to the interval 0..max
+func clampScroll(pos, max int) int {
+	if pos > max {
+		pos = max
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	return pos
+}
+
 // ChildAt returns the children of parent control that is at absolute
 // coordinates x, y. Returns nil if x, y are outside parent control and
 // returns parent if no child is at x, y
@@ -273,6 +308,11 @@ func NextControl(parent Control, curr Control, next bool) Control {
 // If it is not mouse click event then it looks for the first active child and
 // sends the event to it if it is not nil
 func SendEventToChild(parent Control, ev Event) bool {
+	// hover events are sent to the control under the mouse cursor only
+	if ev.Type == EventMouseEnter || ev.Type == EventMouseLeave {
+		return false
+	}
+
 	var child Control
 	if IsMouseClickEvent(ev) {
 		child = ChildAt(parent, ev.X, ev.Y)
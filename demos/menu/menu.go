@@ -0,0 +1,73 @@
+package main
+
+import (
+	ui "github.com/VladimirMarkelov/clui"
+)
+
+func createView() {
+	view := ui.AddWindow(0, 0, 40, 10, "Menu Demo")
+	view.SetPack(ui.Vertical)
+	lbl := ui.CreateLabel(view, ui.AutoSize, ui.AutoSize, "Press F10 or Alt+F, or right click here", 1)
+
+	report := func(ev ui.Event) {
+		lbl.SetTitle("Clicked: " + ev.Msg)
+	}
+
+	recent := ui.NewMenu()
+	recent.AddItem("&1 notes.txt", report)
+	recent.AddItem("&2 todo.txt", report)
+
+	file := ui.NewMenu()
+	file.AddItem("&Open", report).SetAccelerator("Ctrl+O")
+	file.AddItem("&Save", report).SetEnabled(false)
+	file.AddSubmenu("&Recent", recent)
+	file.AddSeparator()
+	file.AddItem("E&xit", func(ev ui.Event) {
+		ui.Stop()
+	})
+
+	view2 := ui.NewMenu()
+	wrap := view2.AddItem("&Word wrap", report)
+	wrap.SetCheckable(true)
+	wrap.SetChecked(true)
+	view2.AddItem("&Line numbers", report).SetCheckable(true)
+
+	edit := ui.NewMenu()
+	edit.AddItem("&Copy", report)
+	edit.AddItem("&Paste", report)
+
+	bar := ui.NewMenuBar()
+	bar.AddMenu("&File", file)
+	bar.AddMenu("&View", view2)
+	bar.AddMenu("&Help", nil).OnClick(func(ev ui.Event) {
+		lbl.SetTitle("No help yet")
+	})
+	ui.SetMenuBar(bar)
+
+	status := ui.NewStatusBar()
+	status.SetText("Ready")
+	status.AddKey("F10", "Menu", nil)
+	status.AddKey("Ctrl+X", "Exit", func(ev ui.Event) {
+		ui.Stop()
+	})
+	ui.SetStatusBar(status)
+	view.SetHint("Right click to open the popup menu")
+
+	view.SetPopupMenu(edit)
+}
+
+func mainLoop() {
+	// Every application must create a single Composer and
+	// call its intialize method
+	ui.InitLibrary()
+	defer ui.DeinitLibrary()
+
+	createView()
+
+	// start event processing loop - the main core of the library
+	ui.MainLoop()
+}
+
+func main() {
+	mainLoop()
+}
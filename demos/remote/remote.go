@@ -0,0 +1,59 @@
+// Demo serves a separate clui session to every TCP connection. A raw TCP
+// connection does not send terminal size, so every session is 80x24.
+// Connect from a terminal in raw mode:
+//
+//	stty raw -echo; nc localhost 7000; stty sane
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+
+	ui "github.com/VladimirMarkelov/clui"
+)
+
+func createView(app *ui.App, id int) {
+	view := app.AddWindow(0, 0, 30, 7, fmt.Sprintf("Session %v", id))
+	view.SetPack(ui.Vertical)
+
+	edit := ui.CreateEditField(view, 20, "", ui.Fixed)
+	lbl := ui.CreateLabel(view, ui.AutoSize, 1, "Type something", ui.Fixed)
+	edit.OnChange(func(ev ui.Event) {
+		lbl.SetTitle(edit.Title())
+	})
+
+	btnQuit := ui.CreateButton(view, ui.AutoSize, 4, "Quit", ui.Fixed)
+	btnQuit.OnClick(func(ev ui.Event) {
+		// the package functions work with the default App, a session
+		// uses its own one
+		app.Stop()
+	})
+	ui.ActivateControl(view, edit)
+}
+
+func main() {
+	ln, err := net.Listen("tcp", "localhost:7000")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for id := 1; ; id++ {
+		conn, err := ln.Accept()
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		go func(conn net.Conn, id int) {
+			defer conn.Close()
+			size := ui.TerminalSize{Width: 80, Height: 24}
+			err := ui.RunStream(context.Background(), conn, size, nil, func(app *ui.App) {
+				createView(app, id)
+			})
+			if err != nil {
+				log.Printf("Session %v: %v", id, err)
+			}
+		}(conn, id)
+	}
+}
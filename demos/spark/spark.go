@@ -60,16 +60,9 @@ func mainLoop() {
 	b := createView()
 	b.SetData([]float64{1, 2, 3, 4, 5, 6, 6, 7, 5, 8, 9})
 
-	ticker := time.NewTicker(time.Millisecond * 200).C
-	go func() {
-		for {
-			select {
-			case <-ticker:
-				b.AddData(float64(rand.Int31n(20)))
-				ui.PutEvent(ui.Event{Type: ui.EventRedraw})
-			}
-		}
-	}()
+	ui.Every(time.Millisecond*200, func() {
+		b.AddData(float64(rand.Int31n(20)))
+	})
 
 	// start event processing loop - the main core of the library
 	ui.MainLoop()
@@ -37,8 +37,13 @@ type SelectDialog struct {
 // title is a dialog title
 // message is a text inside dialog for user to be notified of a fact
 // button is a title for button inside dialog.
+func (a *App) CreateAlertDialog(title, message string, button string) *ConfirmationDialog {
+	return a.CreateConfirmationDialog(title, message, []string{button}, 0)
+}
+
+// CreateAlertDialog calls App.CreateAlertDialog of the default App
 func CreateAlertDialog(title, message string, button string) *ConfirmationDialog {
-	return CreateConfirmationDialog(title, message, []string{button}, 0)
+	return defaultApp.CreateAlertDialog(title, message, button)
 }
 
 // CreateConfirmationDialog creates new confirmation dialog.
@@ -51,18 +56,18 @@ func CreateAlertDialog(title, message string, button string) *ConfirmationDialog
 // defaultButton is the number of button that is active right after
 //  dialog is created. If the number is greater than the number of
 //  buttons, no button is active
-func CreateConfirmationDialog(title, question string, buttons []string, defaultButton int) *ConfirmationDialog {
+func (a *App) CreateConfirmationDialog(title, question string, buttons []string, defaultButton int) *ConfirmationDialog {
 	dlg := new(ConfirmationDialog)
 
 	if len(buttons) == 0 {
 		buttons = []string{"OK"}
 	}
 
-	cw, ch := term.Size()
+	cw, ch := a.ScreenSize()
 
-	dlg.View = AddWindow(cw/2-12, ch/2-8, 30, 3, title)
-	WindowManager().BeginUpdate()
-	defer WindowManager().EndUpdate()
+	dlg.View = a.AddWindow(cw/2-12, ch/2-8, 30, 3, title)
+	a.comp.BeginUpdate()
+	defer a.comp.EndUpdate()
 	dlg.View.SetConstraints(30, 3)
 	dlg.View.SetModal(true)
 	dlg.View.SetPack(Vertical)
@@ -83,10 +88,10 @@ func CreateConfirmationDialog(title, question string, buttons []string, defaultB
 	btn1.OnClick(func(ev Event) {
 		dlg.result = DialogButton1
 
-		WindowManager().DestroyWindow(dlg.View)
-		WindowManager().BeginUpdate()
+		a.comp.DestroyWindow(dlg.View)
+		a.comp.BeginUpdate()
 		closeFunc := dlg.onClose
-		WindowManager().EndUpdate()
+		a.comp.EndUpdate()
 		if closeFunc != nil {
 			closeFunc()
 		}
@@ -98,7 +103,7 @@ func CreateConfirmationDialog(title, question string, buttons []string, defaultB
 		btn2 = CreateButton(frm1, AutoSize, AutoSize, buttons[1], Fixed)
 		btn2.OnClick(func(ev Event) {
 			dlg.result = DialogButton2
-			WindowManager().DestroyWindow(dlg.View)
+			a.comp.DestroyWindow(dlg.View)
 			if dlg.onClose != nil {
 				dlg.onClose()
 			}
@@ -109,7 +114,7 @@ func CreateConfirmationDialog(title, question string, buttons []string, defaultB
 		btn3 = CreateButton(frm1, AutoSize, AutoSize, buttons[2], Fixed)
 		btn3.OnClick(func(ev Event) {
 			dlg.result = DialogButton3
-			WindowManager().DestroyWindow(dlg.View)
+			a.comp.DestroyWindow(dlg.View)
 			if dlg.onClose != nil {
 				dlg.onClose()
 			}
@@ -130,7 +135,7 @@ func CreateConfirmationDialog(title, question string, buttons []string, defaultB
 		if dlg.result == DialogAlive {
 			dlg.result = DialogClosed
 			if ev.X != 1 {
-				WindowManager().DestroyWindow(dlg.View)
+				a.comp.DestroyWindow(dlg.View)
 			}
 			if dlg.onClose != nil {
 				dlg.onClose()
@@ -142,11 +147,17 @@ func CreateConfirmationDialog(title, question string, buttons []string, defaultB
 	return dlg
 }
 
+// CreateConfirmationDialog calls App.CreateConfirmationDialog of the default App
+func CreateConfirmationDialog(title, question string, buttons []string, defaultButton int) *ConfirmationDialog {
+	return defaultApp.CreateConfirmationDialog(title, question, buttons, defaultButton)
+}
+
 // OnClose sets the callback that is called when the
 // dialog is closed
 func (d *ConfirmationDialog) OnClose(fn func()) {
-	WindowManager().BeginUpdate()
-	defer WindowManager().EndUpdate()
+	a := AppOf(d.View)
+	a.comp.BeginUpdate()
+	defer a.comp.EndUpdate()
 	d.onClose = fn
 }
 
@@ -160,8 +171,13 @@ func (d *ConfirmationDialog) Result() int {
 
 // ------------------------ Selection Dialog ---------------------
 
+func (a *App) CreateEditDialog(title, message, initialText string) *SelectDialog {
+	return a.CreateSelectDialog(title, []string{message, initialText}, 0, SelectDialogEdit)
+}
+
+// CreateEditDialog calls App.CreateEditDialog of the default App
 func CreateEditDialog(title, message, initialText string) *SelectDialog {
-	return CreateSelectDialog(title, []string{message, initialText}, 0, SelectDialogEdit)
+	return defaultApp.CreateEditDialog(title, message, initialText)
 }
 
 // NewSelectDialog creates new dialog to select an item from list.
@@ -172,7 +188,7 @@ func CreateEditDialog(title, message, initialText string) *SelectDialog {
 //  the dialog is created
 // typ is a selection type: ListBox or RadioGroup
 // Returns nil in case of creation process fails, e.g, if item list is empty
-func CreateSelectDialog(title string, items []string, selectedItem int, typ SelectDialogType) *SelectDialog {
+func (a *App) CreateSelectDialog(title string, items []string, selectedItem int, typ SelectDialogType) *SelectDialog {
 	dlg := new(SelectDialog)
 
 	if len(items) == 0 {
@@ -180,12 +196,12 @@ func CreateSelectDialog(title string, items []string, selectedItem int, typ Sele
 		return nil
 	}
 
-	cw, ch := term.Size()
+	cw, ch := a.ScreenSize()
 
 	dlg.typ = typ
-	dlg.View = AddWindow(cw/2-12, ch/2-8, 20, 10, title)
-	WindowManager().BeginUpdate()
-	defer WindowManager().EndUpdate()
+	dlg.View = a.AddWindow(cw/2-12, ch/2-8, 20, 10, title)
+	a.comp.BeginUpdate()
+	defer a.comp.EndUpdate()
 	dlg.View.SetModal(true)
 	dlg.View.SetPack(Vertical)
 
@@ -212,12 +228,15 @@ func CreateSelectDialog(title string, items []string, selectedItem int, typ Sele
 		dlg.edit.OnKeyPress(func(key term.Key, r rune) bool {
 			var input string
 			if key == term.KeyEnter {
+				if !dlg.View.Validate() {
+					return true
+				}
 				input = dlg.edit.Title()
 				dlg.edtResult = input
 				dlg.value = -1
 				dlg.result = DialogButton1
 
-				WindowManager().DestroyWindow(dlg.View)
+				a.comp.DestroyWindow(dlg.View)
 				if dlg.onClose != nil {
 					dlg.onClose()
 				}
@@ -244,6 +263,9 @@ func CreateSelectDialog(title string, items []string, selectedItem int, typ Sele
 	CreateFrame(frm1, 1, 1, BorderNone, 1)
 	btn1 := CreateButton(frm1, AutoSize, AutoSize, "OK", Fixed)
 	btn1.OnClick(func(ev Event) {
+		if !dlg.View.Validate() {
+			return
+		}
 		dlg.result = DialogButton1
 		if dlg.typ == SelectDialogList {
 			dlg.value = dlg.list.SelectedItem()
@@ -253,7 +275,7 @@ func CreateSelectDialog(title string, items []string, selectedItem int, typ Sele
 		} else {
 			dlg.value = dlg.rg.Selected()
 		}
-		WindowManager().DestroyWindow(dlg.View)
+		a.comp.DestroyWindow(dlg.View)
 		if dlg.onClose != nil {
 			dlg.onClose()
 		}
@@ -265,7 +287,7 @@ func CreateSelectDialog(title string, items []string, selectedItem int, typ Sele
 		dlg.result = DialogButton2
 		dlg.edtResult = ""
 		dlg.value = -1
-		WindowManager().DestroyWindow(dlg.View)
+		a.comp.DestroyWindow(dlg.View)
 		if dlg.onClose != nil {
 			dlg.onClose()
 		}
@@ -282,7 +304,7 @@ func CreateSelectDialog(title string, items []string, selectedItem int, typ Sele
 		if dlg.result == DialogAlive {
 			dlg.result = DialogClosed
 			if ev.X != 1 {
-				WindowManager().DestroyWindow(dlg.View)
+				a.comp.DestroyWindow(dlg.View)
 			}
 			if dlg.onClose != nil {
 				dlg.onClose()
@@ -295,11 +317,17 @@ func CreateSelectDialog(title string, items []string, selectedItem int, typ Sele
 	return dlg
 }
 
+// CreateSelectDialog calls App.CreateSelectDialog of the default App
+func CreateSelectDialog(title string, items []string, selectedItem int, typ SelectDialogType) *SelectDialog {
+	return defaultApp.CreateSelectDialog(title, items, selectedItem, typ)
+}
+
 // OnClose sets the callback that is called when the
 // dialog is closed
 func (d *SelectDialog) OnClose(fn func()) {
-	WindowManager().BeginUpdate()
-	defer WindowManager().EndUpdate()
+	a := AppOf(d.View)
+	a.comp.BeginUpdate()
+	defer a.comp.EndUpdate()
 	d.onClose = fn
 }
 
@@ -321,3 +349,11 @@ func (d *SelectDialog) Value() int {
 func (d *SelectDialog) EditResult() string {
 	return d.edtResult
 }
+
+// EditField returns the edit field of the dialog created with
+// CreateEditDialog, e.g to set a validator or an input mask. The dialog
+// is not closed with OK button while the field is invalid. Returns nil
+// for other dialog types
+func (d *SelectDialog) EditField() *EditField {
+	return d.edit
+}
@@ -0,0 +1,163 @@
+package clui
+
+import (
+	term "github.com/nsf/termbox-go"
+)
+
+// dropDown is a list that Composer displays over windows right under
+// its owner control, e.g the list of a ComboBox. Only one drop-down list
+// can be open at a time. The owner keeps the keyboard focus and moves
+// the list selection itself, Composer draws the list and handles mouse
+// clicks on it
+type dropDown struct {
+	owner Control
+	list  *ListBox
+	// onClick is called after a user clicks a list item
+	onClick func()
+	// onClose is called after the list is closed
+	onClose func()
+}
+
+// newDropDownList creates a ListBox that is not attached to any parent
+// and can be displayed by Composer as a drop-down list
+func newDropDownList() *ListBox {
+	l := CreateListBox(nil, 1, 1, Fixed)
+	l.SetConstraints(1, 1)
+	return l
+}
+
+// openDropDown displays the list under its owner(or above it if there is
+// not enough space below). height is the maximum number of visible items
+func (c *Composer) openDropDown(d *dropDown, height int) {
+	if c.dropDown != nil && c.dropDown != d {
+		c.closeDropDown(nil)
+	}
+	d.list.app = c.app
+
+	cnt := d.list.ItemCount()
+	if height <= 0 || height > cnt {
+		height = cnt
+	}
+	if height == 0 {
+		c.closeDropDown(d.owner)
+		return
+	}
+
+	x, y := d.owner.Pos()
+	w, h := d.owner.Size()
+	_, areaY, _, areaH := c.workArea()
+	bottom := areaY + areaH
+
+	top := y + h
+	if top+height > bottom {
+		if y-height >= areaY {
+			top = y - height
+		} else if bottom-top >= y-areaY {
+			height = bottom - top
+		} else {
+			height = y - areaY
+			top = areaY
+		}
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	d.list.SetSize(w, height)
+	d.list.SetPos(x, top)
+	d.list.EnsureVisible()
+	c.dropDown = d
+}
+
+// closeDropDown closes the drop-down list if it belongs to owner. nil
+// owner closes any drop-down list
+func (c *Composer) closeDropDown(owner Control) {
+	d := c.dropDown
+	if d == nil || (owner != nil && d.owner != owner) {
+		return
+	}
+
+	c.dropDown = nil
+	if d.onClose != nil {
+		d.onClose()
+	}
+}
+
+// dropDownOpen returns true if the owner drop-down list is displayed
+func (c *Composer) dropDownOpen(owner Control) bool {
+	return c.dropDown != nil && c.dropDown.owner == owner
+}
+
+func (c *Composer) drawDropDown() {
+	d := c.dropDown
+	if d == nil {
+		return
+	}
+
+	// the owner lost focus or its window is not on top any more
+	wnd := d.owner
+	for wnd.Parent() != nil {
+		wnd = wnd.Parent()
+	}
+	if !d.owner.Active() || !d.owner.Visible() || wnd != c.topWindow() {
+		c.closeDropDown(nil)
+		return
+	}
+
+	c.app.PushClip()
+	defer c.app.PopClip()
+	sw, sh := c.app.ScreenSize()
+	c.app.SetClipRect(0, 0, sw, sh)
+
+	d.list.Draw()
+}
+
+// processDropDownMouse handles mouse clicks on the drop-down list. A click
+// outside the list closes it. Returns true if the event must not be
+// processed further
+func (c *Composer) processDropDownMouse(ev Event) bool {
+	if ev.Key == term.MouseRelease && c.eatRelease {
+		c.eatRelease = false
+		return true
+	}
+
+	d := c.dropDown
+	if d == nil || ev.Mod == term.ModMotion {
+		return false
+	}
+
+	lx, ly := d.list.Pos()
+	lw, lh := d.list.Size()
+	inside := ev.X >= lx && ev.X < lx+lw && ev.Y >= ly && ev.Y < ly+lh
+	if !inside {
+		if ev.Key != term.MouseLeft && ev.Key != term.MouseRight && ev.Key != term.MouseMiddle {
+			return false
+		}
+
+		c.closeDropDown(nil)
+		c.app.InvalidateScreen()
+		// a click on the owner just closes the list
+		ox, oy := d.owner.Pos()
+		ow, oh := d.owner.Size()
+		if ev.X >= ox && ev.X < ox+ow && ev.Y >= oy && ev.Y < oy+oh {
+			c.eatRelease = ev.Key == term.MouseLeft
+			return true
+		}
+		return false
+	}
+
+	if dx, dy := WheelDelta(ev); dx != 0 || dy != 0 {
+		d.list.ProcessEvent(ev)
+		c.app.InvalidateScreen()
+	} else if ev.Key == term.MouseLeft {
+		c.eatRelease = true
+		d.list.ProcessEvent(ev)
+		// the last column is the list scrollbar
+		if ev.X < lx+lw-1 && d.onClick != nil {
+			d.onClick()
+		}
+		c.app.InvalidateScreen()
+	}
+
+	return true
+}
@@ -6,6 +6,276 @@ import (
 	"strings"
 )
 
+/*
+EditField is a single-line text edit contol. Edit field consumes some keyboard
+events when it is active: all printable charaters; Delete, BackSpace, Home,
+End, left and right arrows; Ctrl+R to clear EditField(if it does not have
+a history).
+Edit text can be limited. By default a user can enter text of any length.
+Use SetMaxWidth to limit the maximum text length. If the text is longer than
+maximun then the text is automatically truncated.
+EditField calls onChage in case of its text is changed. Event field Msg contains the new text
+
+Text editing keys:
+  Ctrl+B, Ctrl+F (Alt+Left, Alt+Right, Ctrl+Left, Ctrl+Right) - move the
+        cursor to the previous or next word
+  Shift+Left, Shift+Right, Shift+Home, Shift+End - select text. termbox
+        does not report Shift key, so there is an alternative way:
+  Ctrl+Space - start(or stop) selecting text with arrows, Home and End
+  Ctrl+A - select the whole text
+  Esc - clear the selection
+  Ctrl+K (Alt+Backspace) - delete the word before the cursor
+  Ctrl+U - delete the text before the cursor
+  Ctrl+C, Ctrl+X - copy and cut the selected text
+  Ctrl+V - paste text at the cursor position replacing the selection
+  Ctrl+Z, Ctrl+Y - undo and redo changes
+
+A validator(see SetValidator) and an input mask(see SetMask) restrict
+the text. An invalid EditField is displayed with EditErrorText and
+EditErrorBack colors after a user changes its text, and its hint displayed
+in the status bar is the error message. Window.Validate checks all fields
+of a window.
+
+A completer(see SetCompleter) displays a list of candidates under the
+EditField while a user types, e.g file names(see PathCompleter).
+
+A history(see SetHistory) keeps the texts entered by a user: up and down
+arrows browse it, Ctrl+R(see SetHistorySearchKey) searches it.
+
+Note: termbox does not report Alt and Ctrl modifiers of other keys, so
+the keys in parentheses work only with backends that report them, e.g
+StreamBackend. With termbox, use Ctrl+B, Ctrl+F and Ctrl+K
+*/
+type EditField struct {
+	BaseControl
+	// cursor position in edit text
+	cursorPos int
+	// the number of the first displayed text character - it is used in case of text is longer than edit width
+	offset    int
+	readonly  bool
+	maxWidth  int
+	showStars bool
+	// the selected text is between selStart and the cursor. selStart is -1
+	// if nothing is selected. marking is true if the cursor movement
+	// extends the selection(after Ctrl+Space)
+	selStart int
+	marking  bool
+	// undo and redo history
+	undo, redo []editFieldState
+	lastEdit   editKind
+	platform   editPlatform
+	// input mask and validator. touched is true after a user changes the
+	// text or the text is validated with Validate
+	mask      []maskPos
+	maskDesc  string
+	validator func(string) error
+	touched   bool
+	// completion candidates(see SetCompleter)
+	completer func(string) []string
+	compList  *ListBox
+	compDrop  *dropDown
+	// history(see SetHistory). histBack is the number of entries the
+	// user went back, 0 means the text is not from the history
+	history      *History
+	histBack     int
+	histDraft    string
+	searchKey    term.Key
+	searching    bool
+	searchQuery  string
+	searchPos    int
+	searchFailed bool
+
+	onChange   func(Event)
+	onKeyPress func(term.Key, rune) bool
+}
+
+// editFieldState is a snapshot of EditField text for undo and redo
+type editFieldState struct {
+	text      string
+	cursorPos int
+}
+
+// NewEditField creates a new EditField control
+// view - is a View that manages the control
+// parent - is container that keeps the control. The same View can be a view and a parent at the same time.
+// width - is minimal width of the control.
+// text - text to edit.
+// scale - the way of scaling the control when the parent is resized. Use DoNotScale constant if the
+//  control should keep its original size.
+func CreateEditField(parent Control, width int, text string, scale int) *EditField {
+	e := new(EditField)
+	e.BaseControl = NewBaseControl()
+	e.onChange = nil
+	e.selStart = -1
+	e.searchKey = term.KeyCtrlR
+	e.SetTitle(text)
+	e.SetEnabled(true)
+
+	if width == AutoSize {
+		width = TextWidth(text) + 1
+	}
+
+	e.SetSize(width, 1)
+	e.cursorPos = xs.Len(text)
+	e.offset = 0
+	e.parent = parent
+	e.readonly = false
+	e.SetScale(scale)
+
+	e.SetConstraints(width, 1)
+
+	e.end()
+	e.platform.init()
+
+	if parent != nil {
+		parent.AddChild(e)
+	}
+
+	return e
+}
+
+/*
+ProcessEvent processes all events come from the control parent. If a control
+processes an event it should return true. If the method returns false it means
+that the control do not want or cannot process the event and the caller sends
+the event to the control parent
+*/
+func (e *EditField) ProcessEvent(event Event) bool {
+	if !e.Active() || !e.Enabled() {
+		return false
+	}
+
+	a := AppOf(e)
+	if event.Type == EventActivate && event.X == 0 {
+		a.HideCursor()
+	}
+
+	if event.Type == EventMouse && event.Key == term.MouseLeft {
+		e.platform.mouseClicked()
+	}
+
+	if event.Type == EventKey && e.processCompletionKey(event) {
+		return true
+	}
+
+	if event.Type == EventKey && e.searching && e.processSearchKey(event) {
+		return true
+	}
+
+	if event.Type == EventKey && event.Key == term.KeyEnter {
+		e.addToHistory()
+	}
+
+	if event.Type == EventKey && event.Key != term.KeyTab {
+		if e.onKeyPress != nil {
+			res := e.onKeyPress(event.Key, event.Ch)
+			if res {
+				return true
+			}
+		}
+
+		old := e.title
+		res := e.processKey(event)
+		if e.title != old {
+			e.updateCompletion()
+		}
+		return res
+	}
+
+	return false
+}
+
+func (e *EditField) processKey(event Event) bool {
+	extend := e.marking || event.Mod&ModShift != 0
+
+	if event.Mod&(term.ModAlt|ModCtrl) != 0 {
+		switch event.Key {
+		case term.KeyArrowLeft:
+			e.moveCursor(prevWordStart([]rune(e.title), e.cursorPos), extend)
+			return true
+		case term.KeyArrowRight:
+			e.moveCursor(nextWordStart([]rune(e.title), e.cursorPos), extend)
+			return true
+		case term.KeyBackspace, term.KeyBackspace2:
+			e.deleteWord()
+			return true
+		}
+	}
+
+	if e.history != nil && e.searchKey != 0 && event.Key == e.searchKey && event.Ch == 0 {
+		e.startSearch()
+		return true
+	}
+
+	if event.Ch != 0 {
+		if e.platform.acceptChar() {
+			e.insertRune(event.Ch)
+		}
+		return true
+	}
+
+	switch event.Key {
+	case term.KeyEnter:
+		return false
+	case term.KeySpace:
+		e.insertRune(' ')
+	case term.KeyBackspace, term.KeyBackspace2:
+		e.backspace()
+	case term.KeyDelete:
+		e.del()
+	case term.KeyArrowLeft:
+		e.charLeft(extend)
+	case term.KeyArrowRight:
+		e.charRight(extend)
+	case term.KeyArrowUp:
+		return e.browseHistory(-1)
+	case term.KeyArrowDown:
+		return e.browseHistory(1)
+	case term.KeyHome:
+		e.moveCursor(0, extend)
+	case term.KeyEnd:
+		e.moveCursor(xs.Len(e.title), extend)
+	case term.KeyCtrlR:
+		if e.title != "" && e.beginEdit(editOther) {
+			e.deleteRange(0, xs.Len(e.title))
+		}
+	case term.KeyCtrlA:
+		e.SelectAll()
+	case term.KeyCtrlC:
+		e.Copy()
+	case term.KeyCtrlX:
+		e.Cut()
+	case term.KeyCtrlV:
+		e.Paste()
+	case term.KeyCtrlB:
+		e.moveCursor(prevWordStart([]rune(e.title), e.cursorPos), extend)
+	case term.KeyCtrlF:
+		e.moveCursor(nextWordStart([]rune(e.title), e.cursorPos), extend)
+	case term.KeyCtrlK:
+		e.deleteWord()
+	case term.KeyCtrlU:
+		e.deleteToStart()
+	case term.KeyCtrlZ:
+		e.Undo()
+	case term.KeyCtrlY:
+		e.Redo()
+	case term.KeyCtrlSpace:
+		e.marking = !e.marking
+		if e.marking {
+			e.selStart = -1
+		}
+	case term.KeyEsc:
+		if _, _, ok := e.selection(); !ok && !e.marking {
+			return false
+		}
+		e.selStart, e.marking = -1, false
+	default:
+		return false
+	}
+
+	return true
+}
+
 // OnChange sets the callback that is called when EditField content is changed
 func (e *EditField) OnChange(fn func(Event)) {
 	e.onChange = fn
@@ -19,11 +289,22 @@ func (e *EditField) OnKeyPress(fn func(term.Key, rune) bool) {
 	e.onKeyPress = fn
 }
 
-// SetTitle changes the EditField content and emits OnChage eventif the new value does not equal to old one
+// SetTitle changes the EditField content and emits OnChage eventif the new value does not equal to old one.
+// The selection and undo history are cleared
 func (e *EditField) SetTitle(title string) {
+	e.Invalidate()
+	if e.mask != nil {
+		title = string(applyMask(e.mask, title))
+	}
 	e.setTitleInternal(title)
+	e.undo, e.redo = nil, nil
+	e.lastEdit = editNone
+	e.touched = false
 	e.offset = 0
 	e.end()
+	if e.mask != nil {
+		e.moveCursor(e.firstBlank(), false)
+	}
 }
 
 func (e *EditField) setTitleInternal(title string) {
@@ -47,183 +328,468 @@ func (e *EditField) Draw() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(e)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	x, y := e.Pos()
 	w, _ := e.Size()
 
-	parts := []rune(SysObject(ObjEdit))
+	parts := []rune(a.SysObject(ObjEdit))
 	chLeft, chRight := string(parts[0]), string(parts[1])
-	chStar := "*"
+	chStar := '*'
 	if len(parts) > 3 {
-		chStar = string(parts[3])
+		chStar = parts[3]
 	}
 
-	var textOut string
-	curOff := 0
-	if e.offset == 0 && xs.Len(e.title) < e.width {
-		if e.showStars {
-			textOut = strings.Repeat(chStar, xs.Len(e.title))
-		} else {
-			textOut = e.title
-		}
-	} else {
-		fromIdx := 0
-		toIdx := 0
-		if e.offset == 0 {
-			toIdx = e.width - 1
-			if e.showStars {
-				textOut = strings.Repeat(chStar, toIdx) + chRight
-			} else {
-				textOut = xs.Slice(e.title, 0, toIdx) + chRight
-			}
-			curOff = -e.offset
-		} else {
-			curOff = 1 - e.offset
-			fromIdx = e.offset
-			if e.width-1 <= xs.Len(e.title)-e.offset {
-				toIdx = e.offset + e.width - 2
-				if e.showStars {
-					textOut = chLeft + strings.Repeat(chStar, toIdx-fromIdx) + chRight
-				} else {
-					textOut = chLeft + xs.Slice(e.title, fromIdx, toIdx) + chRight
-				}
-			} else {
-				if e.showStars {
-					textOut = chLeft + strings.Repeat(chStar, xs.Len(e.title)-fromIdx)
-				} else {
-					textOut = chLeft + xs.Slice(e.title, fromIdx, -1)
-				}
-			}
+	text := e.displayText(chStar)
+	widths := runeWidths(text)
+
+	// if the text is longer than the field, the first and the last columns
+	// display arrows instead of hidden text. Text positions are rune
+	// indices, while the screen columns depend on the character widths
+	from := e.offset
+	if from > len(text) {
+		from = len(text)
+	}
+	shift := 0
+	if from > 0 {
+		shift = 1
+	}
+	to := len(text)
+	fromCol := columnOf(widths, from)
+	moreRight := columnOf(widths, to)-fromCol > w-shift
+	if moreRight {
+		to = clusterAtColumn(widths, fromCol+w-shift-1)
+		if columnOf(widths, to)-fromCol > w-shift-1 {
+			to = prevCluster(text, to)
 		}
 	}
 
-	fg, bg := RealColor(e.fg, e.Style(), ColorEditText), RealColor(e.bg, e.Style(), ColorEditBack)
+	fg, bg := a.RealColor(e.fg, e.Style(), ColorEditText), a.RealColor(e.bg, e.Style(), ColorEditBack)
 	if !e.Enabled() {
-		fg, bg = RealColor(e.fg, e.Style(), ColorDisabledText), RealColor(e.fg, e.Style(), ColorDisabledBack)
+		fg, bg = a.RealColor(e.fg, e.Style(), ColorDisabledText), a.RealColor(e.fg, e.Style(), ColorDisabledBack)
 	} else if e.Active() {
-		fg, bg = RealColor(e.fg, e.Style(), ColorEditActiveText), RealColor(e.bg, e.Style(), ColorEditActiveBack)
+		fg, bg = a.RealColor(e.fg, e.Style(), ColorEditActiveText), a.RealColor(e.bg, e.Style(), ColorEditActiveBack)
+	}
+	if e.Enabled() && e.touched && e.validate() != nil {
+		fg, bg = a.RealColor(e.fg, e.Style(), ColorEditErrorText), a.RealColor(e.bg, e.Style(), ColorEditErrorBack)
+	}
+	fgSel, bgSel := a.RealColor(e.fgActive, e.Style(), ColorSelectionText), a.RealColor(e.bgActive, e.Style(), ColorSelectionBack)
+	selFrom, selTo, _ := e.selection()
+
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(x, y, w, 1, ' ')
+	if shift > 0 {
+		a.DrawRawText(x, y, chLeft)
+	}
+	if moreRight {
+		a.DrawRawText(x+w-1, y, chRight)
+	}
+	col := x + shift
+	for idx := from; idx < to; idx++ {
+		if widths[idx] == 0 {
+			continue
+		}
+		if idx >= selFrom && idx < selTo {
+			a.SetTextColor(fgSel)
+			a.SetBackColor(bgSel)
+		} else {
+			a.SetTextColor(fg)
+			a.SetBackColor(bg)
+		}
+		a.PutChar(col, y, text[idx])
+		col += widths[idx]
 	}
 
-	SetTextColor(fg)
-	SetBackColor(bg)
-	FillRect(x, y, w, 1, ' ')
-	DrawRawText(x, y, textOut)
 	if e.Active() {
-		SetCursorPos(e.cursorPos+e.x+curOff, e.y)
+		a.SetCursorPos(x+shift+columnOf(widths, e.cursorPos)-fromCol, y)
 	}
 }
 
-func (e *EditField) insertRune(ch rune) {
-	if e.readonly {
+// displayText returns the text as it is displayed: password characters
+// are replaced with stars, and empty mask positions with placeholders
+func (e *EditField) displayText(chStar rune) []rune {
+	text := []rune(e.title)
+	if e.showStars {
+		text = []rune(strings.Repeat(string(chStar), len(text)))
+	} else if e.mask != nil && len(text) == len(e.mask) {
+		for i, m := range e.mask {
+			if !m.literal && text[i] == ' ' {
+				text[i] = maskBlankChar
+			}
+		}
+	}
+	return text
+}
+
+// scrollToCursor changes the first displayed character to make the cursor
+// visible. The layout must be the same as in Draw
+func (e *EditField) scrollToCursor() {
+	widths := runeWidths(e.displayText('*'))
+	length := columnOf(widths, len(widths))
+	if length < e.width || e.width < 3 {
+		e.offset = 0
 		return
 	}
 
-	if e.maxWidth > 0 && xs.Len(e.title) >= e.maxWidth {
+	pos := columnOf(widths, e.cursorPos)
+	offset := columnOf(widths, e.offset)
+	if offset == 0 && pos <= e.width-2 {
 		return
 	}
 
-	idx := e.cursorPos
+	// the first column displays an arrow, and the last one displays
+	// an arrow if there is more text after the last displayed character
+	if pos < offset {
+		offset = pos
+	}
+	maxShift := e.width - 3
+	if e.cursorPos >= len(widths) {
+		maxShift = e.width - 2
+	}
+	if pos-offset > maxShift {
+		offset = pos - maxShift
+	}
+	if offset > length-e.width+2 {
+		offset = length - e.width + 2
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	// the first displayed character must not be cut
+	e.offset = clusterAtColumn(widths, offset)
+}
 
-	if idx == 0 {
-		e.setTitleInternal(string(ch) + e.title)
-	} else if idx >= xs.Len(e.title) {
-		e.setTitleInternal(e.title + string(ch))
-	} else {
-		e.setTitleInternal(xs.Slice(e.title, 0, idx) + string(ch) + xs.Slice(e.title, idx, -1))
+// selection returns the beginning and the end of the selected text. ok is
+// false if nothing is selected
+func (e *EditField) selection() (start int, end int, ok bool) {
+	if e.selStart == -1 || e.selStart == e.cursorPos {
+		return e.cursorPos, e.cursorPos, false
 	}
+	if e.selStart < e.cursorPos {
+		return e.selStart, e.cursorPos, true
+	}
+	return e.cursorPos, e.selStart, true
+}
 
-	e.cursorPos++
+// SelectedText returns the selected text or empty string if nothing
+// is selected
+func (e *EditField) SelectedText() string {
+	start, end, ok := e.selection()
+	if !ok {
+		return ""
+	}
+	return xs.Slice(e.title, start, end)
+}
 
-	if e.cursorPos >= e.width {
-		if e.offset == 0 {
-			e.offset = 2
-		} else {
-			e.offset++
+// SelectAll selects the whole text and moves the cursor to its end
+func (e *EditField) SelectAll() {
+	e.Invalidate()
+	e.marking = false
+	e.moveCursor(0, false)
+	e.moveCursor(xs.Len(e.title), true)
+}
+
+// moveCursor moves the cursor to pos. If extend is true the selection is
+// extended to the new cursor position, otherwise the selection is removed
+func (e *EditField) moveCursor(pos int, extend bool) {
+	if length := xs.Len(e.title); pos > length {
+		pos = length
+	}
+	if pos < 0 {
+		pos = 0
+	}
+
+	if !extend {
+		e.selStart = -1
+	} else if e.selStart == -1 {
+		e.selStart = e.cursorPos
+	}
+
+	e.cursorPos = pos
+	e.lastEdit = editNone
+	e.scrollToCursor()
+}
+
+// charLeft moves the cursor one character left. If text is selected and
+// the selection is not extended, the cursor moves to the selection start
+func (e *EditField) charLeft(extend bool) {
+	if start, _, ok := e.selection(); ok && !extend {
+		e.moveCursor(start, false)
+		return
+	}
+	e.moveCursor(prevCluster([]rune(e.title), e.cursorPos), extend)
+}
+
+// charRight moves the cursor one character right. If text is selected and
+// the selection is not extended, the cursor moves to the selection end
+func (e *EditField) charRight(extend bool) {
+	if _, end, ok := e.selection(); ok && !extend {
+		e.moveCursor(end, false)
+		return
+	}
+	e.moveCursor(nextCluster([]rune(e.title), e.cursorPos), extend)
+}
+
+func (e *EditField) home() {
+	e.moveCursor(0, false)
+}
+
+func (e *EditField) end() {
+	e.moveCursor(xs.Len(e.title), false)
+}
+
+// beginEdit saves the text for undo before a change. Consecutive typing
+// is saved only once. Returns false if the text cannot be changed
+func (e *EditField) beginEdit(kind editKind) bool {
+	if e.readonly {
+		return false
+	}
+
+	if kind != editTyping || e.lastEdit != editTyping {
+		e.undo = append(e.undo, editFieldState{text: e.title, cursorPos: e.cursorPos})
+		if len(e.undo) > maxUndoSteps {
+			e.undo = e.undo[1:]
 		}
 	}
+	e.redo = nil
+	e.lastEdit = kind
+	e.marking = false
+	e.touched = true
+	return true
 }
 
-func (e *EditField) backspace() {
-	if e.title == "" || e.cursorPos == 0 || e.readonly {
+// insertText replaces the selected text with text, or inserts text at the
+// cursor position if nothing is selected. The inserted text is truncated
+// if the result exceeds the maximum length
+func (e *EditField) insertText(text string) {
+	runes := []rune(e.title)
+	start, end, _ := e.selection()
+	if e.mask != nil {
+		e.insertMasked(runes, start, end, text)
 		return
 	}
+	ins := []rune(text)
+	if e.maxWidth > 0 {
+		room := e.maxWidth - (len(runes) - (end - start))
+		if room < 0 {
+			room = 0
+		}
+		if len(ins) > room {
+			ins = ins[:room]
+		}
+	}
 
-	length := xs.Len(e.title)
-	if e.cursorPos >= length {
-		e.cursorPos--
-		e.setTitleInternal(xs.Slice(e.title, 0, length-1))
-	} else if e.cursorPos == 1 {
-		e.cursorPos = 0
-		e.setTitleInternal(xs.Slice(e.title, 1, -1))
-		e.offset = 0
+	res := make([]rune, 0, len(runes)-(end-start)+len(ins))
+	res = append(append(append(res, runes[:start]...), ins...), runes[end:]...)
+	e.selStart = -1
+	e.cursorPos = start + len(ins)
+	e.setTitleInternal(string(res))
+	e.scrollToCursor()
+}
+
+// deleteRange removes the text between start and end and moves the cursor
+// to start
+func (e *EditField) deleteRange(start, end int) {
+	runes := []rune(e.title)
+	e.selStart = -1
+	e.cursorPos = start
+	if e.mask != nil {
+		// mask literals are never deleted
+		for i := start; i < end; i++ {
+			if !e.mask[i].literal {
+				runes[i] = ' '
+			}
+		}
+		e.setTitleInternal(string(runes))
 	} else {
-		e.cursorPos--
-		e.setTitleInternal(xs.Slice(e.title, 0, e.cursorPos) + xs.Slice(e.title, e.cursorPos+1, -1))
+		e.setTitleInternal(string(runes[:start]) + string(runes[end:]))
+	}
+	e.scrollToCursor()
+}
+
+// insertMasked clears the selected positions between start and end, and
+// types text from start. Overwrites the characters under the cursor
+func (e *EditField) insertMasked(runes []rune, start, end int, text string) {
+	for i := start; i < end; i++ {
+		if !e.mask[i].literal {
+			runes[i] = ' '
+		}
 	}
 
-	if length-1 < e.width {
-		e.offset = 0
+	pos := start
+	for _, r := range text {
+		pos, _ = maskType(e.mask, runes, pos, r)
 	}
+	e.selStart = -1
+	e.cursorPos = pos
+	e.setTitleInternal(string(runes))
+	e.scrollToCursor()
 }
 
-func (e *EditField) del() {
-	length := xs.Len(e.title)
+// firstBlank returns the first empty position of the input mask or the
+// text length if all positions are filled
+func (e *EditField) firstBlank() int {
+	runes := []rune(e.title)
+	for i, m := range e.mask {
+		if !m.literal && i < len(runes) && runes[i] == ' ' {
+			return i
+		}
+	}
+	return len(runes)
+}
 
-	if e.title == "" || e.cursorPos == length || e.readonly {
+// deleteBefore deletes the selected text or, if nothing is selected, the
+// text between start and the cursor
+func (e *EditField) deleteBefore(start int) {
+	from, to, ok := e.selection()
+	if !ok {
+		if start < 0 {
+			start = 0
+		}
+		from, to = start, e.cursorPos
+	}
+	if from == to || !e.beginEdit(editOther) {
 		return
 	}
+	e.deleteRange(from, to)
+}
 
-	if e.cursorPos == length-1 {
-		e.setTitleInternal(xs.Slice(e.title, 0, length-1))
-	} else {
-		e.setTitleInternal(xs.Slice(e.title, 0, e.cursorPos) + xs.Slice(e.title, e.cursorPos+1, -1))
+func (e *EditField) insertRune(ch rune) {
+	_, _, selected := e.selection()
+	if e.mask != nil {
+		if _, ok := maskType(e.mask, []rune(e.title), e.cursorPos, ch); !ok {
+			return
+		}
+	} else if e.maxWidth > 0 && xs.Len(e.title) >= e.maxWidth && !selected {
+		return
 	}
 
-	if length-1 < e.width {
-		e.offset = 0
+	kind := editTyping
+	if selected {
+		kind = editOther
+	}
+	if !e.beginEdit(kind) {
+		return
 	}
+	// typing after the replaced selection is undone at once
+	e.lastEdit = editTyping
+	e.insertText(string(ch))
 }
 
-func (e *EditField) charLeft() {
-	if e.cursorPos == 0 || e.title == "" {
-		return
+func (e *EditField) backspace() {
+	runes := []rune(e.title)
+	start := prevCluster(runes, e.cursorPos)
+	// mask literals and empty positions are skipped
+	for e.mask != nil && start > 0 && (e.mask[start].literal || runes[start] == ' ') {
+		start--
 	}
+	e.deleteBefore(start)
+}
 
-	if e.cursorPos == e.offset {
-		e.offset--
+func (e *EditField) del() {
+	from, to, ok := e.selection()
+	if !ok {
+		to = nextCluster([]rune(e.title), e.cursorPos)
+		if to == e.cursorPos {
+			return
+		}
 	}
+	if to > xs.Len(e.title) || !e.beginEdit(editOther) {
+		return
+	}
+	e.deleteRange(from, to)
+}
 
-	e.cursorPos--
+// deleteWord deletes the word before the cursor
+func (e *EditField) deleteWord() {
+	e.deleteBefore(prevWordStart([]rune(e.title), e.cursorPos))
 }
 
-func (e *EditField) charRight() {
-	length := xs.Len(e.title)
-	if e.cursorPos == length || e.title == "" {
+// deleteToStart deletes the text between the beginning and the cursor
+func (e *EditField) deleteToStart() {
+	e.deleteBefore(0)
+}
+
+// InsertText replaces the selected text with the given one, or inserts the
+// text at the cursor position if nothing is selected. Line breaks are
+// replaced with spaces
+func (e *EditField) InsertText(text string) {
+	e.Invalidate()
+	text = strings.Replace(text, "\r\n", " ", -1)
+	text = strings.Replace(text, "\n", " ", -1)
+	text = strings.Replace(text, "\r", " ", -1)
+	if !e.beginEdit(editOther) {
 		return
 	}
+	e.insertText(text)
+}
 
-	e.cursorPos++
-	if e.cursorPos != length && e.cursorPos >= e.offset+e.width-2 {
-		e.offset++
+// Copy puts the selected text to the clipboard. Nothing is copied in
+// password mode
+func (e *EditField) Copy() {
+	if _, _, ok := e.selection(); ok && !e.showStars {
+		clipboardWrite(e.SelectedText())
 	}
 }
 
-func (e *EditField) home() {
-	e.offset = 0
-	e.cursorPos = 0
+// Cut puts the selected text to the clipboard and removes it. Nothing is
+// cut in password mode
+func (e *EditField) Cut() {
+	start, end, ok := e.selection()
+	if !ok || e.readonly || e.showStars {
+		return
+	}
+
+	e.Copy()
+	e.beginEdit(editOther)
+	e.deleteRange(start, end)
 }
 
-func (e *EditField) end() {
-	length := xs.Len(e.title)
-	e.cursorPos = length
+// Paste inserts the clipboard content at the cursor position replacing
+// the selected text
+func (e *EditField) Paste() {
+	if s, ok := clipboardRead(); ok && s != "" {
+		e.InsertText(s)
+	}
+}
 
-	if length < e.width {
-		return
+// Undo reverts the last change of the text. Returns false if there is
+// nothing to undo
+func (e *EditField) Undo() bool {
+	if len(e.undo) == 0 || e.readonly {
+		return false
 	}
 
-	e.offset = length - (e.width - 2)
+	e.redo = append(e.redo, editFieldState{text: e.title, cursorPos: e.cursorPos})
+	st := e.undo[len(e.undo)-1]
+	e.undo = e.undo[:len(e.undo)-1]
+	e.restore(st)
+	return true
+}
+
+// Redo restores the change reverted by Undo. Returns false if there is
+// nothing to redo
+func (e *EditField) Redo() bool {
+	if len(e.redo) == 0 || e.readonly {
+		return false
+	}
+
+	e.undo = append(e.undo, editFieldState{text: e.title, cursorPos: e.cursorPos})
+	st := e.redo[len(e.redo)-1]
+	e.redo = e.redo[:len(e.redo)-1]
+	e.restore(st)
+	return true
+}
+
+func (e *EditField) restore(st editFieldState) {
+	e.selStart, e.marking = -1, false
+	e.cursorPos = st.cursorPos
+	e.setTitleInternal(st.text)
+	e.lastEdit = editNone
+	e.scrollToCursor()
 }
 
 // Clear empties the EditField and emits OnChange event
@@ -232,10 +798,11 @@ func (e *EditField) Clear() {
 	e.setTitleInternal("")
 }
 
-// SetMaxWidth sets the maximum lenght of the EditField text. If the current text is longer it is truncated
+// SetMaxWidth sets the maximum lenght of the EditField text. If the current text is longer it is truncated.
+// The maximum length is ignored if the EditField has an input mask
 func (e *EditField) SetMaxWidth(w int) {
 	e.maxWidth = w
-	if w > 0 && xs.Len(e.title) > w {
+	if w > 0 && xs.Len(e.title) > w && e.mask == nil {
 		e.title = xs.Slice(e.title, 0, w)
 		e.end()
 	}
@@ -261,6 +828,7 @@ func (e *EditField) SetSize(width, height int) {
 
 	if width != KeepValue {
 		e.width = width
+		e.scrollToCursor()
 	}
 
 	e.height = 1
@@ -276,5 +844,84 @@ func (e *EditField) PasswordMode() bool {
 // control. If PasswordMode is true then the EditField shows its content hidden
 // with star characters ('*' by default)
 func (e *EditField) SetPasswordMode(pass bool) {
+	e.Invalidate()
 	e.showStars = pass
 }
+
+// SetValidator sets the function that checks the EditField text. The
+// function returns nil if the text is valid, and an error that describes
+// the problem otherwise. See predefined validators, e.g IntegerValidator.
+// nil removes the validator
+func (e *EditField) SetValidator(fn func(string) error) {
+	e.validator = fn
+}
+
+/*
+SetMask sets the input mask: a template that defines the text length, its
+fixed characters and the classes of characters a user can type. Mask
+characters:
+  9 - a digit
+  # - a digit or nothing(the position can be left blank)
+  A - a letter
+  X - a letter or a digit
+  \ - the next mask character is a fixed one, e.g "\9"
+Any other character is fixed: it is always displayed and the cursor skips
+it while typing. Empty positions are displayed as underscores, and they
+are spaces in the EditField text. Typing a fixed character moves the
+cursor after the nearest such character, e.g "10.1." in MaskIPv4.
+EditField with an incomplete mask(a position that cannot be blank is
+empty) is invalid. Empty mask removes the mask. The current text is
+converted to fit the mask
+*/
+func (e *EditField) SetMask(mask string) {
+	e.Invalidate()
+	e.maskDesc = mask
+	e.mask = nil
+	if mask != "" {
+		e.mask = parseMask(mask)
+	}
+	e.SetTitle(e.title)
+}
+
+// Mask returns the input mask. See SetMask
+func (e *EditField) Mask() string {
+	return e.maskDesc
+}
+
+// Validate checks the EditField text with the input mask and the
+// validator. Returns nil if the text is valid. After the call an invalid
+// EditField is displayed with error colors
+func (e *EditField) Validate() error {
+	e.touched = true
+	return e.validate()
+}
+
+func (e *EditField) validate() error {
+	if e.mask != nil {
+		runes := []rune(e.title)
+		for i, m := range e.mask {
+			if m.required() && (i >= len(runes) || runes[i] == ' ') {
+				return errIncomplete
+			}
+		}
+	}
+	if e.validator != nil {
+		return e.validator(e.title)
+	}
+	return nil
+}
+
+// Hint returns the text that the status bar displays while the control
+// is active. If a user has entered an invalid text, the hint is the error
+// message
+func (e *EditField) Hint() string {
+	if e.searching {
+		return e.searchHint()
+	}
+	if e.touched {
+		if err := e.validate(); err != nil {
+			return err.Error()
+		}
+	}
+	return e.BaseControl.Hint()
+}
@@ -0,0 +1,156 @@
+package clui
+
+import (
+	"strings"
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func modKey(key term.Key, mod term.Modifier) {
+	SimulateEvent(Event{Type: EventKey, Key: key, Mod: mod})
+}
+
+func TestEditField(t *testing.T) {
+	b := initHeadless(t, 30, 6)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 20, 4, "Edit")
+	edit := CreateEditField(wnd, 12, "", 1)
+	ActivateControl(wnd, edit)
+
+	typeText("hello big world")
+	if edit.Title() != "hello big world" || edit.cursorPos != 15 {
+		t.Fatalf("Invalid text: %q at %v", edit.Title(), edit.cursorPos)
+	}
+
+	// word jumps
+	modKey(term.KeyArrowLeft, term.ModAlt)
+	if edit.cursorPos != 10 {
+		t.Errorf("Alt+Left must jump to the word start: %v", edit.cursorPos)
+	}
+	modKey(term.KeyArrowLeft, ModCtrl)
+	modKey(term.KeyArrowLeft, ModCtrl)
+	if edit.cursorPos != 0 {
+		t.Errorf("Ctrl+Left must jump to the text start: %v", edit.cursorPos)
+	}
+	modKey(term.KeyArrowRight, term.ModAlt)
+	if edit.cursorPos != 6 {
+		t.Errorf("Alt+Right must jump to the next word: %v", edit.cursorPos)
+	}
+	SimulateKey(term.KeyCtrlF, 0)
+	if edit.cursorPos != 10 {
+		t.Errorf("Ctrl+F must jump to the next word: %v", edit.cursorPos)
+	}
+	SimulateKey(term.KeyCtrlB, 0)
+	if edit.cursorPos != 6 {
+		t.Errorf("Ctrl+B must jump to the previous word: %v", edit.cursorPos)
+	}
+
+	// selection with Shift and with Ctrl+Space marking
+	modKey(term.KeyArrowRight, ModShift)
+	modKey(term.KeyArrowRight, ModShift)
+	modKey(term.KeyArrowRight, ModShift)
+	if edit.SelectedText() != "big" {
+		t.Errorf("Shift+Right must select text: %q", edit.SelectedText())
+	}
+	RefreshScreen()
+	x, y := edit.Pos()
+	for i := 0; i < 5; i++ {
+		sel := i >= 1 && i <= 3
+		if cell := b.Cell(x+5+i, y); (cell.Bg == SysColor(ColorSelectionBack)) != sel {
+			t.Errorf("Invalid background of column %v: %v", 5+i, cell.Bg)
+		}
+	}
+
+	typeText("small")
+	if edit.Title() != "hello small world" || edit.SelectedText() != "" {
+		t.Errorf("Typing must replace the selection: %q", edit.Title())
+	}
+	SimulateKey(term.KeyHome, 0)
+	SimulateKey(term.KeyCtrlSpace, 0)
+	SimulateKey(term.KeyArrowRight, 0)
+	SimulateKey(term.KeyArrowRight, 0)
+	if edit.SelectedText() != "he" {
+		t.Errorf("Arrows must extend the selection after Ctrl+Space: %q", edit.SelectedText())
+	}
+	SimulateKey(term.KeyDelete, 0)
+	if edit.Title() != "llo small world" {
+		t.Errorf("Delete must remove the selection: %q", edit.Title())
+	}
+
+	// undo and redo
+	SimulateKey(term.KeyCtrlZ, 0)
+	if edit.Title() != "hello small world" {
+		t.Errorf("Undo must restore the text: %q", edit.Title())
+	}
+	SimulateKey(term.KeyCtrlZ, 0)
+	if edit.Title() != "hello big world" {
+		t.Errorf("Typed word must be undone at once: %q", edit.Title())
+	}
+	SimulateKey(term.KeyCtrlY, 0)
+	if edit.Title() != "hello small world" {
+		t.Errorf("Redo must restore the change: %q", edit.Title())
+	}
+
+	// delete word and line
+	SimulateKey(term.KeyEnd, 0)
+	SimulateKey(term.KeyCtrlK, 0)
+	if edit.Title() != "hello small " {
+		t.Errorf("Ctrl+K must delete the word at once: %q", edit.Title())
+	}
+	modKey(term.KeyBackspace2, term.ModAlt)
+	if edit.Title() != "hello " {
+		t.Errorf("Alt+Backspace must delete the word: %q", edit.Title())
+	}
+	SimulateKey(term.KeyArrowLeft, 0)
+	SimulateKey(term.KeyCtrlU, 0)
+	if edit.Title() != " " || edit.cursorPos != 0 {
+		t.Errorf("Ctrl+U must delete the text before the cursor: %q", edit.Title())
+	}
+
+	// inserting text at the cursor
+	edit.InsertText("two\nlines")
+	if edit.Title() != "two lines " || edit.cursorPos != 9 {
+		t.Errorf("Text must be inserted at the cursor: %q %v", edit.Title(), edit.cursorPos)
+	}
+	edit.SetMaxWidth(12)
+	edit.InsertText("long text")
+	if edit.Title() != "two lineslo " {
+		t.Errorf("Inserted text must be truncated: %q", edit.Title())
+	}
+}
+
+func TestEditFieldScroll(t *testing.T) {
+	b := initHeadless(t, 20, 5)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 12, 3, "Edit")
+	edit := CreateEditField(wnd, 8, "abcdefghijklmno", Fixed)
+	ActivateControl(wnd, edit)
+
+	row := func() string {
+		RefreshScreen()
+		x, y := edit.Pos()
+		line := []rune(strings.Split(b.Snapshot(), "\n")[y])
+		return string(line[x : x+8])
+	}
+
+	if s := row(); s != "←jklmno " {
+		t.Errorf("The end of the text must be visible: %q", s)
+	}
+	SimulateKey(term.KeyHome, 0)
+	if s := row(); s != "abcdefg→" {
+		t.Errorf("The beginning of the text must be visible: %q", s)
+	}
+	for i := 0; i < 8; i++ {
+		SimulateKey(term.KeyArrowRight, 0)
+	}
+	if s := row(); s != "←defghi→" || edit.offset != 3 {
+		t.Errorf("The cursor must be visible: %q", s)
+	}
+	modKey(term.KeyArrowLeft, term.ModAlt)
+	if s := row(); s != "abcdefg→" {
+		t.Errorf("The cursor must be visible after a word jump: %q", s)
+	}
+}
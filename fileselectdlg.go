@@ -52,16 +52,40 @@ func (d *FileSelectDialog) fileFitsMask(finfo os.FileInfo) bool {
 		return false
 	}
 
-	if len(d.fileMasks) == 0 {
+	return fileMatchesMasks(finfo.Name(), d.fileMasks)
+}
+
+// parseFileMasks splits the list of file masks separated with commas or
+// colons, e.g "*.txt,*.md"
+func parseFileMasks(fileMasks string) []string {
+	if fileMasks == "" {
+		return nil
+	}
+
+	maskList := strings.FieldsFunc(fileMasks,
+		func(c rune) bool { return c == ',' || c == ':' })
+	masks := make([]string, 0, len(maskList))
+	for _, m := range maskList {
+		if m != "" {
+			masks = append(masks, m)
+		}
+	}
+	return masks
+}
+
+// fileMatchesMasks returns true if the file name matches any of masks.
+// Empty list of masks, *, and *.* match any file
+func fileMatchesMasks(name string, masks []string) bool {
+	if len(masks) == 0 {
 		return true
 	}
 
-	for _, msk := range d.fileMasks {
+	for _, msk := range masks {
 		if msk == "*" || msk == "*.*" {
 			return true
 		}
 
-		matched, err := filepath.Match(msk, finfo.Name())
+		matched, err := filepath.Match(msk, name)
 		if err == nil && matched {
 			return true
 		}
@@ -70,6 +94,22 @@ func (d *FileSelectDialog) fileFitsMask(finfo os.FileInfo) bool {
 	return false
 }
 
+// sortFileInfos sorts the directory content: directories go first, and
+// names are compared case insensitive
+func sortFileInfos(finfos []os.FileInfo) {
+	fnLess := func(i, j int) bool {
+		if finfos[i].IsDir() && !finfos[j].IsDir() {
+			return true
+		} else if !finfos[i].IsDir() && finfos[j].IsDir() {
+			return false
+		}
+
+		return strings.ToLower(finfos[i].Name()) < strings.ToLower(finfos[j].Name())
+	}
+
+	sort.Slice(finfos, fnLess)
+}
+
 // Fills the ListBox with the file names from current directory.
 // Files which names do not match mask are filtered out.
 // If select directory is set, then the ListBox contains only directories.
@@ -93,17 +133,7 @@ func (d *FileSelectDialog) populateFiles() error {
 		return err
 	}
 
-	fnLess := func(i, j int) bool {
-		if finfos[i].IsDir() && !finfos[j].IsDir() {
-			return true
-		} else if !finfos[i].IsDir() && finfos[j].IsDir() {
-			return false
-		}
-
-		return strings.ToLower(finfos[i].Name()) < strings.ToLower(finfos[j].Name())
-	}
-
-	sort.Slice(finfos, fnLess)
+	sortFileInfos(finfos)
 
 	for _, finfo := range finfos {
 		if !d.fileFitsMask(finfo) {
@@ -193,26 +223,17 @@ func (d *FileSelectDialog) updateEditBox() {
 //       only existing object ('open file' case). If it is false then the dialog
 //       makes possible to enter a name manually and click 'Select' (useful
 //       for file 'file save' case)
-func CreateFileSelectDialog(title, fileMasks, initPath string, selectDir, mustExist bool) *FileSelectDialog {
+func (a *App) CreateFileSelectDialog(title, fileMasks, initPath string, selectDir, mustExist bool) *FileSelectDialog {
 	dlg := new(FileSelectDialog)
-	cw, ch := term.Size()
+	cw, ch := a.ScreenSize()
 	dlg.selectDir = selectDir
 	dlg.mustExist = mustExist
 
-	if fileMasks != "" {
-		maskList := strings.FieldsFunc(fileMasks,
-			func(c rune) bool { return c == ',' || c == ':' })
-		dlg.fileMasks = make([]string, 0, len(maskList))
-		for _, m := range maskList {
-			if m != "" {
-				dlg.fileMasks = append(dlg.fileMasks, m)
-			}
-		}
-	}
+	dlg.fileMasks = parseFileMasks(fileMasks)
 
-	dlg.View = AddWindow(10, 4, 20, 16, fmt.Sprintf("%s (%s)", title, fileMasks))
-	WindowManager().BeginUpdate()
-	defer WindowManager().EndUpdate()
+	dlg.View = a.AddWindow(10, 4, 20, 16, fmt.Sprintf("%s (%s)", title, fileMasks))
+	a.comp.BeginUpdate()
+	defer a.comp.EndUpdate()
 
 	dlg.View.SetModal(true)
 	dlg.View.SetPack(Vertical)
@@ -243,19 +264,19 @@ func CreateFileSelectDialog(title, fileMasks, initPath string, selectDir, mustEx
 	btnCancel := CreateButton(blist, AutoSize, AutoSize, "Cancel", Fixed)
 
 	btnCancel.OnClick(func(ev Event) {
-		WindowManager().DestroyWindow(dlg.View)
-		WindowManager().BeginUpdate()
+		a.comp.DestroyWindow(dlg.View)
+		a.comp.BeginUpdate()
 		dlg.Selected = false
 		closeFunc := dlg.onClose
-		WindowManager().EndUpdate()
+		a.comp.EndUpdate()
 		if closeFunc != nil {
 			closeFunc()
 		}
 	})
 
 	btnSelect.OnClick(func(ev Event) {
-		WindowManager().DestroyWindow(dlg.View)
-		WindowManager().BeginUpdate()
+		a.comp.DestroyWindow(dlg.View)
+		a.comp.BeginUpdate()
 		dlg.Selected = true
 
 		dlg.FilePath = filepath.Join(dlg.currPath, dlg.listBox.SelectedItemText())
@@ -266,7 +287,7 @@ func CreateFileSelectDialog(title, fileMasks, initPath string, selectDir, mustEx
 		dlg.Exists = !os.IsNotExist(err)
 
 		closeFunc := dlg.onClose
-		WindowManager().EndUpdate()
+		a.comp.EndUpdate()
 		if closeFunc != nil {
 			closeFunc()
 		}
@@ -276,7 +297,7 @@ func CreateFileSelectDialog(title, fileMasks, initPath string, selectDir, mustEx
 		if dlg.result == DialogAlive {
 			dlg.result = DialogClosed
 			if ev.X != 1 {
-				WindowManager().DestroyWindow(dlg.View)
+				a.comp.DestroyWindow(dlg.View)
 			}
 			if dlg.onClose != nil {
 				dlg.onClose()
@@ -410,10 +431,16 @@ func CreateFileSelectDialog(title, fileMasks, initPath string, selectDir, mustEx
 	return dlg
 }
 
+// CreateFileSelectDialog calls App.CreateFileSelectDialog of the default App
+func CreateFileSelectDialog(title, fileMasks, initPath string, selectDir, mustExist bool) *FileSelectDialog {
+	return defaultApp.CreateFileSelectDialog(title, fileMasks, initPath, selectDir, mustExist)
+}
+
 // OnClose sets the callback that is called when the
 // dialog is closed
 func (d *FileSelectDialog) OnClose(fn func()) {
-	WindowManager().BeginUpdate()
-	defer WindowManager().EndUpdate()
+	a := AppOf(d.View)
+	a.comp.BeginUpdate()
+	defer a.comp.EndUpdate()
 	d.onClose = fn
 }
@@ -1,7 +1,6 @@
 package clui
 
 import (
-	xs "github.com/huandu/xstrings"
 	"math"
 )
 
@@ -95,8 +94,9 @@ func (f *Frame) Draw() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(f)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	x, y, w, h := f.Clipper()
 	fx, fy := f.Pos()
@@ -128,32 +128,32 @@ func (f *Frame) Draw() {
 			f.lastScrollProp = prop
 		}
 
-		DrawScrollBar(x+w, y, 1, h, f.lastScrollProp)
+		a.DrawScrollBar(x+w, y, 1, h, f.lastScrollProp)
 	}
 
-	fg, bg := RealColor(f.fg, f.Style(), ColorViewText), RealColor(f.bg, f.Style(), ColorViewBack)
+	fg, bg := a.RealColor(f.fg, f.Style(), ColorViewText), a.RealColor(f.bg, f.Style(), ColorViewBack)
 
 	if f.border == BorderNone {
 		if bg != ColorDefault {
-			SetBackColor(bg)
-			FillRect(x, y, w, h, ' ')
+			a.SetBackColor(bg)
+			a.FillRect(x, y, w, h, ' ')
 		}
 
 		f.DrawChildren()
 		return
 	}
 
-	SetTextColor(fg)
-	SetBackColor(bg)
-	DrawFrame(fx, fy, fw, fh, f.border)
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.DrawFrame(fx, fy, fw, fh, f.border)
 
 	if f.title != "" {
 		str := f.title
 		raw := UnColorizeText(str)
-		if xs.Len(raw) > fw-2 {
+		if TextWidth(raw) > fw-2 {
 			str = SliceColorized(str, 0, fw-2-3) + "..."
 		}
-		DrawText(fx+1, fy, str)
+		a.DrawText(fx+1, fy, str)
 	}
 
 	f.DrawChildren()
@@ -162,6 +162,7 @@ func (f *Frame) Draw() {
 // ScrollTo in case of a scrollable frame this api will scroll the content
 // without adjusting the clipper
 func (f *Frame) ScrollTo(x int, y int) {
+	f.Invalidate()
 	if !f.scrollable {
 		return
 	}
@@ -173,7 +174,26 @@ func (f *Frame) ScrollTo(x int, y int) {
 	f.PlaceChildren()
 }
 
+// wheelScroll scrolls the content of a scrollable frame by a row or by
+// a column. The content always covers the visible area of the frame
+func (f *Frame) wheelScroll(dx, dy int) bool {
+	cx, cy, cw, ch := f.Clipper()
+	px, py := f.Paddings()
+	w, h := f.Size()
+	x, y := f.Pos()
+
+	// the frame moves in the direction opposite to scrolling
+	x = cx - px - clampScroll(cx-px-x+dx, w-cw-2*px)
+	y = cy - py - clampScroll(cy-py-y+dy, h-ch-2*py)
+	f.ScrollTo(x, y)
+	return true
+}
+
 func (f *Frame) ProcessEvent(ev Event) bool {
+	if dx, dy := WheelDelta(ev); dx != 0 || dy != 0 {
+		return f.scrollable && f.Enabled() && f.wheelScroll(dx, dy)
+	}
+
 	if ev.Type != EventActivateChild || (!f.scrollable || ev.Target == nil) {
 		return false
 	}
@@ -0,0 +1,101 @@
+package clui
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden snapshot files in testdata")
+
+func initHeadless(t *testing.T, width, height int) *HeadlessBackend {
+	b := NewHeadlessBackend(width, height)
+	if !InitLibrary(b) {
+		t.Fatal("Failed to initialize library with headless backend")
+	}
+	return b
+}
+
+func checkSnapshot(t *testing.T, b *HeadlessBackend, name string) {
+	t.Helper()
+	golden := filepath.Join("testdata", name+".golden")
+	if err := b.CompareSnapshot(golden, *updateGolden); err != nil {
+		t.Errorf("Snapshot mismatch: %v\nScreen:\n%v", err, b.Snapshot())
+	}
+}
+
+func TestHeadlessWindow(t *testing.T) {
+	b := initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(1, 1, 30, 8, "Headless")
+	wnd.SetPack(Vertical)
+	CreateLabel(wnd, AutoSize, AutoSize, "Name:", Fixed)
+	edit := CreateEditField(wnd, 20, "", Fixed)
+	CreateButton(wnd, AutoSize, AutoSize, "OK", Fixed)
+	ActivateControl(wnd, edit)
+	RefreshScreen()
+
+	SimulateKey(0, 'c')
+	SimulateKey(0, 'l')
+	SimulateKey(0, 'u')
+	SimulateKey(0, 'i')
+
+	if edit.Title() != "clui" {
+		t.Errorf("EditField text must be %v instead of %v", "clui", edit.Title())
+	}
+	x, y := b.CursorPos()
+	ex, ey := edit.Pos()
+	if x != ex+4 || y != ey {
+		t.Errorf("Cursor must be at %v:%v instead of %v:%v", ex+4, ey, x, y)
+	}
+	checkSnapshot(t, b, "window")
+}
+
+func TestHeadlessTableView(t *testing.T) {
+	b := initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 36, 10, "Table")
+	tv := CreateTableView(wnd, 30, 7, 1)
+	tv.SetShowLines(true)
+	tv.SetShowRowNumber(true)
+	tv.SetColumns([]Column{
+		{Title: "Name", Width: 8, Alignment: AlignLeft},
+		{Title: "Value", Width: 6, Alignment: AlignRight},
+	})
+	tv.SetRowCount(10)
+	tv.OnDrawCell(func(info *ColumnDrawInfo) {
+		info.Text = fmt.Sprintf("r%vc%v", info.Row, info.Col)
+	})
+	ActivateControl(wnd, tv)
+	RefreshScreen()
+
+	SimulateKey(term.KeyArrowDown, 0)
+	SimulateKey(term.KeyArrowDown, 0)
+	SimulateKey(term.KeyArrowRight, 0)
+
+	if tv.SelectedRow() != 2 || tv.SelectedCol() != 1 {
+		t.Errorf("Selected cell must be 2:1 instead of %v:%v", tv.SelectedRow(), tv.SelectedCol())
+	}
+	checkSnapshot(t, b, "tableview")
+}
+
+func TestHeadlessFileSelectDialog(t *testing.T) {
+	b := initHeadless(t, 60, 25)
+	defer DeinitLibrary()
+
+	dlg := CreateFileSelectDialog("Open", "*.txt", filepath.Join("testdata", "fselect"), false, true)
+	RefreshScreen()
+
+	SimulateKey(term.KeyArrowDown, 0)
+	SimulateKey(term.KeyArrowDown, 0)
+
+	if dlg.listBox.SelectedItemText() != "alpha.txt" {
+		t.Errorf("Selected item must be %v instead of %v", "alpha.txt", dlg.listBox.SelectedItemText())
+	}
+	checkSnapshot(t, b, "fileselect")
+}
@@ -0,0 +1,298 @@
+package clui
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	term "github.com/nsf/termbox-go"
+)
+
+// DefaultHistorySize is the maximum number of History entries if the
+// size passed to CreateHistory is not positive
+const DefaultHistorySize = 100
+
+/*
+History is a list of texts entered by a user, e.g commands of a REPL. It
+is a ring: after it gets full, adding a new entry removes the oldest one.
+The same History can be attached to several EditFields(see
+EditField.SetHistory). History can be saved to and loaded from a file:
+one entry per line, the oldest entry first.
+*/
+type History struct {
+	items []string
+	size  int
+}
+
+// CreateHistory creates an empty History that keeps up to size entries
+func CreateHistory(size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &History{size: size}
+}
+
+// Add appends the text to the end of the history. Line breaks are
+// replaced with spaces. Empty text and the text that equals to the last
+// entry are not added
+func (h *History) Add(text string) {
+	text = strings.Replace(text, "\r\n", " ", -1)
+	text = strings.Replace(text, "\n", " ", -1)
+	text = strings.Replace(text, "\r", " ", -1)
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	if n := len(h.items); n > 0 && h.items[n-1] == text {
+		return
+	}
+
+	h.items = append(h.items, text)
+	if len(h.items) > h.size {
+		h.items = h.items[len(h.items)-h.size:]
+	}
+}
+
+// Len returns the number of entries
+func (h *History) Len() int {
+	return len(h.items)
+}
+
+// Item returns the entry by its index. 0 is the oldest entry. If index
+// is out of range an empty string and false are returned
+func (h *History) Item(id int) (string, bool) {
+	if id < 0 || id >= len(h.items) {
+		return "", false
+	}
+	return h.items[id], true
+}
+
+// Items returns all entries, the oldest one first
+func (h *History) Items() []string {
+	res := make([]string, len(h.items))
+	copy(res, h.items)
+	return res
+}
+
+// Clear removes all entries
+func (h *History) Clear() {
+	h.items = nil
+}
+
+// Load reads entries from r, one entry per line, and adds them to the
+// history
+func (h *History) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		h.Add(scanner.Text())
+	}
+	return scanner.Err()
+}
+
+// Save writes all entries to w, one entry per line
+func (h *History) Save(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, item := range h.items {
+		if _, err := bw.WriteString(item + "\n"); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// LoadFile adds entries from the file to the history. A file that does
+// not exist is not an error: the history is not changed
+func (h *History) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return h.Load(f)
+}
+
+// SaveFile writes the history to the file. The file is overwritten
+func (h *History) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	err = h.Save(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+/*
+SetHistory attaches the history to the EditField. nil removes it.
+EditField with a history adds its text to the history when a user presses
+Enter(password fields do not). Up and down arrows replace the text with
+the previous or next entry, the text typed before browsing the history is
+restored after the newest entry. The history search key(Ctrl+R by default,
+see SetHistorySearchKey) starts an incremental reverse search: typed text
+finds the newest entry that contains it, the search key again finds the
+older one, Backspace removes the last character of the searched text, Esc
+or Ctrl+G cancels the search and restores the text. Any other key, e.g
+Enter or arrows, accepts the found entry and is processed as usual. The
+status bar displays the searched text
+*/
+func (e *EditField) SetHistory(h *History) {
+	e.history = h
+	e.histBack = 0
+	e.searching = false
+}
+
+// History returns the attached history or nil
+func (e *EditField) History() *History {
+	return e.history
+}
+
+// SetHistorySearchKey changes the key that starts the reverse search in
+// the history. The key takes precedence over the EditField key with the
+// same code: Ctrl+R clears the text only if the EditField does not have
+// a history or the search key is not Ctrl+R. 0 disables the search
+func (e *EditField) SetHistorySearchKey(key term.Key) {
+	e.searchKey = key
+}
+
+// HistorySearchKey returns the key that starts the reverse search in the
+// history
+func (e *EditField) HistorySearchKey() term.Key {
+	return e.searchKey
+}
+
+// addToHistory is called after a user presses Enter
+func (e *EditField) addToHistory() {
+	if e.history == nil || e.showStars {
+		return
+	}
+	e.history.Add(e.title)
+	e.histBack = 0
+}
+
+// browseHistory replaces the text with the entry dy entries newer(or
+// older if dy is negative) than the displayed one. Returns false if the
+// history is empty
+func (e *EditField) browseHistory(dy int) bool {
+	if e.history == nil || e.history.Len() == 0 {
+		return false
+	}
+
+	back := e.histBack - dy
+	if back > e.history.Len() {
+		back = e.history.Len()
+	}
+	if back < 0 {
+		back = 0
+	}
+	if back == e.histBack || !e.beginEdit(editOther) {
+		return true
+	}
+
+	if e.histBack == 0 {
+		e.histDraft = e.title
+	}
+	text := e.histDraft
+	if back > 0 {
+		text, _ = e.history.Item(e.history.Len() - back)
+	}
+	e.selStart = -1
+	e.setTitleInternal(text)
+	e.end()
+	e.histBack = back
+	return true
+}
+
+// startSearch starts the reverse search in the history
+func (e *EditField) startSearch() {
+	if !e.beginEdit(editOther) {
+		return
+	}
+	e.searching = true
+	e.searchQuery = ""
+	e.searchFailed = false
+	e.searchPos = e.history.Len()
+	e.histDraft = e.title
+}
+
+// findHistory looks for the newest entry that contains the searched text
+// starting from the entry from and going to older ones. The found entry
+// is displayed with the searched text selected
+func (e *EditField) findHistory(from int) {
+	if e.searchQuery == "" {
+		e.searchPos = e.history.Len()
+		e.searchFailed = false
+		e.selStart = -1
+		e.setTitleInternal(e.histDraft)
+		e.end()
+		return
+	}
+
+	for i := from; i >= 0; i-- {
+		item, _ := e.history.Item(i)
+		idx := strings.Index(item, e.searchQuery)
+		if idx == -1 {
+			continue
+		}
+
+		e.searchPos = i
+		e.searchFailed = false
+		e.selStart = -1
+		e.setTitleInternal(item)
+		start := utf8.RuneCountInString(item[:idx])
+		e.moveCursor(start, false)
+		e.selStart = start + utf8.RuneCountInString(e.searchQuery)
+		return
+	}
+
+	e.searchFailed = true
+}
+
+// processSearchKey handles keys during the reverse search. Returns false
+// if the key finishes the search and must be processed as usual
+func (e *EditField) processSearchKey(event Event) bool {
+	switch {
+	case event.Ch != 0 && event.Mod&term.ModAlt == 0:
+		e.searchQuery += string(event.Ch)
+		e.findHistory(e.searchPos)
+	case event.Key == term.KeySpace:
+		e.searchQuery += " "
+		e.findHistory(e.searchPos)
+	case event.Key == e.searchKey:
+		e.findHistory(e.searchPos - 1)
+	case event.Key == term.KeyBackspace || event.Key == term.KeyBackspace2:
+		if q := []rune(e.searchQuery); len(q) > 0 {
+			e.searchQuery = string(q[:len(q)-1])
+		}
+		e.findHistory(e.history.Len() - 1)
+	case event.Key == term.KeyEsc || event.Key == term.KeyCtrlG:
+		// the text before the search was saved for undo when the search
+		// started
+		st := e.undo[len(e.undo)-1]
+		e.undo = e.undo[:len(e.undo)-1]
+		e.searching = false
+		e.restore(st)
+	default:
+		e.searching = false
+		e.selStart = -1
+		e.end()
+		return false
+	}
+
+	return true
+}
+
+// searchHint returns the status bar text during the reverse search
+func (e *EditField) searchHint() string {
+	if e.searchFailed {
+		return "Failed reverse search: " + e.searchQuery
+	}
+	return "Reverse search: " + e.searchQuery
+}
@@ -0,0 +1,127 @@
+package clui
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestHistory(t *testing.T) {
+	h := CreateHistory(3)
+	for _, s := range []string{"ls", "", "cd /", "cd /", "  ", "pwd", "echo\nhi"} {
+		h.Add(s)
+	}
+	if items := h.Items(); !reflect.DeepEqual(items, []string{"cd /", "pwd", "echo hi"}) {
+		t.Errorf("Invalid history: %q", items)
+	}
+
+	var buf bytes.Buffer
+	if err := h.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "cd /\npwd\necho hi\n" {
+		t.Errorf("Invalid saved history: %q", buf.String())
+	}
+
+	path := filepath.Join(t.TempDir(), "history")
+	loaded := CreateHistory(0)
+	if err := loaded.LoadFile(path); err != nil || loaded.Len() != 0 {
+		t.Errorf("Missing file must be loaded as empty history: %v", err)
+	}
+	if err := h.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.LoadFile(path); err != nil || !reflect.DeepEqual(loaded.Items(), h.Items()) {
+		t.Errorf("Invalid loaded history: %q %v", loaded.Items(), err)
+	}
+}
+
+func TestEditFieldHistory(t *testing.T) {
+	initHeadless(t, 40, 10)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 30, 6, "REPL")
+	wnd.SetPack(Vertical)
+	edit := CreateEditField(wnd, 20, "", Fixed)
+	CreateEditField(wnd, 20, "", Fixed)
+	ActivateControl(wnd, edit)
+
+	// without a history arrows move focus
+	SimulateKey(term.KeyArrowDown, 0)
+	if edit.Active() {
+		t.Fatal("Arrow must move focus if EditField does not have a history")
+	}
+	ActivateControl(wnd, edit)
+
+	h := CreateHistory(10)
+	edit.SetHistory(h)
+	for _, cmd := range []string{"make build", "git status", "make test"} {
+		typeText(cmd)
+		SimulateKey(term.KeyEnter, 0)
+		edit.SetTitle("")
+	}
+	if h.Len() != 3 {
+		t.Fatalf("Enter must add the text to the history: %q", h.Items())
+	}
+
+	typeText("dra")
+	SimulateKey(term.KeyArrowUp, 0)
+	SimulateKey(term.KeyArrowUp, 0)
+	if edit.Title() != "git status" || edit.cursorPos != 10 {
+		t.Errorf("Up must display older entries: %q", edit.Title())
+	}
+	SimulateKey(term.KeyArrowUp, 0)
+	SimulateKey(term.KeyArrowUp, 0)
+	if edit.Title() != "make build" || !edit.Active() {
+		t.Errorf("Up must stop at the oldest entry: %q", edit.Title())
+	}
+	for i := 0; i < 3; i++ {
+		SimulateKey(term.KeyArrowDown, 0)
+	}
+	if edit.Title() != "dra" {
+		t.Errorf("Down must restore the typed text: %q", edit.Title())
+	}
+
+	// reverse search
+	SimulateKey(term.KeyCtrlR, 0)
+	typeText("ma")
+	if edit.Title() != "make test" || edit.SelectedText() != "ma" {
+		t.Errorf("Search must find the newest entry: %q", edit.Title())
+	}
+	if edit.Hint() != "Reverse search: ma" {
+		t.Errorf("Invalid hint: %q", edit.Hint())
+	}
+	SimulateKey(term.KeyCtrlR, 0)
+	if edit.Title() != "make build" {
+		t.Errorf("Search key must find the older entry: %q", edit.Title())
+	}
+	typeText("x")
+	if edit.Title() != "make build" || edit.Hint() != "Failed reverse search: max" {
+		t.Errorf("Failed search must keep the found entry: %q %q", edit.Title(), edit.Hint())
+	}
+	SimulateKey(term.KeyEsc, 0)
+	if edit.Title() != "dra" || edit.searching {
+		t.Errorf("Esc must cancel the search: %q", edit.Title())
+	}
+
+	SimulateKey(term.KeyCtrlR, 0)
+	typeText("sta")
+	SimulateKey(term.KeyEnd, 0)
+	if edit.Title() != "git status" || edit.SelectedText() != "" || edit.searching {
+		t.Errorf("Key must accept the found entry: %q", edit.Title())
+	}
+	SimulateKey(term.KeyCtrlZ, 0)
+	if edit.Title() != "dra" {
+		t.Errorf("Accepted search must be undoable: %q", edit.Title())
+	}
+
+	// Ctrl+R clears the text if it does not start the search
+	edit.SetHistorySearchKey(term.KeyCtrlT)
+	SimulateKey(term.KeyCtrlR, 0)
+	if edit.Title() != "" {
+		t.Errorf("Ctrl+R must clear the text: %q", edit.Title())
+	}
+}
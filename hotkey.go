@@ -0,0 +1,445 @@
+package clui
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	term "github.com/nsf/termbox-go"
+)
+
+// KeyPress is a single key press of a hotkey sequence. For printable
+// characters Ch is set and Key is zero, for special keys Key is set and
+// Ch is zero. Mod is a combination of term.ModAlt, ModCtrl and ModShift.
+// ModCtrl and ModShift make sense only for special keys, e.g Ctrl+PgUp:
+// Ctrl with a letter is a separate key, like term.KeyCtrlA
+type KeyPress struct {
+	Key term.Key
+	Ch  rune
+	Mod term.Modifier
+}
+
+// hotkeyBinding is a sequence of key presses and a function that is called
+// when a user presses all keys of the sequence one by one
+type hotkeyBinding struct {
+	keys   []KeyPress
+	action HotkeyAction
+	// custom is true for application global hotkeys that do not run
+	// any built-in action
+	custom bool
+	// sticky binding keeps its prefix pressed after the last key, so the
+	// last key can be repeated without pressing the whole sequence again
+	sticky bool
+	fn     func(Event) bool
+}
+
+type hotkeyMap struct {
+	mtx      sync.RWMutex
+	bindings []*hotkeyBinding
+}
+
+var (
+	// hotkeys of the default App are kept between InitLibrary calls, so
+	// an application can set them before the library is initialized
+	defaultHotkeys = newHotkeyMap()
+)
+
+var keyNames = []struct {
+	key  term.Key
+	name string
+}{
+	{term.KeyF1, "F1"}, {term.KeyF2, "F2"}, {term.KeyF3, "F3"},
+	{term.KeyF4, "F4"}, {term.KeyF5, "F5"}, {term.KeyF6, "F6"},
+	{term.KeyF7, "F7"}, {term.KeyF8, "F8"}, {term.KeyF9, "F9"},
+	{term.KeyF10, "F10"}, {term.KeyF11, "F11"}, {term.KeyF12, "F12"},
+	{term.KeyInsert, "Insert"}, {term.KeyDelete, "Delete"},
+	{term.KeyHome, "Home"}, {term.KeyEnd, "End"},
+	{term.KeyPgup, "PgUp"}, {term.KeyPgdn, "PgDn"},
+	{term.KeyArrowUp, "Up"}, {term.KeyArrowDown, "Down"},
+	{term.KeyArrowLeft, "Left"}, {term.KeyArrowRight, "Right"},
+	{term.KeyTab, "Tab"}, {term.KeyEnter, "Enter"}, {term.KeyEsc, "Esc"},
+	{term.KeySpace, "Space"}, {term.KeyBackspace2, "Backspace"},
+}
+
+func newHotkeyMap() *hotkeyMap {
+	m := new(hotkeyMap)
+	m.reset()
+	return m
+}
+
+// reset restores the default set of hotkeys
+func (m *hotkeyMap) reset() {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.bindings = nil
+	defaults := []struct {
+		action HotkeyAction
+		keys   []term.Key
+	}{
+		{HotkeyQuit, []term.Key{term.KeyCtrlQ, term.KeyCtrlQ}},
+		{HotkeyWindowHide, []term.Key{term.KeyCtrlW, term.KeyCtrlH}},
+		{HotkeyWindowMaximize, []term.Key{term.KeyCtrlW, term.KeyCtrlM}},
+		{HotkeyWindowClose, []term.Key{term.KeyCtrlW, term.KeyCtrlC}},
+		{HotkeyWindowMoveUp, []term.Key{term.KeyCtrlP, term.KeyArrowUp}},
+		{HotkeyWindowMoveDown, []term.Key{term.KeyCtrlP, term.KeyArrowDown}},
+		{HotkeyWindowMoveLeft, []term.Key{term.KeyCtrlP, term.KeyArrowLeft}},
+		{HotkeyWindowMoveRight, []term.Key{term.KeyCtrlP, term.KeyArrowRight}},
+		{HotkeyWindowShorter, []term.Key{term.KeyCtrlS, term.KeyArrowUp}},
+		{HotkeyWindowTaller, []term.Key{term.KeyCtrlS, term.KeyArrowDown}},
+		{HotkeyWindowNarrower, []term.Key{term.KeyCtrlS, term.KeyArrowLeft}},
+		{HotkeyWindowWider, []term.Key{term.KeyCtrlS, term.KeyArrowRight}},
+		{HotkeyMenuBar, []term.Key{term.KeyF10}},
+	}
+	for _, d := range defaults {
+		keys := make([]KeyPress, 0, len(d.keys))
+		for _, k := range d.keys {
+			keys = append(keys, KeyPress{Key: k})
+		}
+		m.bind(&hotkeyBinding{keys: keys, action: d.action})
+	}
+
+	// TabView keys work only with backends that report Ctrl and Alt keys,
+	// e.g StreamBackend: termbox reports Alt+1 as Esc followed by 1, and
+	// does not report Ctrl+PgUp at all
+	m.bind(&hotkeyBinding{keys: []KeyPress{{Key: term.KeyPgup, Mod: ModCtrl}}, action: HotkeyTabPrev})
+	m.bind(&hotkeyBinding{keys: []KeyPress{{Key: term.KeyPgdn, Mod: ModCtrl}}, action: HotkeyTabNext})
+	for i := 0; i < 9; i++ {
+		action, ch := HotkeyTab1+HotkeyAction(i), rune('1'+i)
+		m.bind(&hotkeyBinding{keys: []KeyPress{{Ch: ch, Mod: term.ModAlt}}, action: action})
+	}
+}
+
+// bind must be called with the map locked. A binding replaces any other
+// binding with the same key sequence
+func (m *hotkeyMap) bind(b *hotkeyBinding) {
+	m.unbind(b.keys)
+	if !b.custom {
+		b.sticky = (b.action >= HotkeyWindowMoveUp && b.action <= HotkeyWindowWider) ||
+			b.action == HotkeyTabPrev || b.action == HotkeyTabNext
+	}
+	m.bindings = append(m.bindings, b)
+}
+
+// unbind must be called with the map locked
+func (m *hotkeyMap) unbind(keys []KeyPress) bool {
+	for i, b := range m.bindings {
+		if sameKeys(b.keys, keys) {
+			m.bindings = append(m.bindings[:i], m.bindings[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// find returns the binding for a key sequence and true if the sequence is
+// the beginning of any longer binding
+func (m *hotkeyMap) find(keys []KeyPress) (*hotkeyBinding, bool) {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	var found *hotkeyBinding
+	prefix := false
+	for _, b := range m.bindings {
+		if len(b.keys) < len(keys) || !sameKeys(b.keys[:len(keys)], keys) {
+			continue
+		}
+		if len(b.keys) == len(keys) {
+			found = b
+		} else {
+			prefix = true
+		}
+	}
+
+	return found, prefix
+}
+
+func sameKeys(a, b []KeyPress) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func keyPressFromEvent(ev Event) KeyPress {
+	k := KeyPress{Key: ev.Key, Ch: ev.Ch, Mod: ev.Mod & (term.ModAlt | ModCtrl | ModShift)}
+	if k.Ch != 0 {
+		k.Key = 0
+	}
+	return k
+}
+
+// SetHotkey replaces all hotkeys of a built-in action with a new key
+// sequence. Calling SetHotkey without keys disables the action. Example:
+//
+//	keys, _ := ParseHotkey("Ctrl+X Ctrl+C")
+//	app.SetHotkey(HotkeyQuit, keys...)
+func (a *App) SetHotkey(action HotkeyAction, keys ...KeyPress) {
+	a.hotkeys.setAction(action, keys)
+}
+
+// AddHotkey adds one more key sequence for a built-in action. If the
+// sequence is already used by other action or by global hotkey, the
+// sequence is reassigned
+func (a *App) AddHotkey(action HotkeyAction, keys ...KeyPress) {
+	a.hotkeys.addAction(action, keys)
+}
+
+// DisableHotkey removes all key sequences of a built-in action
+func (a *App) DisableHotkey(action HotkeyAction) {
+	a.hotkeys.disableAction(action)
+}
+
+// AddGlobalHotkey registers an application-wide hotkey. The callback is
+// called before the active Window sees the last key of the sequence. If
+// the callback returns false, the key is sent to the active Window as
+// usual. A sequence can contain a few keys, like "Ctrl+K Ctrl+D"
+func (a *App) AddGlobalHotkey(fn func(Event) bool, keys ...KeyPress) {
+	a.hotkeys.addGlobal(fn, keys)
+}
+
+// RemoveHotkey removes a key sequence whether it is a built-in action
+// hotkey or a global one. Returns false if the sequence is not found
+func (a *App) RemoveHotkey(keys ...KeyPress) bool {
+	return a.hotkeys.remove(keys)
+}
+
+// ResetHotkeys removes all global hotkeys and restores the default
+// hotkeys of built-in actions
+func (a *App) ResetHotkeys() {
+	a.hotkeys.reset()
+}
+
+// Hotkeys returns all key sequences assigned to a built-in action
+func (a *App) Hotkeys(action HotkeyAction) [][]KeyPress {
+	return a.hotkeys.actionKeys(action)
+}
+
+// The package hotkey functions change the hotkeys of the default App. They
+// can be called before InitLibrary
+
+// SetHotkey calls App.SetHotkey of the default App
+func SetHotkey(action HotkeyAction, keys ...KeyPress) {
+	defaultHotkeys.setAction(action, keys)
+}
+
+// AddHotkey calls App.AddHotkey of the default App
+func AddHotkey(action HotkeyAction, keys ...KeyPress) {
+	defaultHotkeys.addAction(action, keys)
+}
+
+// DisableHotkey calls App.DisableHotkey of the default App
+func DisableHotkey(action HotkeyAction) {
+	defaultHotkeys.disableAction(action)
+}
+
+// AddGlobalHotkey calls App.AddGlobalHotkey of the default App
+func AddGlobalHotkey(fn func(Event) bool, keys ...KeyPress) {
+	defaultHotkeys.addGlobal(fn, keys)
+}
+
+// RemoveHotkey calls App.RemoveHotkey of the default App
+func RemoveHotkey(keys ...KeyPress) bool {
+	return defaultHotkeys.remove(keys)
+}
+
+// ResetHotkeys calls App.ResetHotkeys of the default App
+func ResetHotkeys() {
+	defaultHotkeys.reset()
+}
+
+// Hotkeys calls App.Hotkeys of the default App
+func Hotkeys(action HotkeyAction) [][]KeyPress {
+	return defaultHotkeys.actionKeys(action)
+}
+
+func (m *hotkeyMap) setAction(action HotkeyAction, keys []KeyPress) {
+	m.disableAction(action)
+	if len(keys) != 0 {
+		m.addAction(action, keys)
+	}
+}
+
+func (m *hotkeyMap) addAction(action HotkeyAction, keys []KeyPress) {
+	if len(keys) == 0 {
+		return
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.bind(&hotkeyBinding{keys: copyKeys(keys), action: action})
+}
+
+func (m *hotkeyMap) disableAction(action HotkeyAction) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	bindings := make([]*hotkeyBinding, 0, len(m.bindings))
+	for _, b := range m.bindings {
+		if b.custom || b.action != action {
+			bindings = append(bindings, b)
+		}
+	}
+	m.bindings = bindings
+}
+
+func (m *hotkeyMap) addGlobal(fn func(Event) bool, keys []KeyPress) {
+	if fn == nil || len(keys) == 0 {
+		return
+	}
+
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.bind(&hotkeyBinding{keys: copyKeys(keys), custom: true, fn: fn})
+}
+
+func (m *hotkeyMap) remove(keys []KeyPress) bool {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return m.unbind(keys)
+}
+
+func (m *hotkeyMap) actionKeys(action HotkeyAction) [][]KeyPress {
+	m.mtx.RLock()
+	defer m.mtx.RUnlock()
+
+	var res [][]KeyPress
+	for _, b := range m.bindings {
+		if !b.custom && b.action == action {
+			res = append(res, copyKeys(b.keys))
+		}
+	}
+	return res
+}
+
+func copyKeys(keys []KeyPress) []KeyPress {
+	res := make([]KeyPress, len(keys))
+	copy(res, keys)
+	return res
+}
+
+// ParseHotkey converts a text description of a key sequence to a list of
+// key presses. Keys are separated with spaces, modifiers are joined with
+// '+': "Ctrl+W Ctrl+C", "Alt+X", "F10", "Ctrl+P Left". Key names are case
+// insensitive
+func ParseHotkey(s string) ([]KeyPress, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("Empty hotkey")
+	}
+
+	keys := make([]KeyPress, 0, len(fields))
+	for _, f := range fields {
+		k, err := parseKeyPress(f)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, nil
+}
+
+func parseKeyPress(s string) (KeyPress, error) {
+	var k KeyPress
+	ctrl := false
+
+	parts := strings.Split(s, "+")
+	// "Alt++" means Alt and plus key
+	if strings.HasSuffix(s, "++") {
+		parts = append(parts[:len(parts)-2], "+")
+	}
+	for _, mod := range parts[:len(parts)-1] {
+		switch strings.ToLower(mod) {
+		case "ctrl":
+			ctrl = true
+		case "alt":
+			k.Mod |= term.ModAlt
+		case "shift":
+			k.Mod |= ModShift
+		default:
+			return k, fmt.Errorf("Invalid key modifier '%s' in '%s'", mod, s)
+		}
+	}
+
+	name := parts[len(parts)-1]
+	runes := []rune(name)
+	if len(runes) == 1 {
+		ch := runes[0]
+		if k.Mod&ModShift != 0 {
+			return k, fmt.Errorf("Unsupported key '%s'", s)
+		}
+		if !ctrl {
+			k.Ch = ch
+			return k, nil
+		}
+		lower := ch | 0x20
+		if lower >= 'a' && lower <= 'z' {
+			k.Key = term.KeyCtrlA + term.Key(lower-'a')
+			return k, nil
+		}
+		return k, fmt.Errorf("Unsupported key '%s'", s)
+	}
+
+	if ctrl && strings.ToLower(name) == "space" {
+		k.Key = term.KeyCtrlSpace
+		return k, nil
+	}
+	for _, kn := range keyNames {
+		if strings.EqualFold(kn.name, name) {
+			if ctrl {
+				k.Mod |= ModCtrl
+			}
+			k.Key = kn.key
+			return k, nil
+		}
+	}
+
+	return k, fmt.Errorf("Unknown key '%s'", s)
+}
+
+// String returns a human readable key name, e.g "Ctrl+S" or "Alt+F1"
+func (k KeyPress) String() string {
+	mod := ""
+	if k.Mod&ModCtrl != 0 {
+		mod += "Ctrl+"
+	}
+	if k.Mod&term.ModAlt != 0 {
+		mod += "Alt+"
+	}
+	if k.Mod&ModShift != 0 {
+		mod += "Shift+"
+	}
+
+	if k.Ch != 0 {
+		return mod + string(k.Ch)
+	}
+	for _, kn := range keyNames {
+		if kn.key == k.Key {
+			return mod + kn.name
+		}
+	}
+	if k.Key == term.KeyCtrlSpace {
+		return mod + "Ctrl+Space"
+	}
+	if k.Key >= term.KeyCtrlA && k.Key <= term.KeyCtrlZ {
+		return mod + "Ctrl+" + string(rune('A'+k.Key-term.KeyCtrlA))
+	}
+
+	return fmt.Sprintf("%s0x%X", mod, uint16(k.Key))
+}
+
+// HotkeyToString returns a text description of a key sequence that can
+// be parsed back with ParseHotkey
+func HotkeyToString(keys []KeyPress) string {
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, k.String())
+	}
+	return strings.Join(names, " ")
+}
@@ -0,0 +1,126 @@
+package clui
+
+import (
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestParseHotkey(t *testing.T) {
+	cases := []struct {
+		text string
+		keys []KeyPress
+		str  string
+	}{
+		{"Ctrl+W Ctrl+C", []KeyPress{{Key: term.KeyCtrlW}, {Key: term.KeyCtrlC}}, "Ctrl+W Ctrl+C"},
+		{"ctrl+p left", []KeyPress{{Key: term.KeyCtrlP}, {Key: term.KeyArrowLeft}}, "Ctrl+P Left"},
+		{"F10", []KeyPress{{Key: term.KeyF10}}, "F10"},
+		{"Alt+x", []KeyPress{{Ch: 'x', Mod: term.ModAlt}}, "Alt+x"},
+		{"Alt++", []KeyPress{{Ch: '+', Mod: term.ModAlt}}, "Alt++"},
+		{"Alt+PgDn", []KeyPress{{Key: term.KeyPgdn, Mod: term.ModAlt}}, "Alt+PgDn"},
+		{"Ctrl+Space", []KeyPress{{Key: term.KeyCtrlSpace}}, "Ctrl+Space"},
+		{"Ctrl+PgUp", []KeyPress{{Key: term.KeyPgup, Mod: ModCtrl}}, "Ctrl+PgUp"},
+		{"shift+alt+Tab", []KeyPress{{Key: term.KeyTab, Mod: term.ModAlt | ModShift}}, "Alt+Shift+Tab"},
+	}
+
+	for _, c := range cases {
+		keys, err := ParseHotkey(c.text)
+		if err != nil {
+			t.Errorf("Failed to parse '%s': %v", c.text, err)
+			continue
+		}
+		if !sameKeys(keys, c.keys) {
+			t.Errorf("'%s' parsed as %v instead of %v", c.text, keys, c.keys)
+		}
+		if s := HotkeyToString(keys); s != c.str {
+			t.Errorf("'%s' converted to '%s' instead of '%s'", c.text, s, c.str)
+		}
+	}
+
+	for _, text := range []string{"", "Shift+A", "Ctrl+Alt+Shift+1", "Ctrl+Unknown"} {
+		if _, err := ParseHotkey(text); err == nil {
+			t.Errorf("'%s' must fail", text)
+		}
+	}
+}
+
+func TestHotkeys(t *testing.T) {
+	initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+	defer ResetHotkeys()
+
+	wnd := AddWindow(5, 2, 20, 6, "Keys")
+	var got []KeyPress
+	wnd.OnKeyDown(func(ev Event, _ interface{}) bool {
+		got = append(got, keyPressFromEvent(ev))
+		return true
+	}, nil)
+
+	// default sticky sequence: Ctrl+P and then arrows
+	SimulateKey(term.KeyCtrlP, 0)
+	SimulateKey(term.KeyArrowRight, 0)
+	SimulateKey(term.KeyArrowRight, 0)
+	SimulateKey(term.KeyArrowDown, 0)
+	SimulateKey(0, 'a')
+	if x, y := wnd.Pos(); x != 7 || y != 3 {
+		t.Errorf("Window must be moved to 7:3 instead of %v:%v", x, y)
+	}
+	if len(got) != 1 || got[0] != (KeyPress{Ch: 'a'}) {
+		t.Errorf("Window must get only 'a' key: %v", got)
+	}
+
+	// disabled resize hotkeys free Ctrl+S for the application
+	got = nil
+	for _, a := range []HotkeyAction{HotkeyWindowShorter, HotkeyWindowTaller, HotkeyWindowNarrower, HotkeyWindowWider} {
+		DisableHotkey(a)
+	}
+	if IsDeadKey(term.KeyCtrlS) {
+		t.Error("Ctrl+S must not be a dead key")
+	}
+	SimulateKey(term.KeyCtrlS, 0)
+	if len(got) != 1 || got[0] != (KeyPress{Key: term.KeyCtrlS}) {
+		t.Errorf("Window must get Ctrl+S: %v", got)
+	}
+
+	// rebound action
+	keys, _ := ParseHotkey("Alt+h")
+	SetHotkey(HotkeyWindowMoveLeft, keys...)
+	SimulateEvent(Event{Type: EventKey, Ch: 'h', Mod: term.ModAlt})
+	if x, _ := wnd.Pos(); x != 6 {
+		t.Errorf("Window must be moved to 6 instead of %v", x)
+	}
+	if len(Hotkeys(HotkeyWindowMoveLeft)) != 1 {
+		t.Errorf("Action must have one hotkey: %v", Hotkeys(HotkeyWindowMoveLeft))
+	}
+
+	// not a hotkey: held back prefix goes to the window with the key
+	got = nil
+	SimulateKey(term.KeyCtrlW, 0)
+	SimulateKey(0, 'z')
+	if len(got) != 2 || got[0] != (KeyPress{Key: term.KeyCtrlW}) || got[1] != (KeyPress{Ch: 'z'}) {
+		t.Errorf("Window must get Ctrl+W and 'z': %v", got)
+	}
+
+	// global hotkey fires before the window
+	got = nil
+	fired := 0
+	keys, _ = ParseHotkey("Ctrl+K Ctrl+D")
+	AddGlobalHotkey(func(ev Event) bool {
+		fired++
+		return true
+	}, keys...)
+	keys, _ = ParseHotkey("F5")
+	AddGlobalHotkey(func(ev Event) bool {
+		fired++
+		return false
+	}, keys...)
+	SimulateKey(term.KeyCtrlK, 0)
+	SimulateKey(term.KeyCtrlD, 0)
+	SimulateKey(term.KeyF5, 0)
+	if fired != 2 {
+		t.Errorf("Global hotkeys must fire twice instead of %v", fired)
+	}
+	if len(got) != 1 || got[0] != (KeyPress{Key: term.KeyF5}) {
+		t.Errorf("Window must get only F5: %v", got)
+	}
+}
@@ -1,9 +1,5 @@
 package clui
 
-import (
-	xs "github.com/huandu/xstrings"
-)
-
 /*
 Label is a decorative control that can display text in horizontal
 or vertical direction. Other available text features are alignment
@@ -33,7 +29,7 @@ func CreateLabel(parent Control, w, h int, title string, scale int) *Label {
 	c.BaseControl = NewBaseControl()
 
 	if w == AutoSize {
-		w = xs.Len(title)
+		w = TextWidth(title)
 	}
 	if h == AutoSize {
 		h = 1
@@ -62,6 +58,7 @@ func (l *Label) Direction() Direction {
 
 // SetDirection sets the text output direction
 func (l *Label) SetDirection(dir Direction) {
+	l.Invalidate()
 	l.direction = dir
 }
 
@@ -70,17 +67,18 @@ func (l *Label) Draw() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
-	fg, bg := RealColor(l.fg, l.Style(), ColorText), RealColor(l.bg, l.Style(), ColorBack)
+	fg, bg := a.RealColor(l.fg, l.Style(), ColorText), a.RealColor(l.bg, l.Style(), ColorBack)
 	if !l.Enabled() {
-		fg = RealColor(l.fg, l.Style(), ColorDisabledText)
+		fg = a.RealColor(l.fg, l.Style(), ColorDisabledText)
 	}
 
-	SetTextColor(fg)
-	SetBackColor(bg)
-	FillRect(l.x, l.y, l.width, l.height, ' ')
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(l.x, l.y, l.width, l.height, ' ')
 
 	if l.title == "" {
 		return
@@ -99,9 +97,9 @@ func (l *Label) Draw() {
 				xx = l.x
 				yy += 1
 			} else if elem.Type == ElemPrintable {
-				SetTextColor(elem.Fg)
-				SetBackColor(elem.Bg)
-				putCharUnsafe(xx, yy, elem.Ch)
+				a.SetTextColor(elem.Fg)
+				a.SetBackColor(elem.Bg)
+				a.putCharUnsafe(xx, yy, elem.Ch)
 
 				if l.direction == Horizontal {
 					xx += 1
@@ -126,13 +124,13 @@ func (l *Label) Draw() {
 			if str != l.title && l.align != l.textDisplay {
 				shift, str = AlignColorizedText(l.title, l.width, l.textDisplay)
 			}
-			DrawText(l.x+shift, l.y, str)
+			a.DrawText(l.x+shift, l.y, str)
 		} else {
 			shift, str := AlignColorizedText(l.title, l.height, l.align)
 			if str != l.title && l.align != l.textDisplay {
 				shift, str = AlignColorizedText(l.title, l.width, l.textDisplay)
 			}
-			DrawTextVertical(l.x, l.y+shift, str)
+			a.DrawTextVertical(l.x, l.y+shift, str)
 		}
 	}
 }
@@ -147,6 +145,7 @@ func (l *Label) Multiline() bool {
 // SetMultiline sets if the label should output text as one line
 // or automatically display it in several lines
 func (l *Label) SetMultiline(multi bool) {
+	l.Invalidate()
 	l.multiline = multi
 }
 
@@ -164,6 +163,7 @@ func (l *Label) TextDisplay() Align {
 // for the property. Any other value does is skipped and does not affect
 // displaying the title
 func (l *Label) SetTextDisplay(align Align) {
+	l.Invalidate()
 	if align != AlignLeft && align != AlignRight {
 		return
 	}
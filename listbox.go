@@ -14,6 +14,10 @@ ListBox calls onSelectItem item function after a user changes currently
 selected item with mouse or using keyboard. Event structure has 2 fields filled:
 Y - selected item number in list(-1 if nothing is selected),
 Msg - text of the selected item.
+
+ListBox calls onActivateItem function after a user presses Enter or
+double clicks an item. Event structure fields are the same as for
+onSelectItem.
 */
 type ListBox struct {
 	BaseControl
@@ -23,8 +27,9 @@ type ListBox struct {
 	topLine       int
 	buttonPos     int
 
-	onSelectItem func(Event)
-	onKeyPress   func(term.Key) bool
+	onSelectItem   func(Event)
+	onActivateItem func(Event)
+	onKeyPress     func(term.Key) bool
 }
 
 /*
@@ -67,18 +72,20 @@ func CreateListBox(parent Control, width, height int, scale int) *ListBox {
 }
 
 func (l *ListBox) drawScroll() {
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	pos := ThumbPosition(l.currSelection, len(l.items), l.height)
 	l.buttonPos = pos
 
-	DrawScrollBar(l.x+l.width-1, l.y, 1, l.height, pos)
+	a.DrawScrollBar(l.x+l.width-1, l.y, 1, l.height, pos)
 }
 
 func (l *ListBox) drawItems() {
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	maxCurr := len(l.items) - 1
 	curr := l.topLine
@@ -86,11 +93,11 @@ func (l *ListBox) drawItems() {
 	maxDy := l.height - 1
 	maxWidth := l.width - 1
 
-	fg, bg := RealColor(l.fg, l.Style(), ColorEditText), RealColor(l.bg, l.Style(), ColorEditBack)
+	fg, bg := a.RealColor(l.fg, l.Style(), ColorEditText), a.RealColor(l.bg, l.Style(), ColorEditBack)
 	if l.Active() {
-		fg, bg = RealColor(l.fg, l.Style(), ColorEditActiveText), RealColor(l.bg, l.Style(), ColorEditActiveBack)
+		fg, bg = a.RealColor(l.fg, l.Style(), ColorEditActiveText), a.RealColor(l.bg, l.Style(), ColorEditActiveBack)
 	}
-	fgSel, bgSel := RealColor(l.fgActive, l.Style(), ColorSelectionText), RealColor(l.bgActive, l.Style(), ColorSelectionBack)
+	fgSel, bgSel := a.RealColor(l.fgActive, l.Style(), ColorSelectionText), a.RealColor(l.bgActive, l.Style(), ColorSelectionBack)
 
 	for curr <= maxCurr && dy <= maxDy {
 		f, b := fg, bg
@@ -98,11 +105,11 @@ func (l *ListBox) drawItems() {
 			f, b = fgSel, bgSel
 		}
 
-		SetTextColor(f)
-		SetBackColor(b)
-		FillRect(l.x, l.y+dy, l.width-1, 1, ' ')
+		a.SetTextColor(f)
+		a.SetBackColor(b)
+		a.FillRect(l.x, l.y+dy, l.width-1, 1, ' ')
 		str := SliceColorized(l.items[curr], 0, maxWidth)
-		DrawText(l.x, l.y+dy, str)
+		a.DrawText(l.x, l.y+dy, str)
 
 		curr++
 		dy++
@@ -115,19 +122,20 @@ func (l *ListBox) Draw() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	x, y := l.Pos()
 	w, h := l.Size()
 
-	fg, bg := RealColor(l.fg, l.Style(), ColorEditText), RealColor(l.bg, l.Style(), ColorEditBack)
+	fg, bg := a.RealColor(l.fg, l.Style(), ColorEditText), a.RealColor(l.bg, l.Style(), ColorEditBack)
 	if l.Active() {
-		fg, bg = RealColor(l.fg, l.Style(), ColorEditActiveText), RealColor(l.bg, l.Style(), ColorEditActiveBack)
+		fg, bg = a.RealColor(l.fg, l.Style(), ColorEditActiveText), a.RealColor(l.bg, l.Style(), ColorEditActiveBack)
 	}
-	SetTextColor(fg)
-	SetBackColor(bg)
-	FillRect(x, y, w, h, ' ')
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(x, y, w, h, ' ')
 	l.drawItems()
 	l.drawScroll()
 }
@@ -240,6 +248,7 @@ func (l *ListBox) EnsureVisible() {
 
 // Clear deletes all ListBox items
 func (l *ListBox) Clear() {
+	l.Invalidate()
 	l.items = make([]string, 0)
 	l.currSelection = -1
 	l.topLine = 0
@@ -281,9 +290,10 @@ func (l *ListBox) processMouseClick(ev Event) bool {
 	}
 
 	l.SelectItem(l.topLine + dy)
-	WindowManager().BeginUpdate()
+	a := AppOf(l)
+	a.WindowManager().BeginUpdate()
 	onSelFunc := l.onSelectItem
-	WindowManager().EndUpdate()
+	a.WindowManager().EndUpdate()
 	if onSelFunc != nil {
 		ev := Event{Y: l.topLine + dy, Msg: l.SelectedItemText()}
 		onSelFunc(ev)
@@ -292,6 +302,20 @@ func (l *ListBox) processMouseClick(ev Event) bool {
 	return true
 }
 
+// activateItem emits OnActivateItem event for the selected item
+func (l *ListBox) activateItem() {
+	if l.currSelection != -1 && l.onActivateItem != nil {
+		ev := Event{Y: l.currSelection, Msg: l.SelectedItemText()}
+		l.onActivateItem(ev)
+	}
+}
+
+// wheelScroll scrolls the list without changing the selected item
+func (l *ListBox) wheelScroll(dy int) bool {
+	l.topLine = clampScroll(l.topLine+dy, len(l.items)-l.height)
+	return true
+}
+
 func (l *ListBox) recalcPositionByScroll() {
 	newPos := ItemByThumbPosition(l.buttonPos, len(l.items), l.height)
 	if newPos < 1 {
@@ -309,6 +333,10 @@ that the control do not want or cannot process the event and the caller sends
 the event to the control parent
 */
 func (l *ListBox) ProcessEvent(event Event) bool {
+	if _, dy := WheelDelta(event); dy != 0 {
+		return l.Enabled() && l.wheelScroll(dy)
+	}
+
 	if !l.Active() || !l.Enabled() {
 		return false
 	}
@@ -346,11 +374,18 @@ func (l *ListBox) ProcessEvent(event Event) bool {
 				ev := Event{Y: l.currSelection, Msg: l.SelectedItemText()}
 				l.onSelectItem(ev)
 			}
+			l.activateItem()
 		default:
 			return false
 		}
 	case EventMouse:
 		return l.processMouseClick(event)
+	case EventDoubleClick:
+		// the first click has already selected the item
+		if event.X < l.width-1 && l.topLine+event.Y == l.currSelection {
+			l.activateItem()
+		}
+		return true
 	}
 
 	return false
@@ -361,6 +396,7 @@ func (l *ListBox) ProcessEvent(event Event) bool {
 // AddItem adds a new item to item list.
 // Returns true if the operation is successful
 func (l *ListBox) AddItem(item string) bool {
+	l.Invalidate()
 	l.items = append(l.items, item)
 	return true
 }
@@ -370,6 +406,7 @@ func (l *ListBox) AddItem(item string) bool {
 // make the item visible.
 // Returns true if the item is selected successfully
 func (l *ListBox) SelectItem(id int) bool {
+	l.Invalidate()
 	if len(l.items) <= id || id < 0 {
 		return false
 	}
@@ -444,6 +481,7 @@ func (l *ListBox) SelectedItemText() string {
 // RemoveItem deletes an item which number is id in item list
 // Returns true if item is deleted
 func (l *ListBox) RemoveItem(id int) bool {
+	l.Invalidate()
 	if id < 0 || id >= len(l.items) {
 		return false
 	}
@@ -458,6 +496,12 @@ func (l *ListBox) OnSelectItem(fn func(Event)) {
 	l.onSelectItem = fn
 }
 
+// OnActivateItem sets a callback that is called every time a user
+// presses Enter or double clicks the selected item, e.g to open it
+func (l *ListBox) OnActivateItem(fn func(Event)) {
+	l.onActivateItem = fn
+}
+
 // OnKeyPress sets the callback that is called when a user presses a Key while
 // the controls is active. If a handler processes the key it should return
 // true. If handler returns false it means that the default handler will
@@ -3,22 +3,53 @@ package clui
 import (
 	"log"
 	"os"
+	"sync"
 )
 
 var (
-	logger *log.Logger
+	logger    *log.Logger
+	loggerMtx sync.Mutex
 )
 
 func InitLogger() {
+	loggerMtx.Lock()
+	defer loggerMtx.Unlock()
+	initLogger()
+}
+
+func initLogger() {
 	file, _ := os.OpenFile("debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0666)
 	logger = log.New(file, "", log.Ldate|log.Ltime|log.Lshortfile)
 	logger.Printf("----------------------------------")
 }
 
 func Logger() *log.Logger {
+	loggerMtx.Lock()
+	defer loggerMtx.Unlock()
 	if logger == nil {
-		InitLogger()
+		initLogger()
 	}
 
 	return logger
 }
+
+// Logger returns the logger of the App. If SetLogger was not called, it
+// is the logger shared by all Apps(see Logger)
+func (a *App) Logger() *log.Logger {
+	a.loggerMtx.Lock()
+	l := a.logger
+	a.loggerMtx.Unlock()
+
+	if l == nil {
+		return Logger()
+	}
+	return l
+}
+
+// SetLogger makes the App write to its own logger, e.g to tell sessions
+// apart. nil restores the shared logger
+func (a *App) SetLogger(l *log.Logger) {
+	a.loggerMtx.Lock()
+	defer a.loggerMtx.Unlock()
+	a.logger = l
+}
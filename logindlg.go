@@ -42,12 +42,12 @@ type LoginDialog struct {
 //  credentials are not valid, then the dialog shows a warning. The warning
 //  automatically disappears when a user starts typing in Password or Username
 //  field.
-func CreateLoginDialog(title, userName string) *LoginDialog {
+func (a *App) CreateLoginDialog(title, userName string) *LoginDialog {
 	dlg := new(LoginDialog)
 
-	dlg.View = AddWindow(15, 8, 10, 4, title)
-	WindowManager().BeginUpdate()
-	defer WindowManager().EndUpdate()
+	dlg.View = a.AddWindow(15, 8, 10, 4, title)
+	a.comp.BeginUpdate()
+	defer a.comp.EndUpdate()
 
 	dlg.View.SetModal(true)
 	dlg.View.SetPack(Vertical)
@@ -78,11 +78,11 @@ func CreateLoginDialog(title, userName string) *LoginDialog {
 	btnCancel := CreateButton(blist, 10, 4, "Cancel", Fixed)
 
 	btnCancel.OnClick(func(ev Event) {
-		WindowManager().DestroyWindow(dlg.View)
-		WindowManager().BeginUpdate()
+		a.comp.DestroyWindow(dlg.View)
+		a.comp.BeginUpdate()
 		dlg.Action = LoginCanceled
 		closeFunc := dlg.onClose
-		WindowManager().EndUpdate()
+		a.comp.EndUpdate()
 		if closeFunc != nil {
 			closeFunc()
 		}
@@ -101,11 +101,11 @@ func CreateLoginDialog(title, userName string) *LoginDialog {
 			dlg.Password = edPass.Title()
 		}
 
-		WindowManager().DestroyWindow(dlg.View)
-		WindowManager().BeginUpdate()
+		a.comp.DestroyWindow(dlg.View)
+		a.comp.BeginUpdate()
 
 		closeFunc := dlg.onClose
-		WindowManager().EndUpdate()
+		a.comp.EndUpdate()
 		if closeFunc != nil {
 			closeFunc()
 		}
@@ -115,7 +115,7 @@ func CreateLoginDialog(title, userName string) *LoginDialog {
 		if dlg.result == DialogAlive {
 			dlg.result = DialogClosed
 			if ev.X != 1 {
-				WindowManager().DestroyWindow(dlg.View)
+				a.comp.DestroyWindow(dlg.View)
 			}
 			if dlg.onClose != nil {
 				dlg.onClose()
@@ -139,11 +139,17 @@ func CreateLoginDialog(title, userName string) *LoginDialog {
 	return dlg
 }
 
+// CreateLoginDialog calls App.CreateLoginDialog of the default App
+func CreateLoginDialog(title, userName string) *LoginDialog {
+	return defaultApp.CreateLoginDialog(title, userName)
+}
+
 // OnClose sets the callback that is called when the
 // dialog is closed
 func (d *LoginDialog) OnClose(fn func()) {
-	WindowManager().BeginUpdate()
-	defer WindowManager().EndUpdate()
+	a := AppOf(d.View)
+	a.comp.BeginUpdate()
+	defer a.comp.EndUpdate()
 	d.onClose = fn
 }
 
@@ -153,7 +159,8 @@ func (d *LoginDialog) OnClose(fn func()) {
 // the credentials are valid it returns true. That means the dialog can be
 // closed. If the callback returns false then the dialog remains on the screen.
 func (d *LoginDialog) OnCheck(fn func(string, string) bool) {
-	WindowManager().BeginUpdate()
-	defer WindowManager().EndUpdate()
+	a := AppOf(d.View)
+	a.comp.BeginUpdate()
+	defer a.comp.EndUpdate()
 	d.onCheck = fn
 }
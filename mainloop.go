@@ -1,63 +1,252 @@
 package clui
 
 import (
-	term "github.com/nsf/termbox-go"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // Composer is a service object that manages Views and console, processes
 // events, and provides service methods. One application must have only
 // one object of this type
 type mainLoop struct {
+	app *App
 	// a channel to communicate with View(e.g, Views send redraw event to this channel)
 	channel chan Event
+	// closures scheduled with Invoke, in order of scheduling
+	invokeQueue []func()
+	invokeMtx   sync.Mutex
+	// non-zero while MainLoop is processing events
+	running int32
+	// events read from the backend. Reading starts with the first
+	// MainLoop call and lasts until the library is deinitialized
+	events   chan Event
+	pollOnce sync.Once
+	// closed when the App is closed to stop reading events
+	quit      chan struct{}
+	closeOnce sync.Once
+	// the minimal interval between screen repaints and the time of the
+	// last repaint
+	frameTime time.Duration
+	lastFrame time.Time
+	frameMtx  sync.Mutex
 }
 
-var (
-	loop *mainLoop
-)
+func newMainLoop(a *App) *mainLoop {
+	l := new(mainLoop)
+	l.app = a
+	l.channel = make(chan Event)
+	l.quit = make(chan struct{})
+	l.frameTime = time.Second / DefaultFrameRate
+	return l
+}
 
-func initMainLoop() {
-	loop = new(mainLoop)
-	loop.channel = make(chan Event)
+// MainLoop starts the App event loop. It panics if the backend fails. Use
+// MainLoopContext to get the error instead
+func (a *App) MainLoop() {
+	if err := a.MainLoopContext(context.Background()); err != nil {
+		panic(err)
+	}
 }
 
-// MainLoop starts the main application event loop
-func MainLoop() {
-	RefreshScreen()
+// MainLoopContext starts the App event loop. The loop stops when the App
+// quits(e.g, after Stop call or after the last window is closed), when ctx
+// is cancelled, or when the backend fails. In the first case the function
+// returns nil, in the second one - ctx.Err(), and the backend error
+// otherwise.
+// The loop repaints invalidated areas of the screen not more often than
+// the frame rate allows(see SetFrameRate and InvalidateRect).
+// If a control event handler panics, the terminal is restored before the
+// panic goes further, so the panic message is readable
+func (a *App) MainLoopContext(ctx context.Context) (err error) {
+	l := a.loop
+	atomic.StoreInt32(&l.running, 1)
+	defer atomic.StoreInt32(&l.running, 0)
 
-	eventQueue := make(chan term.Event)
-	go func() {
-		for {
-			eventQueue <- term.PollEvent()
+	backend := a.backend
+	defer func() {
+		if r := recover(); r != nil {
+			backend.Close()
+			panic(r)
 		}
 	}()
 
+	a.InvalidateScreen()
+	l.paintFrame()
+
+	eventQueue := l.pollEvents(backend)
+
+	// the screen is repainted when the frame timer fires, so a burst of
+	// events causes only one repaint
+	var frame <-chan time.Time
 	for {
-		RefreshScreen()
+		if frame == nil && a.comp.needsRepaint() {
+			if delay := l.frameDelay(); delay > 0 {
+				frame = time.After(delay)
+			} else {
+				l.paintFrame()
+			}
+		}
 
 		select {
+		case <-frame:
+			frame = nil
+			l.paintFrame()
+		case <-ctx.Done():
+			return ctx.Err()
 		case ev := <-eventQueue:
-			switch ev.Type {
-			case term.EventError:
-				panic(ev.Err)
-			default:
-				ProcessEvent(termboxEventToLocal(ev))
+			if ev.Type == EventError {
+				return ev.Err
 			}
-		case cmd := <-loop.channel:
+			a.ProcessEvent(ev)
+		case cmd := <-l.channel:
 			if cmd.Type == EventQuit {
-				return
+				if a.comp.canQuit(cmd) {
+					return nil
+				}
+				continue
 			}
-			ProcessEvent(cmd)
+			a.ProcessEvent(cmd)
 		}
 	}
 }
 
-func _putEvent(ev Event) {
-	loop.channel <- ev
+// MainLoop calls App.MainLoop of the default App
+func MainLoop() {
+	defaultApp.MainLoop()
+}
+
+// MainLoopContext calls App.MainLoopContext of the default App
+func MainLoopContext(ctx context.Context) (err error) {
+	return defaultApp.MainLoopContext(ctx)
 }
 
-// PutEvent send event to a Composer directly.
-// Used by Views to ask for repainting or for quitting the application
+// pollEvents starts reading backend events in a separate goroutine. The
+// goroutine is shared by all MainLoop calls: PollEvent cannot be
+// interrupted, and a goroutine per call would steal events from the next one
+func (l *mainLoop) pollEvents(backend Backend) chan Event {
+	l.pollOnce.Do(func() {
+		events := make(chan Event)
+		l.events = events
+		go func() {
+			for {
+				ev := backend.PollEvent()
+				select {
+				case events <- ev:
+				case <-l.quit:
+					return
+				}
+			}
+		}()
+	})
+	return l.events
+}
+
+// close stops reading backend events after the backend is closed
+func (l *mainLoop) close() {
+	l.closeOnce.Do(func() {
+		close(l.quit)
+	})
+}
+
+func _putEvent(ch chan Event, ev Event) {
+	ch <- ev
+}
+
+// PutEvent send event to the App main loop directly. Used by Views to ask
+// for repainting or for quitting the application. It can be called from
+// any goroutine
+func (a *App) PutEvent(ev Event) {
+	a.loop.put(ev)
+}
+
+// PutEvent calls App.PutEvent of the default App
 func PutEvent(ev Event) {
-	go _putEvent(ev)
+	defaultApp.PutEvent(ev)
+}
+
+func (l *mainLoop) put(ev Event) {
+	go _putEvent(l.channel, ev)
+}
+
+// Invoke schedules fn to be called on the main loop goroutine and returns
+// immediately. Closures are called in the same order they are scheduled,
+// and the areas they invalidate are repainted after that(see
+// InvalidateRect). Controls and Composer are not
+// thread safe, so this is the way to update UI from other goroutines:
+//
+//	go func() {
+//	    data := longOperation()
+//	    app.Invoke(func() {
+//	        textView.AddText(data)
+//	    })
+//	}()
+//
+// Invoke can be called from any goroutine including the main loop one
+func (a *App) Invoke(fn func()) {
+	a.loop.invoke(fn)
+}
+
+// Invoke calls App.Invoke of the default App
+func Invoke(fn func()) {
+	defaultApp.Invoke(fn)
+}
+
+func (l *mainLoop) invoke(fn func()) {
+	if fn == nil {
+		return
+	}
+
+	l.invokeMtx.Lock()
+	l.invokeQueue = append(l.invokeQueue, fn)
+	wakeUp := len(l.invokeQueue) == 1
+	l.invokeMtx.Unlock()
+
+	if wakeUp {
+		l.put(Event{Type: EventInvoke})
+	}
+}
+
+// InvokeSync does the same as Invoke but waits until fn finishes. If
+// MainLoop is not running, fn is called immediately.
+// Note: do not call InvokeSync from the main loop goroutine (e.g, from
+// a control event handler) - it never returns. Use Invoke instead
+func (a *App) InvokeSync(fn func()) {
+	if fn != nil && atomic.LoadInt32(&a.loop.running) == 0 {
+		fn()
+		return
+	}
+	a.loop.invokeSync(fn)
+}
+
+// InvokeSync calls App.InvokeSync of the default App
+func InvokeSync(fn func()) {
+	defaultApp.InvokeSync(fn)
+}
+
+// invokeSync schedules fn and waits until the running main loop calls it
+func (l *mainLoop) invokeSync(fn func()) {
+	if fn == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	l.invoke(func() {
+		defer close(done)
+		fn()
+	})
+	<-done
+}
+
+// runInvoked calls all closures scheduled with Invoke
+func (l *mainLoop) runInvoked() {
+	l.invokeMtx.Lock()
+	queue := l.invokeQueue
+	l.invokeQueue = nil
+	l.invokeMtx.Unlock()
+
+	for _, fn := range queue {
+		fn()
+	}
 }
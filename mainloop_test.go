@@ -0,0 +1,158 @@
+package clui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInvoke(t *testing.T) {
+	initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 30, 10, "Invoke")
+	tv := CreateTextView(wnd, 20, 5, 1)
+
+	done := make(chan struct{})
+	go func() {
+		MainLoop()
+		close(done)
+	}()
+
+	const workers, lines = 4, 25
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				line := fmt.Sprintf("%v:%v", id, i)
+				Invoke(func() {
+					tv.AddText([]string{line})
+				})
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	var count int
+	InvokeSync(func() {
+		count = tv.ItemCount()
+	})
+	if count != workers*lines {
+		t.Errorf("TextView must have %v lines instead of %v", workers*lines, count)
+	}
+
+	order := make([]int, 0)
+	for i := 0; i < 10; i++ {
+		n := i
+		Invoke(func() {
+			order = append(order, n)
+		})
+	}
+	InvokeSync(func() {})
+	for i, n := range order {
+		if i != n {
+			t.Errorf("Invoked closures are called out of order: %v", order)
+			break
+		}
+	}
+
+	Stop()
+	<-done
+}
+
+func runLoop(ctx context.Context) chan error {
+	res := make(chan error, 1)
+	go func() {
+		res <- MainLoopContext(ctx)
+	}()
+	return res
+}
+
+func waitLoop(t *testing.T, res chan error) error {
+	t.Helper()
+	select {
+	case err := <-res:
+		return err
+	case <-time.After(time.Second):
+		t.Fatal("MainLoopContext did not stop")
+	}
+	return nil
+}
+
+func TestMainLoopContext(t *testing.T) {
+	b := initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	res := runLoop(ctx)
+	cancel()
+	if err := waitLoop(t, res); err != context.Canceled {
+		t.Errorf("Cancelled loop must return context.Canceled instead of %v", err)
+	}
+
+	failure := errors.New("terminal failure")
+	res = runLoop(context.Background())
+	b.PostEvent(Event{Type: EventError, Err: failure})
+	if err := waitLoop(t, res); err != failure {
+		t.Errorf("Loop must return backend error instead of %v", err)
+	}
+
+	wnd := AddWindow(0, 0, 20, 5, "Quit")
+	asked := make(chan struct{}, 1)
+	wnd.OnQuit(func(ev Event) bool {
+		asked <- struct{}{}
+		return false
+	})
+	res = runLoop(context.Background())
+	Stop()
+	<-asked
+	InvokeSync(func() {
+		wnd.OnQuit(nil)
+	})
+	select {
+	case err := <-res:
+		t.Fatalf("Window must veto quitting, loop returned %v", err)
+	default:
+	}
+	Stop()
+	if err := waitLoop(t, res); err != nil {
+		t.Errorf("Loop must return nil after Stop instead of %v", err)
+	}
+}
+
+type closeTracker struct {
+	*HeadlessBackend
+	closed chan struct{}
+}
+
+func (b *closeTracker) Close() {
+	close(b.closed)
+}
+
+func TestMainLoopPanicRestore(t *testing.T) {
+	b := &closeTracker{NewHeadlessBackend(40, 12), make(chan struct{})}
+	if !InitLibrary(b) {
+		t.Fatal("Failed to initialize library")
+	}
+
+	defer func() {
+		if r := recover(); r != "handler failure" {
+			t.Errorf("Panic must be passed to the caller, got %v", r)
+		}
+		select {
+		case <-b.closed:
+		default:
+			t.Error("Backend must be closed after panic")
+		}
+	}()
+
+	Invoke(func() {
+		panic("handler failure")
+	})
+	MainLoopContext(context.Background())
+}
@@ -0,0 +1,897 @@
+package clui
+
+import (
+	"strings"
+
+	term "github.com/nsf/termbox-go"
+)
+
+/*
+MenuItem is an item of a Menu or a MenuBar. An item can run a callback,
+open a submenu, or be a separator line. A character after '&' in the item
+title is the item hotkey: it is underlined, and pressing it selects the item
+when the menu is open. Use "&&" to display '&'.
+A checkable item toggles its state every time it is clicked. An accelerator
+is a global hotkey that clicks the item even if the menu is closed. The
+accelerator works in the App of the menu owners: the menu bar(see
+SetMenuBar) or controls(see SetPopupMenu). It is removed when the item is
+removed with Menu.Clear, or when its menu is detached from the last owner.
+*/
+type MenuItem struct {
+	title     string
+	text      string
+	hotkeyPos int
+	accel     []KeyPress
+	// the App of the menu owners, the accelerator is registered in its
+	// hotkeys. nil if the menu has no owners
+	app       *App
+	checkable bool
+	checked   bool
+	disabled  bool
+	separator bool
+	submenu   *Menu
+	onClick   func(Event)
+}
+
+/*
+Menu is a list of items that is displayed as a drop-down of MenuBar, as
+a submenu of another Menu, or as a popup menu at any screen position.
+Menus are not windows: Composer draws open menus over all windows and
+sends all keyboard and mouse events to them until the menus are closed.
+*/
+type Menu struct {
+	items []*MenuItem
+	// position and size of the open menu
+	x, y          int
+	width, height int
+	current       int
+	// number of menu bars, controls and parent menus the menu is attached
+	// to, and their App
+	owners int
+	app    *App
+}
+
+/*
+MenuBar is a line of menu titles at the top of the screen. The menu bar is
+managed by Composer outside window stacking: it is always drawn over windows
+and the screen row it takes is excluded from the area available for windows
+(see WorkArea). F10(see HotkeyMenuBar) or Alt+title hotkey activates the menu
+bar, mouse click on a title opens its menu.
+*/
+type MenuBar struct {
+	items []*MenuItem
+	// the App that displays the menu bar
+	app *App
+}
+
+// NewMenu creates an empty menu
+func NewMenu() *Menu {
+	return new(Menu)
+}
+
+// NewMenuBar creates an empty menu bar. Call SetMenuBar to display it
+func NewMenuBar() *MenuBar {
+	return new(MenuBar)
+}
+
+func newMenuItem(title string) *MenuItem {
+	item := new(MenuItem)
+	item.SetTitle(title)
+	return item
+}
+
+// parseMenuTitle removes hotkey marker from the title and returns the
+// position of the hotkey rune in the resulting text(-1 if there is no hotkey)
+func parseMenuTitle(title string) (string, int) {
+	var sb strings.Builder
+	pos, idx := -1, 0
+	runes := []rune(title)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '&' && i+1 < len(runes) {
+			i++
+			if runes[i] != '&' && pos == -1 {
+				pos = idx
+			}
+		}
+		sb.WriteRune(runes[i])
+		idx++
+	}
+
+	return sb.String(), pos
+}
+
+// Title returns the item title including hotkey marker
+func (item *MenuItem) Title() string {
+	return item.title
+}
+
+// SetTitle changes the item title. A character after '&' is the item hotkey
+func (item *MenuItem) SetTitle(title string) {
+	item.title = title
+	item.text, item.hotkeyPos = parseMenuTitle(title)
+}
+
+func (item *MenuItem) hotkey() rune {
+	if item.hotkeyPos < 0 {
+		return 0
+	}
+	return []rune(strings.ToLower(item.text))[item.hotkeyPos]
+}
+
+// Enabled returns true if a user can click the item
+func (item *MenuItem) Enabled() bool {
+	return !item.disabled
+}
+
+// SetEnabled enables or disables the item. Disabled item is grayed out and
+// cannot be clicked with mouse, keyboard or accelerator
+func (item *MenuItem) SetEnabled(enabled bool) {
+	item.disabled = !enabled
+}
+
+// Checkable returns true if the item toggles its checked state on click
+func (item *MenuItem) Checkable() bool {
+	return item.checkable
+}
+
+// SetCheckable makes the item toggle its checked state on every click
+func (item *MenuItem) SetCheckable(checkable bool) {
+	item.checkable = checkable
+}
+
+// Checked returns true if the item is checked
+func (item *MenuItem) Checked() bool {
+	return item.checked
+}
+
+// SetChecked changes the check mark of the item. It does not make the item
+// checkable, so the state is not toggled by clicks
+func (item *MenuItem) SetChecked(checked bool) {
+	item.checked = checked
+}
+
+// Submenu returns the menu that the item opens or nil
+func (item *MenuItem) Submenu() *Menu {
+	return item.submenu
+}
+
+// OnClick sets the callback that is called when a user clicks the item.
+// For checkable items the checked state is already changed when the
+// callback is called
+func (item *MenuItem) OnClick(fn func(Event)) {
+	item.onClick = fn
+}
+
+// Accelerator returns the text description of the item global hotkey
+func (item *MenuItem) Accelerator() string {
+	return HotkeyToString(item.accel)
+}
+
+// SetAccelerator assigns a global hotkey to the item, e.g "Ctrl+O" or
+// "F3". The hotkey clicks the item while it is enabled and the hotkey text
+// is displayed to the right of the item title. Empty string removes the
+// accelerator
+func (item *MenuItem) SetAccelerator(hotkey string) error {
+	var keys []KeyPress
+	if hotkey != "" {
+		var err error
+		if keys, err = ParseHotkey(hotkey); err != nil {
+			return err
+		}
+	}
+
+	a := item.app
+	item.setApp(nil)
+	item.accel = keys
+	item.setApp(a)
+
+	return nil
+}
+
+// setApp moves the item global hotkey to the hotkeys of the App. nil App
+// removes the hotkey
+func (item *MenuItem) setApp(a *App) {
+	if a == item.app {
+		return
+	}
+
+	if item.app != nil && len(item.accel) != 0 {
+		item.app.hotkeys.remove(item.accel)
+	}
+	item.app = a
+	if a == nil || len(item.accel) == 0 {
+		return
+	}
+	a.hotkeys.addGlobal(func(ev Event) bool {
+		if item.disabled || item.separator {
+			return false
+		}
+		item.click()
+		return true
+	}, item.accel)
+}
+
+func (item *MenuItem) click() {
+	if item.checkable {
+		item.checked = !item.checked
+	}
+	if item.onClick != nil {
+		item.onClick(Event{Type: EventClick, Msg: item.text})
+	}
+}
+
+func (item *MenuItem) selectable() bool {
+	return !item.separator && !item.disabled
+}
+
+// AddItem appends a new item to the menu. fn is called when the item
+// is clicked, it can be nil
+func (m *Menu) AddItem(title string, fn func(Event)) *MenuItem {
+	item := newMenuItem(title)
+	item.onClick = fn
+	item.app = m.app
+	m.items = append(m.items, item)
+	return item
+}
+
+// AddSubmenu appends an item that opens another menu
+func (m *Menu) AddSubmenu(title string, submenu *Menu) *MenuItem {
+	item := newMenuItem(title)
+	item.submenu = submenu
+	item.app = m.app
+	m.items = append(m.items, item)
+	for i := 0; i < m.owners; i++ {
+		submenu.attach(m.app)
+	}
+	return item
+}
+
+// AddSeparator appends a horizontal line to the menu
+func (m *Menu) AddSeparator() {
+	item := new(MenuItem)
+	item.separator = true
+	item.hotkeyPos = -1
+	m.items = append(m.items, item)
+}
+
+// Items returns all menu items including separators
+func (m *Menu) Items() []*MenuItem {
+	return m.items
+}
+
+// Clear removes all items from the menu and their accelerators including
+// accelerators of submenus
+func (m *Menu) Clear() {
+	for _, item := range m.items {
+		item.setApp(nil)
+		if sub := item.submenu; sub != nil {
+			for i := 0; i < m.owners; i++ {
+				sub.detach()
+			}
+			if sub.owners == 0 {
+				sub.release()
+			}
+		}
+	}
+	m.items = nil
+}
+
+// release removes accelerators of the menu and its submenus that have no
+// owners
+func (m *Menu) release() {
+	for _, item := range m.items {
+		item.setApp(nil)
+		if item.submenu != nil && item.submenu.owners == 0 {
+			item.submenu.release()
+		}
+	}
+}
+
+// attach registers accelerators of the menu and its submenus in the App
+// when the menu gets a new owner
+func (m *Menu) attach(a *App) {
+	if m == nil {
+		return
+	}
+
+	m.owners++
+	m.app = a
+	for _, item := range m.items {
+		item.setApp(a)
+		item.submenu.attach(a)
+	}
+}
+
+// detach removes accelerators of the menu and its submenus when the menu
+// loses the last owner
+func (m *Menu) detach() {
+	if m == nil || m.owners == 0 {
+		return
+	}
+
+	m.owners--
+	if m.owners == 0 {
+		m.app = nil
+	}
+	for _, item := range m.items {
+		item.setApp(m.app)
+		item.submenu.detach()
+	}
+}
+
+func (m *Menu) hasChecks() bool {
+	for _, item := range m.items {
+		if item.checkable || item.checked {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Menu) hasSubmenus() bool {
+	for _, item := range m.items {
+		if item.submenu != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// calcSize calculates the menu size and places the menu at given screen
+// coordinates so it fits the screen
+func (m *Menu) calcSize(a *App, x, y int) {
+	textW, accelW := 0, 0
+	for _, item := range m.items {
+		if l := TextWidth(item.text); l > textW {
+			textW = l
+		}
+		if l := TextWidth(item.Accelerator()); l > accelW {
+			accelW = l
+		}
+	}
+
+	// borders and spaces around the text
+	m.width = textW + 4
+	if accelW != 0 {
+		m.width += accelW + 2
+	}
+	if m.hasChecks() {
+		m.width += 2
+	}
+	if m.hasSubmenus() {
+		m.width += 2
+	}
+	m.height = len(m.items) + 2
+
+	sw, sh := a.ScreenSize()
+	if x+m.width > sw {
+		x = sw - m.width
+	}
+	if y+m.height > sh {
+		y = sh - m.height
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y < 0 {
+		y = 0
+	}
+	m.x, m.y = x, y
+
+	m.current = -1
+	m.moveCurrent(1)
+}
+
+// moveCurrent selects the next(dir is 1) or previous(dir is -1) selectable
+// item. Selection wraps around
+func (m *Menu) moveCurrent(dir int) {
+	cnt := len(m.items)
+	idx := m.current
+	for i := 0; i < cnt; i++ {
+		idx += dir
+		if idx >= cnt {
+			idx = 0
+		} else if idx < 0 {
+			idx = cnt - 1
+		}
+		if m.items[idx].selectable() {
+			m.current = idx
+			return
+		}
+	}
+}
+
+func (m *Menu) itemByHotkey(ch rune) int {
+	ch = []rune(strings.ToLower(string(ch)))[0]
+	for i, item := range m.items {
+		if item.selectable() && item.hotkey() == ch {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *Menu) inside(x, y int) bool {
+	return x >= m.x && x < m.x+m.width && y >= m.y && y < m.y+m.height
+}
+
+// itemAt returns the index of the item at screen row y or -1
+func (m *Menu) itemAt(y int) int {
+	idx := y - m.y - 1
+	if idx < 0 || idx >= len(m.items) {
+		return -1
+	}
+	return idx
+}
+
+// drawMenuText draws a text with underlined hotkey rune
+func drawMenuText(a *App, x, y int, text string, hotkeyPos int, fg term.Attribute) {
+	for i, r := range []rune(text) {
+		if i == hotkeyPos {
+			a.SetTextColor(fg | term.AttrUnderline)
+		} else {
+			a.SetTextColor(fg)
+		}
+		a.PutChar(x, y, r)
+		x++
+	}
+	a.SetTextColor(fg)
+}
+
+func (m *Menu) draw(a *App) {
+	a.PushAttributes()
+	defer a.PopAttributes()
+
+	chars := []rune(a.SysObject(ObjMenu))
+	cLine, cLeft, cRight, cCheck, cSub := chars[0], chars[1], chars[2], chars[3], chars[4]
+
+	fg, bg := a.SysColor(ColorMenuText), a.SysColor(ColorMenuBack)
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(m.x, m.y, m.width, m.height, ' ')
+	a.DrawFrame(m.x, m.y, m.width, m.height, BorderThin)
+
+	checks, subs := m.hasChecks(), m.hasSubmenus()
+	for idx, item := range m.items {
+		y := m.y + 1 + idx
+		if item.separator {
+			a.SetTextColor(fg)
+			a.SetBackColor(bg)
+			a.DrawHorizontalLine(m.x+1, y, m.width-2, cLine)
+			a.PutChar(m.x, y, cLeft)
+			a.PutChar(m.x+m.width-1, y, cRight)
+			continue
+		}
+
+		itemFg, itemBg := fg, bg
+		if idx == m.current {
+			itemFg, itemBg = a.SysColor(ColorMenuActiveText), a.SysColor(ColorMenuActiveBack)
+		}
+		if item.disabled {
+			itemFg = a.SysColor(ColorMenuDisabledText)
+		}
+		a.SetTextColor(itemFg)
+		a.SetBackColor(itemBg)
+		a.DrawHorizontalLine(m.x+1, y, m.width-2, ' ')
+
+		x := m.x + 2
+		if checks {
+			if item.checked {
+				a.PutChar(x, y, cCheck)
+			}
+			x += 2
+		}
+		drawMenuText(a, x, y, item.text, item.hotkeyPos, itemFg)
+
+		right := m.x + m.width - 2
+		if subs {
+			if item.submenu != nil {
+				a.PutChar(right, y, cSub)
+			}
+			right -= 2
+		}
+		if accel := item.Accelerator(); accel != "" {
+			a.DrawRawText(right-TextWidth(accel)+1, y, accel)
+		}
+	}
+}
+
+// AddMenu appends a menu title to the menu bar. Clicking the title opens
+// the menu. If menu is nil, the title works like a button: use OnClick of
+// the returned item to set its callback
+func (b *MenuBar) AddMenu(title string, menu *Menu) *MenuItem {
+	item := newMenuItem(title)
+	item.submenu = menu
+	item.app = b.app
+	b.items = append(b.items, item)
+	if b.app != nil {
+		menu.attach(b.app)
+	}
+	return item
+}
+
+// Items returns all menu bar items
+func (b *MenuBar) Items() []*MenuItem {
+	return b.items
+}
+
+// attach registers accelerators of the menu bar and its menus in the App
+// that displays the menu bar. nil App removes them
+func (b *MenuBar) attach(a *App) {
+	if b == nil || b.app == a {
+		return
+	}
+
+	b.app = a
+	for _, item := range b.items {
+		item.setApp(a)
+		if a != nil {
+			item.submenu.attach(a)
+		} else {
+			item.submenu.detach()
+		}
+	}
+}
+
+// itemPos returns screen column and width of a menu bar item
+func (b *MenuBar) itemPos(idx int) (int, int) {
+	x := 1
+	for i, item := range b.items {
+		w := TextWidth(item.text) + 2
+		if i == idx {
+			return x, w
+		}
+		x += w
+	}
+	return x, 0
+}
+
+func (b *MenuBar) itemAt(x int) int {
+	for i := range b.items {
+		pos, w := b.itemPos(i)
+		if x >= pos && x < pos+w {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *MenuBar) itemByHotkey(ch rune) int {
+	ch = []rune(strings.ToLower(string(ch)))[0]
+	for i, item := range b.items {
+		if !item.disabled && item.hotkey() == ch {
+			return i
+		}
+	}
+	return -1
+}
+
+func (b *MenuBar) draw(a *App, selected int) {
+	a.PushAttributes()
+	defer a.PopAttributes()
+
+	fg, bg := a.SysColor(ColorMenuText), a.SysColor(ColorMenuBack)
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	sw, _ := a.ScreenSize()
+	a.FillRect(0, 0, sw, 1, ' ')
+
+	for idx, item := range b.items {
+		x, w := b.itemPos(idx)
+		itemFg, itemBg := fg, bg
+		if idx == selected {
+			itemFg, itemBg = a.SysColor(ColorMenuActiveText), a.SysColor(ColorMenuActiveBack)
+		}
+		if item.disabled {
+			itemFg = a.SysColor(ColorMenuDisabledText)
+		}
+		a.SetBackColor(itemBg)
+		a.SetTextColor(itemFg)
+		a.FillRect(x, 0, w, 1, ' ')
+		drawMenuText(a, x+1, 0, item.text, item.hotkeyPos, itemFg)
+	}
+}
+
+// SetMenuBar displays the menu bar at the top of the screen. nil removes
+// the current menu bar. Windows that are maximized or overlap the menu bar
+// are moved to the area below it. Accelerators of the previous menu bar are
+// removed. A menu bar can be displayed by only one App at a time
+func (a *App) SetMenuBar(bar *MenuBar) {
+	c := a.comp
+	c.closeMenus()
+	if c.menuBar != bar {
+		c.menuBar.attach(nil)
+		bar.attach(a)
+	}
+	c.menuBar = bar
+	c.fitWindowsToWorkArea()
+	a.InvalidateScreen()
+}
+
+// ShowPopupMenu opens the menu at given screen coordinates, e.g at mouse
+// cursor position. The menu is moved if it does not fit the screen
+func (a *App) ShowPopupMenu(menu *Menu, x, y int) {
+	if menu == nil || len(menu.items) == 0 {
+		return
+	}
+
+	c := a.comp
+	c.closeMenus()
+	c.barItem = -1
+	c.openMenu(menu, x, y)
+	a.InvalidateScreen()
+}
+
+// CloseMenus closes all open menus and deactivates the menu bar
+func (a *App) CloseMenus() {
+	a.comp.closeMenus()
+	a.InvalidateScreen()
+}
+
+// WorkArea returns the part of the screen available for windows: the whole
+// screen except rows taken by the menu bar and the status bar
+func (a *App) WorkArea() (x, y, width, height int) {
+	return a.comp.workArea()
+}
+
+// SetMenuBar calls App.SetMenuBar of the default App
+func SetMenuBar(bar *MenuBar) {
+	defaultApp.SetMenuBar(bar)
+}
+
+// ShowPopupMenu calls App.ShowPopupMenu of the default App
+func ShowPopupMenu(menu *Menu, x, y int) {
+	defaultApp.ShowPopupMenu(menu, x, y)
+}
+
+// CloseMenus calls App.CloseMenus of the default App
+func CloseMenus() {
+	defaultApp.CloseMenus()
+}
+
+// WorkArea calls App.WorkArea of the default App
+func WorkArea() (x, y, width, height int) {
+	return defaultApp.WorkArea()
+}
+
+func (c *Composer) workArea() (x, y, width, height int) {
+	width, height = c.app.ScreenSize()
+	if c.menuBar != nil {
+		y++
+		height--
+	}
+	if c.statusBar != nil {
+		height--
+	}
+	return x, y, width, height
+}
+
+// fitWindowsToWorkArea resizes maximized windows and moves down windows
+// that are above the work area
+func (c *Composer) fitWindowsToWorkArea() {
+	x, y, w, h := c.workArea()
+	for _, ctrl := range c.getWindowList() {
+		wnd := ctrl.(*Window)
+		if wnd.Maximized() {
+			wnd.SetPos(x, y)
+			wnd.SetSize(w, h)
+			wnd.ResizeChildren()
+			wnd.PlaceChildren()
+		} else if wx, wy := wnd.Pos(); wy < y {
+			wnd.SetPos(wx, y)
+			wnd.PlaceChildren()
+		}
+	}
+}
+
+func (c *Composer) menuActive() bool {
+	return len(c.menus) != 0 || c.barItem >= 0
+}
+
+func (c *Composer) closeMenus() {
+	c.menus = nil
+	c.barItem = -1
+}
+
+func (c *Composer) openMenu(menu *Menu, x, y int) {
+	menu.calcSize(c.app, x, y)
+	c.menus = append(c.menus, menu)
+}
+
+// selectBarItem highlights the menu bar item. If open is true the item menu
+// is opened or the item is clicked if it does not have menu
+func (c *Composer) selectBarItem(idx int, open bool) {
+	cnt := len(c.menuBar.items)
+	if cnt == 0 {
+		c.closeMenus()
+		return
+	}
+	if idx < 0 {
+		idx = cnt - 1
+	} else if idx >= cnt {
+		idx = 0
+	}
+
+	c.menus = nil
+	c.barItem = idx
+	item := c.menuBar.items[idx]
+	if !open || item.disabled {
+		return
+	}
+
+	if item.submenu == nil {
+		c.closeMenus()
+		item.click()
+		return
+	}
+	if len(item.submenu.items) != 0 {
+		x, _ := c.menuBar.itemPos(idx)
+		c.openMenu(item.submenu, x, 1)
+	}
+}
+
+// activateMenuItem opens the submenu of the item or clicks it
+func (c *Composer) activateMenuItem(level, idx int) {
+	m := c.menus[level]
+	if idx < 0 || idx >= len(m.items) || !m.items[idx].selectable() {
+		return
+	}
+
+	m.current = idx
+	c.menus = c.menus[:level+1]
+	item := m.items[idx]
+	if item.submenu != nil {
+		if len(item.submenu.items) != 0 {
+			c.openMenu(item.submenu, m.x+m.width-1, m.y+idx)
+		}
+		return
+	}
+
+	c.closeMenus()
+	item.click()
+}
+
+// drawOverlays draws everything that is managed by Composer outside
+// window stacking over windows
+func (c *Composer) drawOverlays() {
+	c.drawStatusBar()
+	c.drawDropDown()
+	c.drawMenus()
+	c.drawTooltip()
+}
+
+func (c *Composer) drawMenus() {
+	if c.menuBar == nil && len(c.menus) == 0 {
+		return
+	}
+
+	c.app.PushClip()
+	defer c.app.PopClip()
+	sw, sh := c.app.ScreenSize()
+	c.app.SetClipRect(0, 0, sw, sh)
+
+	if c.menuBar != nil {
+		c.menuBar.draw(c.app, c.barItem)
+	}
+	for _, m := range c.menus {
+		m.draw(c.app)
+	}
+}
+
+// processMenuKey handles keyboard while a menu is open. Returns true if
+// the key must not be processed further
+func (c *Composer) processMenuKey(ev Event) bool {
+	if !c.menuActive() {
+		if c.menuBar != nil && ev.Mod&term.ModAlt != 0 && ev.Ch != 0 {
+			if idx := c.menuBar.itemByHotkey(ev.Ch); idx != -1 {
+				c.selectBarItem(idx, true)
+				c.app.InvalidateScreen()
+				return true
+			}
+		}
+		return false
+	}
+
+	defer c.app.InvalidateScreen()
+
+	if len(c.menus) == 0 {
+		switch ev.Key {
+		case term.KeyEsc:
+			c.closeMenus()
+		case term.KeyArrowLeft:
+			c.selectBarItem(c.barItem-1, false)
+		case term.KeyArrowRight:
+			c.selectBarItem(c.barItem+1, false)
+		case term.KeyEnter, term.KeySpace, term.KeyArrowDown:
+			c.selectBarItem(c.barItem, true)
+		default:
+			if ev.Ch != 0 {
+				if idx := c.menuBar.itemByHotkey(ev.Ch); idx != -1 {
+					c.selectBarItem(idx, true)
+				}
+			}
+		}
+		return true
+	}
+
+	level := len(c.menus) - 1
+	m := c.menus[level]
+	switch ev.Key {
+	case term.KeyEsc:
+		c.menus = c.menus[:level]
+		if len(c.menus) == 0 && c.barItem < 0 {
+			c.closeMenus()
+		}
+	case term.KeyArrowUp:
+		m.moveCurrent(-1)
+	case term.KeyArrowDown:
+		m.moveCurrent(1)
+	case term.KeyHome:
+		m.current = -1
+		m.moveCurrent(1)
+	case term.KeyEnd:
+		m.current = len(m.items)
+		m.moveCurrent(-1)
+	case term.KeyArrowLeft:
+		if level > 0 {
+			c.menus = c.menus[:level]
+		} else if c.barItem >= 0 {
+			c.selectBarItem(c.barItem-1, true)
+		}
+	case term.KeyArrowRight:
+		if m.current >= 0 && m.items[m.current].submenu != nil {
+			c.activateMenuItem(level, m.current)
+		} else if c.barItem >= 0 {
+			c.selectBarItem(c.barItem+1, true)
+		}
+	case term.KeyEnter, term.KeySpace:
+		c.activateMenuItem(level, m.current)
+	default:
+		if ev.Ch != 0 {
+			c.activateMenuItem(level, m.itemByHotkey(ev.Ch))
+		}
+	}
+
+	return true
+}
+
+// processMenuMouse handles mouse clicks on the menu bar and open menus.
+// Returns true if the event must not be processed further
+func (c *Composer) processMenuMouse(ev Event) bool {
+	if c.menuBar != nil && ev.Y == 0 && ev.Key == term.MouseLeft && ev.Mod != term.ModMotion {
+		idx := c.menuBar.itemAt(ev.X)
+		if idx == -1 || (idx == c.barItem && len(c.menus) != 0) {
+			c.closeMenus()
+		} else {
+			c.selectBarItem(idx, true)
+		}
+		c.app.InvalidateScreen()
+		return true
+	}
+
+	if !c.menuActive() {
+		return false
+	}
+
+	if ev.Mod == term.ModMotion || ev.Key == term.MouseRelease {
+		return true
+	}
+
+	for level := len(c.menus) - 1; level >= 0; level-- {
+		m := c.menus[level]
+		if m.inside(ev.X, ev.Y) {
+			if ev.Key == term.MouseLeft {
+				c.activateMenuItem(level, m.itemAt(ev.Y))
+				c.app.InvalidateScreen()
+			}
+			return true
+		}
+	}
+
+	// a click outside menus closes them
+	if ev.Key == term.MouseLeft || ev.Key == term.MouseRight || ev.Key == term.MouseMiddle {
+		c.closeMenus()
+		c.app.InvalidateScreen()
+	}
+	return true
+}
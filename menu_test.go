@@ -0,0 +1,191 @@
+package clui
+
+import (
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestParseMenuTitle(t *testing.T) {
+	cases := []struct {
+		title string
+		text  string
+		pos   int
+	}{
+		{"&File", "File", 0},
+		{"Save &as", "Save as", 5},
+		{"R&&D", "R&D", -1},
+		{"No hotkey", "No hotkey", -1},
+		{"Tail&", "Tail&", -1},
+	}
+
+	for _, c := range cases {
+		text, pos := parseMenuTitle(c.title)
+		if text != c.text || pos != c.pos {
+			t.Errorf("'%s' parsed as '%s':%v instead of '%s':%v", c.title, text, pos, c.text, c.pos)
+		}
+	}
+}
+
+func TestMenuBar(t *testing.T) {
+	b := initHeadless(t, 50, 14)
+	defer DeinitLibrary()
+	defer ResetHotkeys()
+
+	wnd := AddWindow(0, 0, 30, 8, "Editor")
+	if _, y := wnd.Pos(); y != 0 {
+		t.Errorf("Window without menu bar must be at row 0 instead of %v", y)
+	}
+
+	clicked := ""
+	click := func(ev Event) {
+		clicked = ev.Msg
+	}
+
+	recent := NewMenu()
+	recent.AddItem("&1 notes.txt", click)
+	recent.AddItem("&2 todo.txt", click)
+
+	file := NewMenu()
+	open := file.AddItem("&Open", click)
+	open.SetAccelerator("Ctrl+O")
+	file.AddItem("&Save", click).SetEnabled(false)
+	file.AddSeparator()
+	file.AddSubmenu("&Recent", recent)
+	wrap := file.AddItem("&Word wrap", nil)
+	wrap.SetCheckable(true)
+	wrap.SetChecked(true)
+
+	edit := NewMenu()
+	edit.AddItem("&Copy", click)
+	edit.AddItem("&Paste", click)
+
+	bar := NewMenuBar()
+	bar.AddMenu("&File", file)
+	bar.AddMenu("&Edit", edit)
+	SetMenuBar(bar)
+
+	if _, y := wnd.Pos(); y != 1 {
+		t.Errorf("Window must be moved below menu bar, row %v", y)
+	}
+	if x, y, w, h := WorkArea(); x != 0 || y != 1 || w != 50 || h != 13 {
+		t.Errorf("Invalid work area %v:%v %vx%v", x, y, w, h)
+	}
+
+	// F10 highlights the menu bar, Enter opens the menu, Down skips
+	// disabled item and separator
+	SimulateKey(term.KeyF10, 0)
+	SimulateKey(term.KeyEnter, 0)
+	SimulateKey(term.KeyArrowDown, 0)
+	SimulateKey(term.KeyArrowRight, 0)
+	checkSnapshot(t, b, "menubar")
+
+	SimulateKey(0, '2')
+	if clicked != "2 todo.txt" {
+		t.Errorf("Submenu item must be clicked: '%v'", clicked)
+	}
+	if defaultApp.comp.menuActive() {
+		t.Error("Menus must be closed after click")
+	}
+
+	// Alt+hotkey opens the menu, Left switches to the previous one
+	SimulateEvent(Event{Type: EventKey, Ch: 'e', Mod: term.ModAlt})
+	SimulateKey(term.KeyArrowLeft, 0)
+	SimulateKey(0, 'w')
+	if wrap.Checked() {
+		t.Error("Checkable item must be toggled")
+	}
+
+	// accelerator works while menus are closed
+	clicked = ""
+	SimulateKey(term.KeyCtrlO, 0)
+	if clicked != "Open" {
+		t.Errorf("Accelerator must click the item: '%v'", clicked)
+	}
+
+	// mouse: click on the menu bar title and then on an item
+	SimulateClick(7, 0)
+	if len(defaultApp.comp.menus) != 1 || defaultApp.comp.menus[0] != edit {
+		t.Fatal("Click on menu bar must open Edit menu")
+	}
+	SimulateClick(edit.x+2, edit.y+2)
+	if clicked != "Paste" {
+		t.Errorf("Click must activate the item: '%v'", clicked)
+	}
+
+	// popup menu on right click
+	wnd.SetPopupMenu(edit)
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseRight, X: 5, Y: 4})
+	if len(defaultApp.comp.menus) != 1 || edit.x != 5 || edit.y != 4 {
+		t.Fatalf("Popup menu must be opened at 5:4 instead of %v:%v", edit.x, edit.y)
+	}
+	SimulateClick(45, 12)
+	if defaultApp.comp.menuActive() {
+		t.Error("Click outside must close the menu")
+	}
+
+	wnd.SetMaximized(true)
+	if _, y := wnd.Pos(); y != 1 {
+		t.Errorf("Maximized window must start below menu bar, row %v", y)
+	}
+}
+
+func TestMenuAccelerators(t *testing.T) {
+	initHeadless(t, 50, 14)
+	defer DeinitLibrary()
+	defer ResetHotkeys()
+
+	wnd := AddWindow(0, 0, 30, 8, "Editor")
+	clicked := ""
+	click := func(ev Event) {
+		clicked = ev.Msg
+	}
+	press := func(key term.Key) string {
+		clicked = ""
+		SimulateKey(key, 0)
+		return clicked
+	}
+
+	recent := NewMenu()
+	recent.AddItem("&Last", click).SetAccelerator("Ctrl+L")
+	file := NewMenu()
+	file.AddItem("&Open", click).SetAccelerator("Ctrl+O")
+	file.AddSubmenu("&Recent", recent)
+	bar := NewMenuBar()
+	bar.AddMenu("&File", file)
+	SetMenuBar(bar)
+	if press(term.KeyCtrlO) != "Open" || press(term.KeyCtrlL) != "Last" {
+		t.Fatal("Accelerators must click items of the menu bar")
+	}
+
+	// removed items do not respond to their accelerators
+	file.Clear()
+	if press(term.KeyCtrlO) != "" || press(term.KeyCtrlL) != "" {
+		t.Error("Accelerators must be removed with menu items")
+	}
+
+	file.AddItem("&Save", click).SetAccelerator("Ctrl+S")
+	SetMenuBar(nil)
+	if press(term.KeyCtrlS) != "" {
+		t.Error("Accelerators must be removed with the menu bar")
+	}
+	SetMenuBar(bar)
+	if press(term.KeyCtrlS) != "Save" {
+		t.Error("Accelerators must be restored when the menu bar is set again")
+	}
+
+	// a popup menu keeps accelerators while any control uses it
+	popup := NewMenu()
+	popup.AddItem("&Copy", click).SetAccelerator("Ctrl+B")
+	edit := CreateEditField(wnd, 10, "", Fixed)
+	wnd.SetPopupMenu(popup)
+	edit.SetPopupMenu(popup)
+	wnd.SetPopupMenu(nil)
+	if press(term.KeyCtrlB) != "Copy" {
+		t.Error("Accelerators must work while the popup menu has an owner")
+	}
+	edit.SetPopupMenu(nil)
+	if press(term.KeyCtrlB) != "" {
+		t.Error("Accelerators must be removed with the last popup menu owner")
+	}
+}
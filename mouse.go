@@ -0,0 +1,107 @@
+package clui
+
+import (
+	"sync/atomic"
+	"time"
+
+	term "github.com/nsf/termbox-go"
+)
+
+// DefaultDoubleClickInterval is the maximum time between two clicks of
+// the left mouse button that makes them a double click
+const DefaultDoubleClickInterval = 400 * time.Millisecond
+
+// DoubleClickInterval returns the maximum time between two clicks of the
+// left mouse button that makes them a double click
+func (a *App) DoubleClickInterval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&a.doubleClickInterval))
+}
+
+// SetDoubleClickInterval changes the maximum time between two clicks of
+// the left mouse button that makes them a double click. Zero or negative
+// interval turns off double click detection
+func (a *App) SetDoubleClickInterval(interval time.Duration) {
+	atomic.StoreInt64(&a.doubleClickInterval, int64(interval))
+}
+
+// DoubleClickInterval calls App.DoubleClickInterval of the default App
+func DoubleClickInterval() time.Duration {
+	return defaultApp.DoubleClickInterval()
+}
+
+// SetDoubleClickInterval calls App.SetDoubleClickInterval of the default
+// App
+func SetDoubleClickInterval(interval time.Duration) {
+	defaultApp.SetDoubleClickInterval(interval)
+}
+
+// popupMenuOwner is a control that can display a popup menu on right click
+type popupMenuOwner interface {
+	PopupMenu() *Menu
+}
+
+// isDoubleClick checks if the left mouse button press ev makes a double
+// click with the previous one. The second click of a double click does not
+// start a new one, so a triple click is a double click and a single click
+func (c *Composer) isDoubleClick(ev Event) bool {
+	now := time.Now()
+	interval := c.app.DoubleClickInterval()
+	double := interval > 0 && !c.clickTime.IsZero() &&
+		ev.X == c.clickX && ev.Y == c.clickY && now.Sub(c.clickTime) <= interval
+
+	if double {
+		c.clickTime = time.Time{}
+	} else {
+		c.clickTime = now
+		c.clickX, c.clickY = ev.X, ev.Y
+	}
+	return double
+}
+
+// sendMouseEvent sends an event of type tp to the control of the window
+// view under the mouse cursor. X and Y of the event are converted to the
+// coordinates relative to the control. If the control does not process
+// the event, it goes to the control parent, and so on up to the window.
+// Right click that nobody processes shows the popup menu of the closest
+// control that has one. Returns true if the event is processed
+func (c *Composer) sendMouseEvent(view Control, ev Event, tp EventType) bool {
+	for ctrl := ChildAt(view, ev.X, ev.Y); ctrl != nil; ctrl = ctrl.Parent() {
+		// windows send all unknown events to their active controls
+		if ctrl != view {
+			x, y := ctrl.Pos()
+			local := Event{Type: tp, Key: ev.Key, Mod: ev.Mod, X: ev.X - x, Y: ev.Y - y, Target: ctrl}
+			if ctrl.ProcessEvent(local) {
+				return true
+			}
+		}
+
+		if tp != EventContextMenu {
+			continue
+		}
+		if owner, ok := ctrl.(popupMenuOwner); ok && owner.PopupMenu() != nil {
+			c.app.ShowPopupMenu(owner.PopupMenu(), ev.X, ev.Y)
+			return true
+		}
+	}
+
+	return false
+}
+
+// processButtonClick handles a press of the right or middle mouse button
+// inside the top window: the control under the mouse cursor is activated
+// and receives EventContextMenu or EventMiddleClick
+func (c *Composer) processButtonClick(view Control, ev Event) {
+	c.clickTime = time.Time{}
+
+	ctrl := ChildAt(view, ev.X, ev.Y)
+	if ctrl != nil && ctrl != view && !ctrl.Active() {
+		ActivateControl(view, ctrl)
+	}
+
+	var tp EventType = EventContextMenu
+	if ev.Key == term.MouseMiddle {
+		tp = EventMiddleClick
+	}
+	c.sendMouseEvent(view, ev, tp)
+	c.app.InvalidateScreen()
+}
@@ -0,0 +1,137 @@
+package clui
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestDoubleClick(t *testing.T) {
+	initHeadless(t, 80, 25)
+	defer DeinitLibrary()
+	defer SetDoubleClickInterval(DefaultDoubleClickInterval)
+
+	wnd := AddWindow(0, 0, 40, 12, "Files")
+	wnd.SetPack(Vertical)
+	list := CreateListBox(wnd, 20, 4, 1)
+	for i := 0; i < 6; i++ {
+		list.AddItem(fmt.Sprintf("file %d", i))
+	}
+	table := CreateTableView(wnd, 20, 6, 1)
+	table.SetColumns([]Column{{Title: "Name", Width: 8}, {Title: "Size", Width: 6}})
+	table.SetRowCount(10)
+
+	opened := -1
+	list.OnActivateItem(func(ev Event) {
+		opened = ev.Y
+	})
+	var action TableEvent
+	table.OnAction(func(ev TableEvent) {
+		action = ev
+	})
+
+	SetDoubleClickInterval(time.Minute)
+	lx, ly := list.Pos()
+	SimulateClick(lx+1, ly+2)
+	if opened != -1 {
+		t.Error("Single click must not activate the item")
+	}
+	SimulateClick(lx+1, ly+2)
+	if opened != 2 {
+		t.Errorf("Double click must activate item 2 instead of %v", opened)
+	}
+
+	// the third click starts a new double click
+	opened = -1
+	SimulateClick(lx+1, ly+2)
+	if opened != -1 {
+		t.Error("Triple click must not be the second double click")
+	}
+
+	// clicks at different places
+	SimulateClick(lx+1, ly+1)
+	SimulateClick(lx+1, ly+3)
+	if opened != -1 {
+		t.Error("Clicks at different places are not a double click")
+	}
+
+	SimulateKey(term.KeyEnter, 0)
+	if opened != 3 {
+		t.Errorf("Enter must activate item 3 instead of %v", opened)
+	}
+
+	tx, ty := table.Pos()
+	SimulateClick(tx+10, ty+3)
+	SimulateClick(tx+10, ty+3)
+	if action.Action != TableActionEdit || action.Row != 1 || action.Col != 1 {
+		t.Errorf("Double click must edit the cell 1:1 instead of %v", action)
+	}
+
+	SetDoubleClickInterval(0)
+	opened = -1
+	SimulateClick(lx+1, ly)
+	SimulateClick(lx+1, ly)
+	if opened != -1 {
+		t.Error("Zero interval must turn off double clicks")
+	}
+}
+
+func TestContextMenu(t *testing.T) {
+	initHeadless(t, 80, 25)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 40, 12, "Files")
+	wnd.SetPack(Vertical)
+	edit := CreateEditField(wnd, 20, "", Fixed)
+	check := CreateCheckBox(wnd, 20, "Hidden", Fixed)
+	list := CreateListBox(wnd, 20, 4, 1)
+	list.AddItem("file")
+
+	wndMenu := NewMenu()
+	wndMenu.AddItem("&Refresh", nil)
+	listMenu := NewMenu()
+	listMenu.AddItem("&Open", nil)
+	wnd.SetPopupMenu(wndMenu)
+	list.SetPopupMenu(listMenu)
+
+	lx, ly := list.Pos()
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseRight, X: lx + 2, Y: ly + 1})
+	if len(defaultApp.comp.menus) != 1 || defaultApp.comp.menus[0] != listMenu {
+		t.Fatal("Right click must open the list popup menu")
+	}
+	if listMenu.x != lx+2 || listMenu.y != ly+1 {
+		t.Errorf("Menu must be opened at the mouse cursor: %v:%v", listMenu.x, listMenu.y)
+	}
+	if !list.Active() {
+		t.Error("Right click must activate the control")
+	}
+	SimulateKey(term.KeyEsc, 0)
+
+	// the control without own menu shows the menu of its parent
+	ex, ey := edit.Pos()
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseRight, X: ex + 1, Y: ey})
+	if len(defaultApp.comp.menus) != 1 || defaultApp.comp.menus[0] != wndMenu {
+		t.Fatal("Right click must open the window popup menu")
+	}
+	SimulateKey(term.KeyEsc, 0)
+
+	// neither right nor middle button clicks controls
+	cx, cy := check.Pos()
+	wnd.SetPopupMenu(nil)
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseRight, X: cx + 1, Y: cy})
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseRelease, X: cx + 1, Y: cy})
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseMiddle, X: cx + 1, Y: cy})
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseRelease, X: cx + 1, Y: cy})
+	if defaultApp.comp.menuActive() {
+		t.Error("No menu must be opened")
+	}
+	if check.State() != 0 {
+		t.Error("Right and middle button must not toggle the check box")
+	}
+	SimulateClick(cx+1, cy)
+	if check.State() != 1 {
+		t.Error("Left button must toggle the check box")
+	}
+}
@@ -1,7 +1,6 @@
 package clui
 
 import (
-	xs "github.com/huandu/xstrings"
 	term "github.com/nsf/termbox-go"
 	"strconv"
 	"strings"
@@ -82,13 +81,14 @@ func (b *ProgressBar) Draw() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(b)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
-	fgOff, fgOn := RealColor(b.fg, b.Style(), ColorProgressText), RealColor(b.fgActive, b.Style(), ColorProgressActiveText)
-	bgOff, bgOn := RealColor(b.bg, b.Style(), ColorProgressBack), RealColor(b.bgActive, b.Style(), ColorProgressActiveBack)
+	fgOff, fgOn := a.RealColor(b.fg, b.Style(), ColorProgressText), a.RealColor(b.fgActive, b.Style(), ColorProgressActiveText)
+	bgOff, bgOn := a.RealColor(b.bg, b.Style(), ColorProgressBack), a.RealColor(b.bgActive, b.Style(), ColorProgressActiveBack)
 
-	parts := []rune(SysObject(ObjProgressBar))
+	parts := []rune(a.SysObject(ObjProgressBar))
 	cFilled, cEmpty := parts[0], parts[1]
 
 	prc := 0
@@ -116,35 +116,35 @@ func (b *ProgressBar) Draw() {
 		sEmpty := strings.Repeat(string(cEmpty), w-filled)
 
 		for yy := y; yy < y+h; yy++ {
-			SetTextColor(fgOn)
-			SetBackColor(bgOn)
-			DrawRawText(x, yy, sFilled)
-			SetTextColor(fgOff)
-			SetBackColor(bgOff)
-			DrawRawText(x+filled, yy, sEmpty)
+			a.SetTextColor(fgOn)
+			a.SetBackColor(bgOn)
+			a.DrawRawText(x, yy, sFilled)
+			a.SetTextColor(fgOff)
+			a.SetBackColor(bgOff)
+			a.DrawRawText(x+filled, yy, sEmpty)
 		}
 
 		if title != "" {
 			shift, str := AlignText(title, w, b.align)
-			titleClr := RealColor(b.titleFg, b.Style(), ColorProgressTitleText)
+			titleClr := a.RealColor(b.titleFg, b.Style(), ColorProgressTitleText)
 			var sOn, sOff string
 			if filled == 0 || shift >= filled {
 				sOff = str
-			} else if w == filled || shift+xs.Len(str) < filled {
+			} else if w == filled || shift+TextWidth(str) < filled {
 				sOn = str
 			} else {
 				r := filled - shift
-				sOn = xs.Slice(str, 0, r)
-				sOff = xs.Slice(str, r, -1)
+				sOn = sliceByWidth(str, 0, r)
+				sOff = str[len(sOn):]
 			}
-			SetTextColor(titleClr)
+			a.SetTextColor(titleClr)
 			if sOn != "" {
-				SetBackColor(bgOn)
-				DrawRawText(x+shift, y, sOn)
+				a.SetBackColor(bgOn)
+				a.DrawRawText(x+shift, y, sOn)
 			}
 			if sOff != "" {
-				SetBackColor(bgOff)
-				DrawRawText(x+shift+xs.Len(sOn), y, sOff)
+				a.SetBackColor(bgOff)
+				a.DrawRawText(x+shift+TextWidth(sOn), y, sOff)
 			}
 		}
 	} else {
@@ -152,14 +152,14 @@ func (b *ProgressBar) Draw() {
 		sFilled := strings.Repeat(string(cFilled), w)
 		sEmpty := strings.Repeat(string(cEmpty), w)
 		for yy := y; yy < y+h-filled; yy++ {
-			SetTextColor(fgOff)
-			SetBackColor(bgOff)
-			DrawRawText(x, yy, sEmpty)
+			a.SetTextColor(fgOff)
+			a.SetBackColor(bgOff)
+			a.DrawRawText(x, yy, sEmpty)
 		}
 		for yy := y + h - filled; yy < y+h; yy++ {
-			SetTextColor(fgOff)
-			SetBackColor(bgOff)
-			DrawRawText(x, yy, sFilled)
+			a.SetTextColor(fgOff)
+			a.SetBackColor(bgOff)
+			a.DrawRawText(x, yy, sFilled)
 		}
 	}
 }
@@ -169,6 +169,7 @@ func (b *ProgressBar) Draw() {
 // SetValue sets new progress value. If value exceeds ProgressBar
 // limits then the limit value is used
 func (b *ProgressBar) SetValue(pos int) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 	if pos < b.min {
@@ -195,6 +196,7 @@ func (b *ProgressBar) Limits() (int, int) {
 // SetLimits set new ProgressBar limits. The current value
 // is adjusted if it exceeds new limits
 func (b *ProgressBar) SetLimits(min, max int) {
+	b.Invalidate()
 	b.min = min
 	b.max = max
 
@@ -229,6 +231,7 @@ func (b *ProgressBar) SecondaryColors() (term.Attribute, term.Attribute) {
 // SetSecondaryColors sets new text and background colors for
 // empty part of the ProgressBar
 func (b *ProgressBar) SetSecondaryColors(fg, bg term.Attribute) {
+	b.Invalidate()
 	b.emptyFg, b.emptyBg = fg, bg
 }
 
@@ -242,5 +245,6 @@ func (b *ProgressBar) TitleColor() term.Attribute {
 
 // SetTitleColor sets text color of ProgressBar's title
 func (b *ProgressBar) SetTitleColor(clr term.Attribute) {
+	b.Invalidate()
 	b.titleFg = clr
 }
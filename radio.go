@@ -1,7 +1,6 @@
 package clui
 
 import (
-	xs "github.com/huandu/xstrings"
 	term "github.com/nsf/termbox-go"
 )
 
@@ -31,7 +30,7 @@ func CreateRadio(parent Control, width int, title string, scale int) *Radio {
 	c.BaseControl = NewBaseControl()
 
 	if width == AutoSize {
-		width = xs.Len(title) + 4
+		width = TextWidth(title) + 4
 	}
 
 	c.parent = parent
@@ -57,43 +56,44 @@ func (c *Radio) Draw() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(c)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	x, y := c.Pos()
 	w, h := c.Size()
 
-	fg, bg := RealColor(c.fg, c.Style(), ColorControlText), RealColor(c.bg, c.Style(), ColorControlBack)
+	fg, bg := a.RealColor(c.fg, c.Style(), ColorControlText), a.RealColor(c.bg, c.Style(), ColorControlBack)
 	if !c.Enabled() {
-		fg, bg = RealColor(c.fg, c.Style(), ColorControlDisabledText), RealColor(c.bg, c.Style(), ColorControlDisabledBack)
+		fg, bg = a.RealColor(c.fg, c.Style(), ColorControlDisabledText), a.RealColor(c.bg, c.Style(), ColorControlDisabledBack)
 	} else if c.Active() {
-		fg, bg = RealColor(c.fg, c.Style(), ColorControlActiveText), RealColor(c.bg, c.Style(), ColorControlActiveBack)
+		fg, bg = a.RealColor(c.fg, c.Style(), ColorControlActiveText), a.RealColor(c.bg, c.Style(), ColorControlActiveBack)
 	}
 
-	parts := []rune(SysObject(ObjRadio))
+	parts := []rune(a.SysObject(ObjRadio))
 	cOpen, cClose, cEmpty, cCheck := parts[0], parts[1], parts[2], parts[3]
 	cState := cEmpty
 	if c.selected {
 		cState = cCheck
 	}
 
-	SetTextColor(fg)
-	SetBackColor(bg)
-	FillRect(x, y, w, h, ' ')
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(x, y, w, h, ' ')
 	if w < 3 {
 		return
 	}
 
-	PutChar(x, y, cOpen)
-	PutChar(x+2, y, cClose)
-	PutChar(x+1, y, cState)
+	a.PutChar(x, y, cOpen)
+	a.PutChar(x+2, y, cClose)
+	a.PutChar(x+1, y, cState)
 
 	if w < 5 {
 		return
 	}
 
 	shift, text := AlignColorizedText(c.title, w-4, c.align)
-	DrawText(x+4+shift, y, text)
+	a.DrawText(x+4+shift, y, text)
 }
 
 // ProcessEvent processes all events come from the control parent. If a control
@@ -123,6 +123,7 @@ func (c *Radio) ProcessEvent(event Event) bool {
 // SetSelected makes the button selected. One should not use
 // the method directly, it is for RadioGroup control
 func (c *Radio) SetSelected(val bool) {
+	c.Invalidate()
 	c.selected = val
 
 	if c.onChange != nil {
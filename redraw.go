@@ -0,0 +1,264 @@
+package clui
+
+import (
+	"time"
+)
+
+// DefaultFrameRate is the maximum number of screen repaints per second
+const DefaultFrameRate = 30
+
+// damage is the area of the screen that must be repainted: the bounding
+// rectangle of all invalidated areas. all is true if the whole screen must
+// be repainted. painting is true while the screen is being repainted
+type damage struct {
+	area     rect
+	all      bool
+	painting bool
+}
+
+func (r rect) empty() bool {
+	return r.w <= 0 || r.h <= 0
+}
+
+// union returns the bounding rectangle of both rectangles
+func (r rect) union(o rect) rect {
+	if r.empty() {
+		return o
+	}
+	if o.empty() {
+		return r
+	}
+
+	res := r
+	if o.x < res.x {
+		res.w += res.x - o.x
+		res.x = o.x
+	}
+	if o.y < res.y {
+		res.h += res.y - o.y
+		res.y = o.y
+	}
+	if o.x+o.w > res.x+res.w {
+		res.w = o.x + o.w - res.x
+	}
+	if o.y+o.h > res.y+res.h {
+		res.h = o.y + o.h - res.y
+	}
+	return res
+}
+
+// intersect returns the common part of both rectangles
+func (r rect) intersect(o rect) rect {
+	x, y := r.x, r.y
+	if o.x > x {
+		x = o.x
+	}
+	if o.y > y {
+		y = o.y
+	}
+	right, bottom := r.x+r.w, r.y+r.h
+	if o.x+o.w < right {
+		right = o.x + o.w
+	}
+	if o.y+o.h < bottom {
+		bottom = o.y + o.h
+	}
+	if right <= x || bottom <= y {
+		return rect{}
+	}
+	return rect{x: x, y: y, w: right - x, h: bottom - y}
+}
+
+/*
+InvalidateRect marks the area of the screen as changed. The main loop
+repaints invalidated areas once per frame(see SetFrameRate): only the
+windows that intersect the area are redrawn, and the screen is flushed
+once.
+
+After a user presses a key or uses the mouse, the windows that receive
+the event are repainted automatically. Controls invalidate themselves
+when their content is changed with their methods, e.g ProgressBar.SetValue
+or TextView.AddText. A custom control must call Invalidate after changing
+what it displays outside of its event handlers, e.g inside Invoke or
+a timer callback.
+The function is not thread safe: call it from the main loop goroutine, or
+wrap with Invoke
+*/
+func (a *App) InvalidateRect(x, y, w, h int) {
+	if a == nil {
+		return
+	}
+	a.comp.invalidate(rect{x: x, y: y, w: w, h: h})
+}
+
+// InvalidateScreen marks the whole screen as changed, so the main loop
+// repaints all windows at the next frame. Unlike RefreshScreen the screen
+// is not repainted immediately
+func (a *App) InvalidateScreen() {
+	if a == nil {
+		return
+	}
+	c := a.comp
+	c.dirtyMtx.Lock()
+	if !c.dirty.painting {
+		c.dirty.all = true
+	}
+	c.dirtyMtx.Unlock()
+}
+
+// InvalidateRect calls App.InvalidateRect of the default App
+func InvalidateRect(x, y, w, h int) {
+	defaultApp.InvalidateRect(x, y, w, h)
+}
+
+// InvalidateScreen calls App.InvalidateScreen of the default App
+func InvalidateScreen() {
+	defaultApp.InvalidateScreen()
+}
+
+// Invalidate marks the control area as changed, so it is repainted at the
+// next frame
+func (c *BaseControl) Invalidate() {
+	AppOf(c).InvalidateRect(c.x, c.y, c.width, c.height)
+}
+
+// invalidateControl marks the area of any control as changed
+func invalidateControl(c Control) {
+	if c == nil {
+		return
+	}
+	x, y := c.Pos()
+	w, h := c.Size()
+	AppOf(c).InvalidateRect(x, y, w, h)
+}
+
+// invalidate adds the area to the damaged one. Changes made while the
+// screen is being repainted are ignored: they are already displayed
+func (c *Composer) invalidate(r rect) {
+	if r.empty() {
+		return
+	}
+	c.dirtyMtx.Lock()
+	if !c.dirty.painting {
+		c.dirty.area = c.dirty.area.union(r)
+	}
+	c.dirtyMtx.Unlock()
+}
+
+// invalidateStatusBar marks the status bar as changed: e.g, the hint of
+// the active control is displayed there
+func (c *Composer) invalidateStatusBar() {
+	if c != nil && c.statusBar != nil {
+		sw, sh := c.app.ScreenSize()
+		c.invalidate(rect{x: 0, y: sh - 1, w: sw, h: 1})
+	}
+}
+
+// needsRepaint returns true if any area of the screen is invalidated
+func (c *Composer) needsRepaint() bool {
+	c.dirtyMtx.Lock()
+	defer c.dirtyMtx.Unlock()
+	return c.dirty.all || !c.dirty.area.empty()
+}
+
+// repaint redraws invalidated areas of the screen and flushes the screen
+func (c *Composer) repaint() {
+	c.dirtyMtx.Lock()
+	d := c.dirty
+	c.dirty = damage{}
+	c.dirtyMtx.Unlock()
+
+	if d.all {
+		c.app.RefreshScreen()
+		return
+	}
+	c.setPainting(true)
+	defer c.setPainting(false)
+
+	sw, sh := c.app.ScreenSize()
+	area := d.area.intersect(rect{w: sw, h: sh})
+	if area.empty() {
+		return
+	}
+
+	c.app.setDrawLimit(area)
+	defer c.app.setDrawLimit(rect{w: sw, h: sh})
+
+	c.app.PushAttributes()
+	c.app.SetTextColor(ColorWhite)
+	c.app.SetBackColor(ColorBlack)
+	c.app.FillRect(area.x, area.y, area.w, area.h, ' ')
+	c.app.PopAttributes()
+
+	for _, wnd := range c.getWindowList() {
+		x, y := wnd.Pos()
+		w, h := wnd.Size()
+		if wnd.Visible() && !area.intersect(rect{x: x, y: y, w: w, h: h}).empty() {
+			wnd.Draw()
+		}
+	}
+	c.drawOverlays()
+
+	c.BeginUpdate()
+	c.app.Flush()
+	c.EndUpdate()
+}
+
+func (c *Composer) setPainting(painting bool) {
+	c.dirtyMtx.Lock()
+	c.dirty.painting = painting
+	c.dirtyMtx.Unlock()
+}
+
+// SetFrameRate changes the maximum number of screen repaints per second.
+// Events that come faster are processed without repainting, and the
+// screen is repainted once after them. Zero or negative value turns off
+// the limit: the screen is repainted after every event
+func (a *App) SetFrameRate(fps int) {
+	l := a.loop
+	l.frameMtx.Lock()
+	defer l.frameMtx.Unlock()
+	if fps <= 0 {
+		l.frameTime = 0
+		return
+	}
+	l.frameTime = time.Second / time.Duration(fps)
+}
+
+// FrameRate returns the maximum number of screen repaints per second. Zero
+// means no limit
+func (a *App) FrameRate() int {
+	l := a.loop
+	l.frameMtx.Lock()
+	defer l.frameMtx.Unlock()
+	if l.frameTime == 0 {
+		return 0
+	}
+	return int(time.Second / l.frameTime)
+}
+
+// SetFrameRate calls App.SetFrameRate of the default App
+func SetFrameRate(fps int) {
+	defaultApp.SetFrameRate(fps)
+}
+
+// FrameRate calls App.FrameRate of the default App
+func FrameRate() int {
+	return defaultApp.FrameRate()
+}
+
+// frameDelay returns how long the main loop must wait before the next
+// repaint
+func (l *mainLoop) frameDelay() time.Duration {
+	l.frameMtx.Lock()
+	defer l.frameMtx.Unlock()
+	return l.frameTime - time.Since(l.lastFrame)
+}
+
+// paintFrame repaints invalidated areas and remembers the frame time
+func (l *mainLoop) paintFrame() {
+	l.app.comp.repaint()
+	l.frameMtx.Lock()
+	l.lastFrame = time.Now()
+	l.frameMtx.Unlock()
+}
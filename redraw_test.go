@@ -0,0 +1,138 @@
+package clui
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPartialRedraw(t *testing.T) {
+	b := initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	left := AddWindow(0, 0, 15, 6, "Left")
+	left.SetPack(Vertical)
+	lbl := CreateLabel(left, 10, 1, "old", Fixed)
+	right := AddWindow(20, 0, 15, 6, "Right")
+	right.SetPack(Vertical)
+	CreateComboBox(right, 10, "combo", Fixed)
+	RefreshScreen()
+	if defaultApp.comp.needsRepaint() {
+		t.Fatal("Changes made while drawing must not invalidate the screen")
+	}
+
+	// the cells outside the invalidated area are not repainted
+	defaultApp.backend.SetCell(39, 11, '#', ColorWhite, ColorBlack)
+	defaultApp.backend.SetCell(25, 3, '#', ColorWhite, ColorBlack)
+	lbl.SetTitle("new")
+	if !defaultApp.comp.needsRepaint() {
+		t.Fatal("SetTitle must invalidate the control")
+	}
+	defaultApp.loop.paintFrame()
+
+	lines := strings.Split(b.Snapshot(), "\n")
+	if !strings.Contains(lines[1], "new") {
+		t.Errorf("Invalidated control must be repainted:\n%v", b.Snapshot())
+	}
+	if []rune(lines[11])[39] != '#' || []rune(lines[3])[25] != '#' {
+		t.Errorf("Only invalidated area must be repainted:\n%v", b.Snapshot())
+	}
+	if defaultApp.comp.needsRepaint() {
+		t.Error("Repainting must reset the invalidated area")
+	}
+
+	// a moved window invalidates its old and new places
+	left.SetPos(2, 4)
+	defaultApp.loop.paintFrame()
+	lines = strings.Split(b.Snapshot(), "\n")
+	if strings.Contains(lines[0], "Left") || !strings.Contains(lines[4], "Left") {
+		t.Errorf("Window must be moved:\n%v", b.Snapshot())
+	}
+	if []rune(lines[3])[25] != '#' {
+		t.Errorf("Window that does not intersect the area must not be repainted:\n%v", b.Snapshot())
+	}
+
+	InvalidateScreen()
+	defaultApp.loop.paintFrame()
+	if strings.Contains(b.Snapshot(), "#") {
+		t.Errorf("The whole screen must be repainted:\n%v", b.Snapshot())
+	}
+}
+
+type countingBackend struct {
+	*HeadlessBackend
+	flushes int32
+}
+
+func (b *countingBackend) Flush() error {
+	atomic.AddInt32(&b.flushes, 1)
+	return b.HeadlessBackend.Flush()
+}
+
+func TestFrameRate(t *testing.T) {
+	b := &countingBackend{HeadlessBackend: NewHeadlessBackend(40, 12)}
+	if !InitLibrary(b) {
+		t.Fatal("Failed to initialize library with headless backend")
+	}
+	defer DeinitLibrary()
+
+	SetFrameRate(5)
+	if FrameRate() != 5 {
+		t.Errorf("Invalid frame rate: %v", FrameRate())
+	}
+	wnd := AddWindow(0, 0, 30, 10, "Progress")
+	wnd.SetPack(Vertical)
+	bar := CreateProgressBar(wnd, 20, 1, Fixed)
+	bar.SetLimits(0, 100)
+	edit := CreateEditField(wnd, 20, "", Fixed)
+	ActivateControl(wnd, edit)
+
+	done := make(chan struct{})
+	go func() {
+		MainLoop()
+		close(done)
+	}()
+
+	InvokeSync(func() {})
+	start := atomic.LoadInt32(&b.flushes)
+	for i := 0; i <= 100; i++ {
+		n := i
+		Invoke(func() {
+			bar.SetValue(n)
+		})
+	}
+	InvokeSync(func() {})
+	time.Sleep(300 * time.Millisecond)
+
+	if n := atomic.LoadInt32(&b.flushes) - start; n == 0 || n > 3 {
+		t.Errorf("A burst of updates must be displayed with a few repaints instead of %v", n)
+	}
+	var value int
+	InvokeSync(func() {
+		value = bar.Value()
+	})
+	if value != 100 {
+		t.Errorf("All updates must be applied: %v", value)
+	}
+
+	// the same for a burst of key presses
+	start = atomic.LoadInt32(&b.flushes)
+	for i := 0; i < 10; i++ {
+		b.PostEvent(Event{Type: EventKey, Ch: 'a'})
+	}
+	time.Sleep(300 * time.Millisecond)
+	if n := atomic.LoadInt32(&b.flushes) - start; n == 0 || n > 3 {
+		t.Errorf("A burst of keys must be displayed with a few repaints instead of %v", n)
+	}
+	var text string
+	InvokeSync(func() {
+		text = edit.Title()
+	})
+	if text != strings.Repeat("a", 10) {
+		t.Errorf("All keys must be processed: %q", text)
+	}
+
+	Stop()
+	<-done
+}
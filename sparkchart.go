@@ -80,13 +80,14 @@ func (b *SparkChart) Draw() {
 	b.mtx.RLock()
 	defer b.mtx.RUnlock()
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(b)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
-	fg, bg := RealColor(b.fg, b.Style(), ColorSparkChartText), RealColor(b.bg, b.Style(), ColorSparkChartBack)
-	SetTextColor(fg)
-	SetBackColor(bg)
-	FillRect(b.x, b.y, b.width, b.height, ' ')
+	fg, bg := a.RealColor(b.fg, b.Style(), ColorSparkChartText), a.RealColor(b.bg, b.Style(), ColorSparkChartBack)
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(b.x, b.y, b.width, b.height, ' ')
 
 	if len(b.data) == 0 {
 		return
@@ -111,15 +112,16 @@ func (b *SparkChart) drawBars() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(b)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	h := b.height
 	pos := b.x + start
 
-	mxFg, mxBg := RealColor(b.maxFg, b.Style(), ColorSparkChartMaxText), RealColor(b.maxBg, b.Style(), ColorSparkChartMaxBack)
-	brFg, brBg := RealColor(b.fg, b.Style(), ColorSparkChartBarText), RealColor(b.bg, b.Style(), ColorSparkChartBarBack)
-	parts := []rune(SysObject(ObjSparkChart))
+	mxFg, mxBg := a.RealColor(b.maxFg, b.Style(), ColorSparkChartMaxText), a.RealColor(b.maxBg, b.Style(), ColorSparkChartMaxBack)
+	brFg, brBg := a.RealColor(b.fg, b.Style(), ColorSparkChartBarText), a.RealColor(b.bg, b.Style(), ColorSparkChartBarBack)
+	parts := []rune(a.SysObject(ObjSparkChart))
 
 	var dt []float64
 	if len(b.data) > width {
@@ -140,9 +142,9 @@ func (b *SparkChart) drawBars() {
 		if b.hiliteMax && max == d {
 			f, g = mxFg, mxBg
 		}
-		SetTextColor(f)
-		SetBackColor(g)
-		FillRect(pos, b.y+h-barH, 1, barH, parts[0])
+		a.SetTextColor(f)
+		a.SetBackColor(g)
+		a.FillRect(pos, b.y+h-barH, 1, barH, parts[0])
 
 		pos++
 	}
@@ -169,11 +171,12 @@ func (b *SparkChart) drawValues() {
 
 	dy := 0
 	format := fmt.Sprintf("%%%v.2f", b.valueWidth)
+	a := AppOf(b)
 	for dy < h-1 {
 		v := float64(h-dy) / float64(h) * max
 		s := fmt.Sprintf(format, v)
 		s = CutText(s, b.valueWidth)
-		DrawRawText(b.x, b.y+dy, s)
+		a.DrawRawText(b.x, b.y+dy, s)
 
 		dy += 2
 	}
@@ -220,6 +223,7 @@ func (b *SparkChart) calculateMultiplier() (float64, float64) {
 
 // AddData appends a new bar to a chart
 func (b *SparkChart) AddData(val float64) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -241,6 +245,7 @@ func (b *SparkChart) ClearData() {
 
 // SetData assigns a new bar list to a chart
 func (b *SparkChart) SetData(data []float64) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -262,6 +267,7 @@ func (b *SparkChart) ValueWidth() int {
 
 // SetValueWidth changes width of the value panel on the left
 func (b *SparkChart) SetValueWidth(width int) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -277,6 +283,7 @@ func (b *SparkChart) Top() float64 {
 // SetTop sets the theoretical highest value of data flow
 // to scale the chart
 func (b *SparkChart) SetTop(top float64) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -291,6 +298,7 @@ func (b *SparkChart) AutoScale() bool {
 
 // SetAutoScale changes the way of scaling the data flow
 func (b *SparkChart) SetAutoScale(auto bool) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -306,6 +314,7 @@ func (b *SparkChart) HilitePeaks() bool {
 // SetHilitePeaks enables or disables hiliting maximum
 // values with different colors
 func (b *SparkChart) SetHilitePeaks(hilite bool) {
+	b.Invalidate()
 	b.mtx.Lock()
 	defer b.mtx.Unlock()
 
@@ -0,0 +1,272 @@
+package clui
+
+import (
+	term "github.com/nsf/termbox-go"
+)
+
+// statusKey is a key legend displayed in the status bar
+type statusKey struct {
+	keys  []KeyPress
+	title string
+	fn    func(Event)
+	// screen column and width of the legend, calculated while drawing
+	x, width int
+}
+
+/*
+StatusBar is a line at the bottom of the screen that displays key legends
+and a hint. Like MenuBar it is managed by Composer outside window stacking:
+the last screen row is excluded from the area available for windows (see
+WorkArea).
+
+A key legend is a key name and its description, e.g "F2 Edit". Clicking a
+legend works as pressing the key.
+
+The hint follows the focus: the status bar displays the hint of the active
+control of the top window. If the control has no hint, the hint of its
+closest parent is displayed, up to the window. If no one has a hint, the
+status bar text is displayed. See BaseControl.SetHint
+
+Global hotkeys of the key legends work only while the status bar is
+displayed by an App
+*/
+type StatusBar struct {
+	text string
+	keys []*statusKey
+	// the App that displays the status bar, global hotkeys of the key
+	// legends are registered in its hotkeys
+	app *App
+}
+
+// NewStatusBar creates an empty status bar. Call SetStatusBar to display it
+func NewStatusBar() *StatusBar {
+	return new(StatusBar)
+}
+
+// Text returns the text displayed when the focused control has no hint
+func (s *StatusBar) Text() string {
+	return s.text
+}
+
+// SetText changes the text displayed when the focused control has no hint.
+// The text can contain color tags
+func (s *StatusBar) SetText(text string) {
+	s.invalidate()
+	s.text = text
+}
+
+// AddKey appends a key legend. hotkey is the key description that is
+// understood by ParseHotkey, e.g "F2" or "Ctrl+S".
+// If fn is not nil, the hotkey is registered as a global one, and fn is
+// called when a user presses the key or clicks the legend. If fn is nil,
+// clicking the legend emulates the key press, so the key is processed as
+// usual(e.g, by the active control)
+func (s *StatusBar) AddKey(hotkey, title string, fn func(Event)) error {
+	s.invalidate()
+	keys, err := ParseHotkey(hotkey)
+	if err != nil {
+		return err
+	}
+
+	s.RemoveKey(hotkey)
+	key := &statusKey{keys: keys, title: title, fn: fn}
+	s.keys = append(s.keys, key)
+	s.bindKey(key)
+
+	return nil
+}
+
+// RemoveKey removes the key legend and its global hotkey
+func (s *StatusBar) RemoveKey(hotkey string) {
+	s.invalidate()
+	keys, err := ParseHotkey(hotkey)
+	if err != nil {
+		return
+	}
+
+	for i, k := range s.keys {
+		if sameKeys(k.keys, keys) {
+			s.unbindKey(k)
+			s.keys = append(s.keys[:i], s.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// ClearKeys removes all key legends and their global hotkeys
+func (s *StatusBar) ClearKeys() {
+	for _, k := range s.keys {
+		s.unbindKey(k)
+	}
+	s.keys = nil
+}
+
+// bindKey registers the global hotkey of the key legend in the App that
+// displays the status bar
+func (s *StatusBar) bindKey(k *statusKey) {
+	if s.app == nil || k.fn == nil {
+		return
+	}
+
+	fn, title := k.fn, k.title
+	s.app.hotkeys.addGlobal(func(ev Event) bool {
+		fn(Event{Type: EventClick, Msg: title})
+		return true
+	}, k.keys)
+}
+
+// unbindKey removes the global hotkey of the key legend
+func (s *StatusBar) unbindKey(k *statusKey) {
+	if s.app != nil && k.fn != nil {
+		s.app.hotkeys.remove(k.keys)
+	}
+}
+
+// setApp moves global hotkeys of the key legends to the App. nil App
+// removes them
+func (s *StatusBar) setApp(a *App) {
+	if s == nil || s.app == a {
+		return
+	}
+
+	for _, k := range s.keys {
+		s.unbindKey(k)
+	}
+	s.app = a
+	for _, k := range s.keys {
+		s.bindKey(k)
+	}
+}
+
+// invalidate marks the status bar as changed if an App displays it
+func (s *StatusBar) invalidate() {
+	if s.app != nil {
+		s.app.comp.invalidateStatusBar()
+	}
+}
+
+func (s *StatusBar) draw(a *App, y int, hint string) {
+	a.PushAttributes()
+	defer a.PopAttributes()
+
+	fg, bg := a.SysColor(ColorStatusText), a.SysColor(ColorStatusBack)
+	keyFg := a.SysColor(ColorStatusKeyText)
+	a.SetBackColor(bg)
+	a.SetTextColor(fg)
+	sw, _ := a.ScreenSize()
+	a.FillRect(0, y, sw, 1, ' ')
+
+	x := 1
+	for _, k := range s.keys {
+		name := HotkeyToString(k.keys)
+		k.x, k.width = x, TextWidth(name)+1+TextWidth(k.title)
+
+		a.SetTextColor(keyFg)
+		a.DrawRawText(x, y, name)
+		a.SetTextColor(fg)
+		a.DrawRawText(x+TextWidth(name)+1, y, k.title)
+		x += k.width + 2
+	}
+
+	if hint != "" && x < sw {
+		a.SetTextColor(fg)
+		a.DrawText(x, y, SliceColorized(hint, 0, sw-x))
+	}
+}
+
+func (s *StatusBar) keyAt(x int) *statusKey {
+	for _, k := range s.keys {
+		if x >= k.x && x < k.x+k.width {
+			return k
+		}
+	}
+	return nil
+}
+
+// SetStatusBar displays the status bar at the bottom of the screen. nil
+// removes the current status bar. Maximized windows are resized to the
+// area above the status bar. A status bar can be displayed by only one App
+// at a time
+func (a *App) SetStatusBar(bar *StatusBar) {
+	c := a.comp
+	if c.statusBar != bar {
+		c.statusBar.setApp(nil)
+		bar.setApp(a)
+	}
+	c.statusBar = bar
+	c.fitWindowsToWorkArea()
+	a.InvalidateScreen()
+}
+
+// SetStatusBar calls App.SetStatusBar of the default App
+func SetStatusBar(bar *StatusBar) {
+	defaultApp.SetStatusBar(bar)
+}
+
+// currentHint returns the hint of the focused control
+func (c *Composer) currentHint() string {
+	top := c.topWindow()
+	if top != nil {
+		ctrl := ActiveControl(top)
+		for ctrl != nil {
+			child := ActiveControl(ctrl)
+			if child == nil {
+				break
+			}
+			ctrl = child
+		}
+		for ; ctrl != nil; ctrl = ctrl.Parent() {
+			if hint := ctrl.Hint(); hint != "" {
+				return hint
+			}
+		}
+		if hint := top.Hint(); hint != "" {
+			return hint
+		}
+	}
+
+	return c.statusBar.text
+}
+
+func (c *Composer) drawStatusBar() {
+	if c.statusBar == nil {
+		return
+	}
+
+	c.app.PushClip()
+	defer c.app.PopClip()
+	sw, sh := c.app.ScreenSize()
+	c.app.SetClipRect(0, 0, sw, sh)
+
+	c.statusBar.draw(c.app, sh-1, c.currentHint())
+}
+
+// processStatusMouse handles mouse clicks on the status bar. Returns true
+// if the event must not be processed further
+func (c *Composer) processStatusMouse(ev Event) bool {
+	if c.statusBar == nil || c.dragType != DragNone {
+		return false
+	}
+	if _, sh := c.app.ScreenSize(); ev.Y != sh-1 {
+		return false
+	}
+
+	if ev.Key != term.MouseLeft || ev.Mod == term.ModMotion {
+		return true
+	}
+
+	k := c.statusBar.keyAt(ev.X)
+	if k == nil {
+		return true
+	}
+	if k.fn != nil {
+		k.fn(Event{Type: EventClick, Msg: k.title})
+	} else {
+		for _, key := range k.keys {
+			c.processKey(Event{Type: EventKey, Key: key.Key, Ch: key.Ch, Mod: key.Mod})
+		}
+	}
+	c.app.InvalidateScreen()
+
+	return true
+}
@@ -0,0 +1,101 @@
+package clui
+
+import (
+	"strings"
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestStatusBar(t *testing.T) {
+	b := initHeadless(t, 50, 10)
+	defer DeinitLibrary()
+	defer ResetHotkeys()
+
+	wnd := AddWindow(0, 0, 30, 6, "Form")
+	wnd.SetPack(Vertical)
+	wnd.SetHint("Fill in the form")
+	name := CreateEditField(wnd, 20, "John", Fixed)
+	name.SetHint("Your full name")
+	CreateEditField(wnd, 20, "Smith", Fixed)
+	ActivateControl(wnd, name)
+
+	var keys []KeyPress
+	wnd.OnKeyDown(func(ev Event, _ interface{}) bool {
+		keys = append(keys, keyPressFromEvent(ev))
+		return true
+	}, nil)
+
+	edits := 0
+	bar := NewStatusBar()
+	bar.SetText("Ready")
+	bar.AddKey("F2", "Edit", func(ev Event) {
+		edits++
+	})
+	bar.AddKey("F5", "Reload", nil)
+	SetStatusBar(bar)
+
+	if _, h := wnd.Size(); h != 6 {
+		t.Errorf("Window height must not change: %v", h)
+	}
+	wnd.SetMaximized(true)
+	if _, h := wnd.Size(); h != 9 {
+		t.Errorf("Maximized window must leave the last row for status bar: %v", h)
+	}
+	RefreshScreen()
+	checkSnapshot(t, b, "statusbar")
+
+	lastRow := func() string {
+		lines := strings.Split(b.Snapshot(), "\n")
+		return lines[9]
+	}
+	if !strings.Contains(lastRow(), "Your full name") {
+		t.Errorf("Status bar must show control hint: '%v'", lastRow())
+	}
+	SimulateKey(term.KeyTab, 0)
+	if !strings.Contains(lastRow(), "Fill in the form") {
+		t.Errorf("Status bar must show window hint: '%v'", lastRow())
+	}
+	wnd.SetHint("")
+	RefreshScreen()
+	if !strings.Contains(lastRow(), "Ready") {
+		t.Errorf("Status bar must show its text: '%v'", lastRow())
+	}
+
+	// legend with callback: the key and the click call it
+	SimulateKey(term.KeyF2, 0)
+	SimulateClick(2, 9)
+	if edits != 2 {
+		t.Errorf("F2 callback must be called twice instead of %v", edits)
+	}
+	// legend without callback emulates the key
+	SimulateClick(11, 9)
+	if len(keys) != 1 || keys[0] != (KeyPress{Key: term.KeyF5}) {
+		t.Errorf("Click on F5 legend must send F5 to the window: %v", keys)
+	}
+
+	bar.ClearKeys()
+	SimulateKey(term.KeyF2, 0)
+	if edits != 2 {
+		t.Error("Removed legend hotkey must not call the callback")
+	}
+}
+
+func TestStatusBarColorizedHint(t *testing.T) {
+	b := initHeadless(t, 20, 6)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 20, 5, "Form")
+	wnd.SetHint("<t:red>abc<t:default>defghijklmnopqrstuvwxyz")
+	SetStatusBar(NewStatusBar())
+	RefreshScreen()
+
+	// color tags do not take screen cells and are never cut
+	lines := strings.Split(b.Snapshot(), "\n")
+	if row := lines[5]; row != " abcdefghijklmnopqrs" {
+		t.Errorf("Hint must be cut by its printable width: '%v'", row)
+	}
+	if cell := b.Cell(1, 5); cell.Fg&0xFF != ColorRed {
+		t.Errorf("Hint color tags must be applied: %v", cell.Fg)
+	}
+}
@@ -16,7 +16,7 @@ Predefined hotkeys:
   Home, End - move cursor to first and last column, respectively
   Alt+Home, Alt+End - move cursor to first and last row, respectively
   PgDn, PgUp - move cursor to a screen down and up
-  Enter, F2 - emits event TableActionEdit
+  Enter, F2, double click on a cell - emits event TableActionEdit
   Insert - emits event TableActionNew
   Delete - emits event TableActionDelete
   F4 - Change sort mode
@@ -167,20 +167,21 @@ func CreateTableView(parent Control, width, height int, scale int) *TableView {
 }
 
 func (l *TableView) drawHeader() {
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
-	fg, bg := RealColor(l.fg, l.Style(), ColorTableHeaderText), RealColor(l.bg, l.Style(), ColorTableHeaderBack)
-	fgLine := RealColor(l.fg, l.Style(), ColorTableLineText)
+	fg, bg := a.RealColor(l.fg, l.Style(), ColorTableHeaderText), a.RealColor(l.bg, l.Style(), ColorTableHeaderBack)
+	fgLine := a.RealColor(l.fg, l.Style(), ColorTableLineText)
 	x, y := l.Pos()
 	w, _ := l.Size()
-	SetTextColor(fg)
-	SetBackColor(bg)
-	FillRect(x, y, w, 1, ' ')
-	parts := []rune(SysObject(ObjTableView))
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(x, y, w, 1, ' ')
+	parts := []rune(a.SysObject(ObjTableView))
 
 	for i := 0; i < w; i++ {
-		PutChar(x+i, y+1, parts[0])
+		a.PutChar(x+i, y+1, parts[0])
 	}
 	w-- // scrollbar
 
@@ -190,16 +191,16 @@ func (l *TableView) drawHeader() {
 	}
 
 	pos := 0
-	SetBackColor(bg)
+	a.SetBackColor(bg)
 	if l.showRowNo {
 		cW := l.counterWidth()
 		shift, str := AlignText("#", cW, AlignRight)
-		SetTextColor(fg)
-		DrawRawText(x+pos+shift, y, str)
+		a.SetTextColor(fg)
+		a.DrawRawText(x+pos+shift, y, str)
 		if l.showVLines {
-			SetTextColor(fgLine)
-			PutChar(x+pos+cW, y, parts[1])
-			PutChar(x+pos+cW, y+1, parts[2])
+			a.SetTextColor(fgLine)
+			a.PutChar(x+pos+cW, y, parts[1])
+			a.PutChar(x+pos+cW, y+1, parts[2])
 			pos++
 		}
 		pos = cW + dx
@@ -222,19 +223,19 @@ func (l *TableView) drawHeader() {
 			if l.columns[idx].Sort == SortDesc {
 				ch = parts[4]
 			}
-			SetTextColor(fg)
-			PutChar(x+pos+w-1, y, ch)
+			a.SetTextColor(fg)
+			a.PutChar(x+pos+w-1, y, ch)
 		}
 
 		shift, str := AlignColorizedText(l.columns[idx].Title, w+dw, l.columns[idx].Alignment)
-		SetTextColor(fg)
-		DrawText(x+pos+shift, y, str)
+		a.SetTextColor(fg)
+		a.DrawText(x+pos+shift, y, str)
 		pos += w
 
 		if l.showVLines && idx < len(l.columns)-1 {
-			SetTextColor(fgLine)
-			PutChar(x+pos, y, parts[1])
-			PutChar(x+pos, y+1, parts[2])
+			a.SetTextColor(fgLine)
+			a.PutChar(x+pos, y, parts[1])
+			a.PutChar(x+pos, y+1, parts[2])
 			pos++
 		}
 
@@ -259,27 +260,29 @@ func (l *TableView) counterWidth() int {
 func (l *TableView) drawScroll() {
 
 	pos := ThumbPosition(l.selectedRow, l.rowCount, l.height-1)
-	DrawScrollBar(l.x+l.width-1, l.y, 1, l.height-1, pos)
+	a := AppOf(l)
+	a.DrawScrollBar(l.x+l.width-1, l.y, 1, l.height-1, pos)
 
 	pos = ThumbPosition(l.selectedCol, len(l.columns), l.width-1)
-	DrawScrollBar(l.x, l.y+l.height-1, l.width-1, 1, pos)
-	PutChar(l.x+l.width-1, l.y+l.height-1, ' ')
+	a.DrawScrollBar(l.x, l.y+l.height-1, l.width-1, 1, pos)
+	a.PutChar(l.x+l.width-1, l.y+l.height-1, ' ')
 }
 
 func (l *TableView) drawCells() {
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	maxRow := l.rowCount - 1
 	rowNo := l.topRow
 	dy := 2
 	maxDy := l.height - 2
 
-	fg, bg := RealColor(l.fg, l.Style(), ColorTableText), RealColor(l.bg, l.Style(), ColorTableBack)
-	fgRow, bgRow := RealColor(l.fg, l.Style(), ColorTableSelectedText), RealColor(l.bg, l.Style(), ColorTableSelectedBack)
-	fgCell, bgCell := RealColor(l.fg, l.Style(), ColorTableActiveCellText), RealColor(l.bg, l.Style(), ColorTableActiveCellBack)
-	fgLine := RealColor(l.fg, l.Style(), ColorTableLineText)
-	parts := []rune(SysObject(ObjTableView))
+	fg, bg := a.RealColor(l.fg, l.Style(), ColorTableText), a.RealColor(l.bg, l.Style(), ColorTableBack)
+	fgRow, bgRow := a.RealColor(l.fg, l.Style(), ColorTableSelectedText), a.RealColor(l.bg, l.Style(), ColorTableSelectedBack)
+	fgCell, bgCell := a.RealColor(l.fg, l.Style(), ColorTableActiveCellText), a.RealColor(l.bg, l.Style(), ColorTableActiveCellBack)
+	fgLine := a.RealColor(l.fg, l.Style(), ColorTableLineText)
+	parts := []rune(a.SysObject(ObjTableView))
 
 	start := 0
 	if l.showRowNo {
@@ -290,12 +293,12 @@ func (l *TableView) drawCells() {
 			}
 			s := fmt.Sprintf("%v", idx+l.topRow)
 			shift, str := AlignText(s, start, AlignRight)
-			SetTextColor(fg)
-			SetBackColor(bg)
-			DrawText(l.x+shift, l.y+dy+idx-1, str)
+			a.SetTextColor(fg)
+			a.SetBackColor(bg)
+			a.DrawText(l.x+shift, l.y+dy+idx-1, str)
 			if l.showVLines {
-				SetTextColor(fgLine)
-				PutChar(l.x+start, l.y+dy+idx-1, parts[1])
+				a.SetTextColor(fgLine)
+				a.PutChar(l.x+start, l.y+dy+idx-1, parts[1])
 			}
 		}
 		if l.showVLines {
@@ -331,17 +334,17 @@ func (l *TableView) drawCells() {
 			if length+dx >= l.width-1 {
 				length = l.width - 1 - dx
 			}
-			SetTextColor(info.Fg)
-			SetBackColor(info.Bg)
-			FillRect(l.x+dx, l.y+dy, length, 1, ' ')
+			a.SetTextColor(info.Fg)
+			a.SetBackColor(info.Bg)
+			a.FillRect(l.x+dx, l.y+dy, length, 1, ' ')
 			shift, text := AlignColorizedText(info.Text, length, info.Alignment)
-			DrawText(l.x+dx+shift, l.y+dy, text)
+			a.DrawText(l.x+dx+shift, l.y+dy, text)
 
 			dx += c.Width
 			if l.showVLines && dx < l.width-1 && colNo < len(l.columns)-1 {
-				SetTextColor(fg)
-				SetBackColor(bg)
-				PutChar(l.x+dx, l.y+dy, parts[1])
+				a.SetTextColor(fg)
+				a.SetBackColor(bg)
+				a.PutChar(l.x+dx, l.y+dy, parts[1])
 				dx++
 			}
 
@@ -361,8 +364,9 @@ func (l *TableView) Draw() {
 
 	l.mtx.RLock()
 	defer l.mtx.RUnlock()
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	x, y := l.Pos()
 	w, h := l.Size()
@@ -372,9 +376,9 @@ func (l *TableView) Draw() {
 		l.onBeforeDraw(firstCol, firstRow, colCount, rowCount)
 	}
 
-	bg := RealColor(l.bg, l.Style(), ColorTableBack)
-	SetBackColor(bg)
-	FillRect(x, y+2, w, h-2, ' ')
+	bg := a.RealColor(l.bg, l.Style(), ColorTableBack)
+	a.SetBackColor(bg)
+	a.FillRect(x, y+2, w, h-2, ' ')
 	l.drawHeader()
 	l.drawScroll()
 	l.drawCells()
@@ -721,6 +725,17 @@ func (l *TableView) processMouseClick(ev Event) bool {
 	return true
 }
 
+// wheelScroll scrolls the table by a row or by a column without changing
+// the selected cell
+func (l *TableView) wheelScroll(dx, dy int) bool {
+	if dy != 0 {
+		l.topRow = clampScroll(l.topRow+dy, l.rowCount-(l.height-3))
+	} else {
+		l.topCol = clampScroll(l.topCol+dx, len(l.columns)-1)
+	}
+	return true
+}
+
 func (l *TableView) headerClicked(dx int) {
 	colID := l.mouseToCol(dx)
 	if colID == -1 {
@@ -758,6 +773,10 @@ that the control do not want or cannot process the event and the caller sends
 the event to the control parent
 */
 func (l *TableView) ProcessEvent(event Event) bool {
+	if dx, dy := WheelDelta(event); dx != 0 || dy != 0 {
+		return l.Enabled() && l.wheelScroll(dx, dy)
+	}
+
 	if !l.Active() || !l.Enabled() {
 		return false
 	}
@@ -849,6 +868,14 @@ func (l *TableView) ProcessEvent(event Event) bool {
 		}
 	case EventMouse:
 		return l.processMouseClick(event)
+	case EventDoubleClick:
+		// the first click has already selected the cell
+		if event.Y >= 2 && event.Y < l.height-1 && event.X < l.width-1 &&
+			l.topRow+event.Y-2 == l.selectedRow && l.selectedCol != -1 && l.onAction != nil {
+			ev := TableEvent{Action: TableActionEdit, Col: l.selectedCol, Row: l.selectedRow}
+			l.onAction(ev)
+		}
+		return true
 	}
 
 	return false
@@ -865,6 +892,7 @@ func (l *TableView) ShowLines() bool {
 // SetShowLines disables and enables displaying vertical
 // lines inside TableView
 func (l *TableView) SetShowLines(show bool) {
+	l.Invalidate()
 	l.showVLines = show
 }
 
@@ -879,6 +907,7 @@ func (l *TableView) ShowRowNumber() bool {
 // SetShowRowNumber turns on and off the first fixed
 // column of the table that displays the row number
 func (l *TableView) SetShowRowNumber(show bool) {
+	l.Invalidate()
 	l.showRowNo = show
 }
 
@@ -894,11 +923,13 @@ func (l *TableView) Columns() []Column {
 // Title and Width, all other column properties may
 // be undefined
 func (l *TableView) SetColumns(cols []Column) {
+	l.Invalidate()
 	l.columns = cols
 }
 
 // SetColumnInfo replaces the existing column info
 func (l *TableView) SetColumnInfo(id int, col Column) {
+	l.Invalidate()
 	if id < len(l.columns) {
 		l.columns[id] = col
 	}
@@ -911,6 +942,7 @@ func (l *TableView) RowCount() int {
 
 // SetRowCount sets the new row count
 func (l *TableView) SetRowCount(count int) {
+	l.Invalidate()
 	l.rowCount = count
 }
 
@@ -925,6 +957,7 @@ func (l *TableView) FullRowSelect() bool {
 // SetFullRowSelect enables or disables hiliting of the
 // full row that contains the selected cell
 func (l *TableView) SetFullRowSelect(fullRow bool) {
+	l.Invalidate()
 	l.fullRowSelect = fullRow
 }
 
@@ -973,6 +1006,7 @@ func (l *TableView) SelectedCol() int {
 // is selected. Set row to -1 to turn off selection.
 // The table scrolls automatically to display the column
 func (l *TableView) SetSelectedRow(row int) {
+	l.Invalidate()
 	oldSelection := l.selectedRow
 	if row >= l.rowCount {
 		l.selectedRow = l.rowCount - 1
@@ -993,6 +1027,7 @@ func (l *TableView) SetSelectedRow(row int) {
 // column is selected. Set row to -1 to turn off selection.
 // The table scrolls automatically to display the column
 func (l *TableView) SetSelectedCol(col int) {
+	l.Invalidate()
 	oldSelection := l.selectedCol
 	if col >= len(l.columns) {
 		l.selectedCol = len(l.columns) - 1
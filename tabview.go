@@ -0,0 +1,471 @@
+package clui
+
+import (
+	term "github.com/nsf/termbox-go"
+)
+
+/*
+TabView is a container that displays one of its pages at a time and
+a strip of page titles at the top or at the bottom(see SetTabPosition).
+Pages are Frames created with AddPage, all of them occupy the same area
+under the tab strip, and the title of a page is the Frame title. Do not
+change the visibility of pages directly - use SetCurrentTab.
+
+If tab titles do not fit the control, the tab strip displays arrows that
+scroll it. A user selects a page by clicking its title, or with global
+hotkeys(see HotkeyTabPrev and HotkeyTab1). Default ones:
+
+	Ctrl+PgUp, Ctrl+PgDn - select the previous or next page
+	Alt+1 .. Alt+9 - select the page by its number
+
+The default hotkeys work only with backends that report Ctrl and Alt keys,
+e.g StreamBackend. termbox does not report Ctrl+PgUp and reports Alt+1 as
+Esc followed by 1, so bind other keys with SetHotkey if termbox is used.
+
+The hotkeys work if the TabView or any control of its current page is
+active. A click on the tab strip activates the TabView itself, in this case
+Left and Right arrows, Home and End select pages as well.
+
+TabView calls onTabChange after a user selects another page: Event field
+Y is the new page number, X is the previous page number, Msg is the page
+title.
+*/
+type TabView struct {
+	BaseControl
+	current     int
+	firstTab    int
+	tabPosition int
+
+	onTabChange func(Event)
+}
+
+// tabPos is a position of a tab title in the tab strip
+type tabPos struct {
+	idx, x, w int
+}
+
+// hider is a control which visibility can be changed without updating
+// the layout
+type hider interface {
+	setHidden(hidden bool)
+}
+
+/*
+CreateTabView creates a new TabView without pages.
+parent - is container that keeps the control.
+width and height - are minimal size of the control including the tab strip.
+scale - the way of scaling the control when the parent is resized. Use DoNotScale constant if the
+control should keep its original size.
+*/
+func CreateTabView(parent Control, width, height int, scale int) *TabView {
+	t := new(TabView)
+	t.BaseControl = NewBaseControl()
+
+	if width == AutoSize {
+		width = 10
+	}
+	if height == AutoSize {
+		height = 4
+	}
+
+	t.SetSize(width, height)
+	t.SetConstraints(width, height)
+	t.parent = parent
+	t.tabPosition = TabsTop
+	t.SetTabStop(true)
+	t.SetScale(scale)
+
+	if parent != nil {
+		parent.AddChild(t)
+	}
+
+	return t
+}
+
+// AddPage creates a new page with the given title and returns it. The
+// first page becomes the current one
+func (t *TabView) AddPage(title string) *Frame {
+	f := CreateFrame(t, AutoSize, AutoSize, BorderNone, 1)
+	f.SetTitle(title)
+	return f
+}
+
+// AddChild adds a new page to the TabView. All pages except the current
+// one are hidden. Use AddPage to create a page
+func (t *TabView) AddChild(control Control) {
+	if t.ChildExists(control) {
+		panic("Double adding a child")
+	}
+
+	t.children = append(t.children, control)
+	if len(t.children) > 1 {
+		if h, ok := control.(hider); ok {
+			h.setHidden(true)
+		}
+	}
+
+	fitParents(t)
+}
+
+// PageCount returns the number of pages
+func (t *TabView) PageCount() int {
+	return len(t.children)
+}
+
+// Page returns the page by its number or nil if the number is out of range
+func (t *TabView) Page(idx int) Control {
+	if idx < 0 || idx >= len(t.children) {
+		return nil
+	}
+	return t.children[idx]
+}
+
+// CurrentTab returns the number of the displayed page or -1 if TabView
+// has no pages
+func (t *TabView) CurrentTab() int {
+	if len(t.children) == 0 {
+		return -1
+	}
+	return t.current
+}
+
+// SetCurrentTab displays the page by its number. If a control of the
+// previous page was active, the first control of the new page is
+// activated. Returns false if the number is out of range
+func (t *TabView) SetCurrentTab(idx int) bool {
+	t.Invalidate()
+	if idx < 0 || idx >= len(t.children) {
+		return false
+	}
+	if idx == t.current {
+		return true
+	}
+
+	old, page := t.children[t.current], t.children[idx]
+	focused := ActiveControl(old) != nil || old.Active()
+	if h, ok := old.(hider); ok {
+		h.setHidden(true)
+	}
+	if h, ok := page.(hider); ok {
+		h.setHidden(false)
+	}
+	t.current = idx
+	t.ensureTabVisible()
+
+	if focused {
+		var target Control = t
+		fnTab := func(c Control) bool {
+			return c.TabStop() && c.Visible() && c.Enabled()
+		}
+		if ctrl := FindFirstControl(page, fnTab); ctrl != nil {
+			target = ctrl
+		}
+
+		var root Control = t
+		for root.Parent() != nil {
+			root = root.Parent()
+		}
+		ActivateControl(root, target)
+	}
+	return true
+}
+
+// selectTab displays the page and emits OnTabChange event
+func (t *TabView) selectTab(idx int) {
+	old := t.current
+	if idx == old || !t.SetCurrentTab(idx) {
+		return
+	}
+
+	if t.onTabChange != nil {
+		t.onTabChange(Event{X: old, Y: idx, Msg: t.children[idx].Title()})
+	}
+}
+
+// TabPosition returns where the tab strip is displayed: TabsTop or
+// TabsBottom
+func (t *TabView) TabPosition() int {
+	return t.tabPosition
+}
+
+// SetTabPosition changes where the tab strip is displayed: TabsTop or
+// TabsBottom
+func (t *TabView) SetTabPosition(pos int) {
+	t.Invalidate()
+	t.tabPosition = pos
+	t.PlaceChildren()
+}
+
+// OnTabChange sets the callback that is called after a user selects
+// another page
+func (t *TabView) OnTabChange(fn func(Event)) {
+	t.onTabChange = fn
+}
+
+// MinimalSize returns the size of the largest page plus the tab strip
+func (t *TabView) MinimalSize() (w int, h int) {
+	for _, page := range t.children {
+		pw, ph := page.MinimalSize()
+		if pw > w {
+			w = pw
+		}
+		if ph > h {
+			h = ph
+		}
+	}
+	h++
+
+	if w < t.minW {
+		w = t.minW
+	}
+	if h < t.minH {
+		h = t.minH
+	}
+	return w, h
+}
+
+// ResizeChildren makes all pages occupy the whole area under the tab
+// strip
+func (t *TabView) ResizeChildren() {
+	for _, page := range t.children {
+		page.SetSize(t.width, t.height-1)
+		page.ResizeChildren()
+	}
+}
+
+// PlaceChildren puts all pages at the same position under(or above) the
+// tab strip
+func (t *TabView) PlaceChildren() {
+	y := t.y + 1
+	if t.tabPosition == TabsBottom {
+		y = t.y
+	}
+
+	for _, page := range t.children {
+		page.SetPos(t.x, y)
+		page.PlaceChildren()
+	}
+}
+
+// stripY returns the row of the tab strip
+func (t *TabView) stripY() int {
+	if t.tabPosition == TabsBottom {
+		return t.y + t.height - 1
+	}
+	return t.y
+}
+
+func (t *TabView) tabWidth(idx int) int {
+	return TextWidth(UnColorizeText(t.children[idx].Title())) + 2
+}
+
+// tabLayout returns the positions of the displayed tabs relative to the
+// strip start and whether scroll arrows are displayed. A tab is
+// truncated only if it is the only displayed one
+func (t *TabView) tabLayout() (tabs []tabPos, left, right bool) {
+	left = t.firstTab > 0
+	start := 0
+	if left {
+		start = 1
+	}
+
+	total := start
+	for i := t.firstTab; i < len(t.children); i++ {
+		if i > t.firstTab {
+			total++
+		}
+		total += t.tabWidth(i)
+	}
+	avail := t.width
+	right = total > avail
+	if right {
+		avail--
+	}
+
+	pos := start
+	for i := t.firstTab; i < len(t.children); i++ {
+		w := t.tabWidth(i)
+		if pos+w > avail {
+			if i != t.firstTab {
+				break
+			}
+			w = avail - pos
+		}
+		tabs = append(tabs, tabPos{idx: i, x: pos, w: w})
+		pos += w + 1
+	}
+	return tabs, left, right
+}
+
+// ensureTabVisible scrolls the tab strip to display the whole title of
+// the current page
+func (t *TabView) ensureTabVisible() {
+	if t.current < t.firstTab {
+		t.firstTab = t.current
+		return
+	}
+
+	for t.firstTab < t.current {
+		tabs, _, _ := t.tabLayout()
+		visible := false
+		for _, tab := range tabs {
+			if tab.idx == t.current && tab.w == t.tabWidth(tab.idx) {
+				visible = true
+			}
+		}
+		if visible {
+			return
+		}
+		t.firstTab++
+	}
+}
+
+// Draw repaints the control on its View surface
+func (t *TabView) Draw() {
+	if t.hidden {
+		return
+	}
+
+	a := AppOf(t)
+	a.PushAttributes()
+	defer a.PopAttributes()
+
+	fg, bg := a.RealColor(t.fg, t.Style(), ColorTabText), a.RealColor(t.bg, t.Style(), ColorTabBack)
+	fgCurr, bgCurr := a.RealColor(t.fgActive, t.Style(), ColorTabActiveText), a.RealColor(t.bgActive, t.Style(), ColorTabActiveBack)
+	if t.Active() {
+		fgCurr, bgCurr = a.RealColor(t.fgActive, t.Style(), ColorSelectionText), a.RealColor(t.bgActive, t.Style(), ColorSelectionBack)
+	}
+	parts := []rune(a.SysObject(ObjScrollBar))
+	chLeft, chRight := parts[4], parts[5]
+	chSep := []rune(a.SysObject(ObjSingleBorder))[1]
+
+	y := t.stripY()
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(t.x, y, t.width, 1, ' ')
+
+	tabs, left, right := t.tabLayout()
+	if left {
+		a.PutChar(t.x, y, chLeft)
+	}
+	if right {
+		a.PutChar(t.x+t.width-1, y, chRight)
+	}
+	for i, tab := range tabs {
+		if i > 0 {
+			a.SetTextColor(fg)
+			a.SetBackColor(bg)
+			a.PutChar(t.x+tab.x-1, y, chSep)
+		}
+		if tab.idx == t.current {
+			a.SetTextColor(fgCurr)
+			a.SetBackColor(bgCurr)
+		} else {
+			a.SetTextColor(fg)
+			a.SetBackColor(bg)
+		}
+		a.FillRect(t.x+tab.x, y, tab.w, 1, ' ')
+		text := SliceColorized(" "+t.children[tab.idx].Title()+" ", 0, tab.w)
+		a.DrawText(t.x+tab.x, y, text)
+	}
+
+	t.DrawChildren()
+}
+
+// processStripClick selects the page which title is clicked or scrolls
+// the tab strip if an arrow is clicked
+func (t *TabView) processStripClick(ev Event) bool {
+	if ev.Y != t.stripY() {
+		return false
+	}
+
+	dx := ev.X - t.x
+	tabs, left, right := t.tabLayout()
+	if left && dx == 0 {
+		t.firstTab--
+		return true
+	}
+	if right && dx == t.width-1 {
+		t.firstTab++
+		return true
+	}
+
+	for _, tab := range tabs {
+		if dx >= tab.x && dx < tab.x+tab.w {
+			t.selectTab(tab.idx)
+			break
+		}
+	}
+	return true
+}
+
+// switchTab runs a page hotkey action for the closest TabView that is
+// active or contains the active control. Returns false if there is no
+// such TabView or the page does not exist, so the key goes to the window
+func (c *Composer) switchTab(action HotkeyAction) bool {
+	top := c.topWindow()
+	if top == nil || c.consumer != nil {
+		return false
+	}
+
+	var tabs *TabView
+	for ctrl := ActiveControl(top); ctrl != nil && ctrl != top; ctrl = ctrl.Parent() {
+		if t, ok := ctrl.(*TabView); ok {
+			tabs = t
+			break
+		}
+	}
+	if tabs == nil || !tabs.Enabled() || !tabs.Visible() {
+		return false
+	}
+
+	idx := tabs.current
+	switch action {
+	case HotkeyTabPrev:
+		idx--
+	case HotkeyTabNext:
+		idx++
+	default:
+		idx = int(action - HotkeyTab1)
+	}
+	if idx < 0 || idx >= len(tabs.children) {
+		return action == HotkeyTabPrev || action == HotkeyTabNext
+	}
+	tabs.selectTab(idx)
+	return true
+}
+
+/*
+ProcessEvent processes all events come from the control parent. If a control
+processes an event it should return true. If the method returns false it means
+that the control do not want or cannot process the event and the caller sends
+the event to the control parent
+*/
+func (t *TabView) ProcessEvent(ev Event) bool {
+	if !t.Active() || !t.Enabled() {
+		return false
+	}
+
+	switch ev.Type {
+	case EventKey:
+		switch ev.Key {
+		case term.KeyArrowLeft:
+			t.selectTab(t.current - 1)
+		case term.KeyArrowRight:
+			t.selectTab(t.current + 1)
+		case term.KeyHome:
+			t.selectTab(0)
+		case term.KeyEnd:
+			t.selectTab(len(t.children) - 1)
+		default:
+			return false
+		}
+		return true
+	case EventMouse:
+		if ev.Key == term.MouseLeft {
+			return t.processStripClick(ev)
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package clui
+
+import (
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestTabView(t *testing.T) {
+	b := initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 30, 8, "Settings")
+	tabs := CreateTabView(wnd, 20, 5, 1)
+	general := tabs.AddPage("General")
+	general.SetPack(Vertical)
+	name := CreateEditField(general, 10, "", Fixed)
+	network := tabs.AddPage("Network")
+	network.SetPack(Vertical)
+	proxy := CreateEditField(network, 10, "proxy", Fixed)
+	tabs.AddPage("Advanced")
+	ActivateControl(wnd, name)
+
+	var changes []Event
+	tabs.OnTabChange(func(ev Event) {
+		changes = append(changes, ev)
+	})
+
+	if tabs.CurrentTab() != 0 || !general.Visible() || network.Visible() {
+		t.Fatal("Only the first page must be visible")
+	}
+	gx, gy := general.Pos()
+	nx, ny := network.Pos()
+	if gx != nx || gy != ny || gy != 2 {
+		t.Errorf("Pages must be placed under the tab strip: %v:%v %v:%v", gx, gy, nx, ny)
+	}
+
+	// Alt and a number switches pages and moves focus to the new page
+	SimulateEvent(Event{Type: EventKey, Ch: '2', Mod: term.ModAlt})
+	if tabs.CurrentTab() != 1 || !proxy.Active() || name.Title() != "" {
+		t.Fatalf("Alt+2 must select the second page: %v", tabs.CurrentTab())
+	}
+	if len(changes) != 1 || changes[0].X != 0 || changes[0].Y != 1 || changes[0].Msg != "Network" {
+		t.Errorf("Invalid change events: %v", changes)
+	}
+	RefreshScreen()
+	checkSnapshot(t, b, "tabview")
+
+	modKey(term.KeyPgdn, ModCtrl)
+	if tabs.CurrentTab() != 2 || !tabs.Active() {
+		t.Errorf("Ctrl+PgDn must select the next page: %v", tabs.CurrentTab())
+	}
+	modKey(term.KeyPgup, ModCtrl)
+	if tabs.CurrentTab() != 1 {
+		t.Errorf("Ctrl+PgUp must select the previous page: %v", tabs.CurrentTab())
+	}
+	SimulateEvent(Event{Type: EventKey, Ch: '1', Mod: term.ModAlt})
+	if tabs.CurrentTab() != 0 {
+		t.Errorf("Alt+1 must select the first page: %v", tabs.CurrentTab())
+	}
+	// Ctrl+N is not a prefix by default
+	SimulateKey(term.KeyCtrlN, 0)
+	modKey(term.KeyPgdn, ModCtrl)
+	modKey(term.KeyPgdn, ModCtrl)
+
+	// the arrow scrolls the tab strip, a click on a title selects the page
+	SimulateClick(1, 1)
+	SimulateClick(3, 1)
+	if tabs.CurrentTab() != 0 || !tabs.Active() {
+		t.Errorf("Click must select the page: %v", tabs.CurrentTab())
+	}
+	SimulateKey(term.KeyArrowRight, 0)
+	if tabs.CurrentTab() != 1 {
+		t.Errorf("Right arrow must select the next page: %v", tabs.CurrentTab())
+	}
+	SimulateKey(term.KeyTab, 0)
+	if !proxy.Active() {
+		t.Error("Tab must activate a control of the current page")
+	}
+}
+
+func TestTabViewOverflow(t *testing.T) {
+	b := initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 24, 8, "Tabs")
+	tabs := CreateTabView(wnd, 20, 5, Fixed)
+	for _, title := range []string{"First", "Second", "Third", "Fourth"} {
+		tabs.AddPage(title)
+	}
+	tabs.SetTabPosition(TabsBottom)
+	ActivateControl(wnd, tabs)
+
+	SimulateKey(term.KeyEnd, 0)
+	RefreshScreen()
+	checkSnapshot(t, b, "tabview_overflow")
+	x, y := tabs.Pos()
+	_, h := tabs.Size()
+	if px, py := tabs.Page(3).Pos(); px != x || py != y {
+		t.Errorf("Pages must be above the tab strip: %v:%v", px, py)
+	}
+
+	SimulateClick(x, y+h-1)
+	if tabs.firstTab != tabs.CurrentTab()-2 {
+		t.Errorf("Left arrow must scroll the tab strip: %v", tabs.firstTab)
+	}
+}
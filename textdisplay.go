@@ -55,15 +55,16 @@ func (l *TextDisplay) drawText() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
-	bg, fg := RealColor(l.bg, l.Style(), ColorEditBack), RealColor(l.fg, l.Style(), ColorEditText)
+	bg, fg := a.RealColor(l.bg, l.Style(), ColorEditBack), a.RealColor(l.fg, l.Style(), ColorEditText)
 	if l.Active() {
-		bg, fg = RealColor(l.bg, l.Style(), ColorEditActiveBack), RealColor(l.fg, l.Style(), ColorEditActiveText)
+		bg, fg = a.RealColor(l.bg, l.Style(), ColorEditActiveBack), a.RealColor(l.fg, l.Style(), ColorEditActiveText)
 	}
-	SetTextColor(fg)
-	SetBackColor(bg)
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
 
 	ind := 0
 	for ind < l.height {
@@ -80,7 +81,7 @@ func (l *TextDisplay) drawText() {
 
 		if str != "" {
 			str = SliceColorized(str, 0, l.width)
-			DrawText(l.x, l.y+ind, str)
+			a.DrawText(l.x, l.y+ind, str)
 		}
 
 		ind++
@@ -93,20 +94,21 @@ func (l *TextDisplay) Draw() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	x, y := l.Pos()
 	w, h := l.Size()
 
-	bg, fg := RealColor(l.bg, l.Style(), ColorEditBack), RealColor(l.fg, l.Style(), ColorEditText)
+	bg, fg := a.RealColor(l.bg, l.Style(), ColorEditBack), a.RealColor(l.fg, l.Style(), ColorEditText)
 	if l.Active() {
-		bg, fg = RealColor(l.bg, l.Style(), ColorEditActiveBack), RealColor(l.fg, l.Style(), ColorEditActiveText)
+		bg, fg = a.RealColor(l.bg, l.Style(), ColorEditActiveBack), a.RealColor(l.fg, l.Style(), ColorEditActiveText)
 	}
 
-	SetTextColor(fg)
-	SetBackColor(bg)
-	FillRect(x, y, w, h, ' ')
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(x, y, w, h, ' ')
 	l.drawText()
 }
 
@@ -180,6 +182,18 @@ that the control do not want or cannot process the event and the caller sends
 the event to the control parent
 */
 func (l *TextDisplay) ProcessEvent(event Event) bool {
+	if _, dy := WheelDelta(event); dy != 0 {
+		if !l.Enabled() {
+			return false
+		}
+		if dy < 0 {
+			l.moveUp(1)
+		} else {
+			l.moveDown(1)
+		}
+		return true
+	}
+
 	if !l.Active() || !l.Enabled() {
 		return false
 	}
@@ -249,6 +263,7 @@ func (l *TextDisplay) LineCount() int {
 }
 
 func (l *TextDisplay) SetLineCount(lineNo int) {
+	l.Invalidate()
 	if l.topLine == lineNo {
 		return
 	}
@@ -268,6 +283,7 @@ func (l *TextDisplay) TopLine() int {
 }
 
 func (l *TextDisplay) SetTopLine(top int) {
+	l.Invalidate()
 	if top < l.lineCount {
 		l.topLine = top
 
@@ -0,0 +1,1003 @@
+package clui
+
+import (
+	"strings"
+
+	term "github.com/nsf/termbox-go"
+)
+
+// the maximum number of changes that TextEdit can undo
+const maxUndoSteps = 100
+
+// editKind is the type of the last change of TextEdit text. Consecutive
+// typing is undone as one step
+type editKind int
+
+const (
+	editNone editKind = iota
+	editTyping
+	editOther
+)
+
+// textEditState is a snapshot of TextEdit text for undo and redo
+type textEditState struct {
+	lines    [][]rune
+	row, col int
+}
+
+/*
+TextEdit is a multi-line text editor. Content is scrollable and, if word
+wrap is off, long lines are scrolled horizontally. Tab characters are
+replaced with spaces to the next tab stop.
+
+Keys: arrows, Home and End move the cursor by character or line, Alt+Left
+and Alt+Right move it by word, PgUp and PgDn move it by page, Alt+Home and
+Alt+End move it to the beginning and the end of the text. Text is selected
+with mouse or with keyboard: Ctrl+Space starts selection and cursor keys
+extend it until Ctrl+Space is pressed again or the text is changed, Esc
+removes selection, Ctrl+A selects the whole text. Ctrl+C, Ctrl+X, and
+Ctrl+V copy, cut and paste selected text, Ctrl+Z and Ctrl+Y undo and redo
+changes. Tab inserts spaces, but if WantTabs is false, Tab moves focus to
+the next control.
+
+TextEdit calls onChange every time its text is changed. Event field Msg
+contains the new text.
+*/
+type TextEdit struct {
+	BaseControl
+	lines [][]rune
+	// cursor position: line number and rune index in the line
+	row, col int
+	// the column that the cursor tries to keep while moving up and down
+	wantCol int
+	// the first visible row(a line or a part of wrapped line) and column
+	topLine   int
+	leftShift int
+	// selection is the text between the anchor(selRow, selCol) and the
+	// cursor. marking is true after Ctrl+Space: moving the cursor extends
+	// the selection. dragging is true while a user selects text with mouse
+	selected bool
+	selRow   int
+	selCol   int
+	marking  bool
+	dragging bool
+
+	wordWrap bool
+	readonly bool
+	tabSize  int
+	wantTabs bool
+
+	undo     []textEditState
+	redo     []textEditState
+	lastEdit editKind
+
+	onChange func(Event)
+}
+
+/*
+CreateTextEdit creates a new multi-line editor.
+parent - is container that keeps the control.
+width and height - are minimal size of the control.
+scale - the way of scaling the control when the parent is resized. Use DoNotScale constant if the
+control should keep its original size.
+*/
+func CreateTextEdit(parent Control, width, height int, scale int) *TextEdit {
+	e := new(TextEdit)
+	e.BaseControl = NewBaseControl()
+
+	if height == AutoSize {
+		height = 3
+	}
+	if width == AutoSize {
+		width = 10
+	}
+
+	e.SetSize(width, height)
+	e.SetConstraints(width, height)
+	e.lines = [][]rune{{}}
+	e.tabSize = 4
+	e.wantTabs = true
+	e.parent = parent
+
+	e.SetTabStop(true)
+	e.SetScale(scale)
+
+	if parent != nil {
+		parent.AddChild(e)
+	}
+
+	return e
+}
+
+// Text returns the edited text. Lines are separated with '\n'
+func (e *TextEdit) Text() string {
+	lines := make([]string, len(e.lines))
+	for i, line := range e.lines {
+		lines[i] = string(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SetText replaces the content of the control, moves the cursor to the
+// beginning of the text and clears undo history
+func (e *TextEdit) SetText(text string) {
+	e.Invalidate()
+	e.lines = [][]rune{{}}
+	e.row, e.col = 0, 0
+	e.insertText(text)
+	e.row, e.col, e.wantCol = 0, 0, 0
+	e.topLine, e.leftShift = 0, 0
+	e.selected, e.marking = false, false
+	e.undo, e.redo = nil, nil
+	e.lastEdit = editNone
+	e.changed()
+}
+
+// LineCount returns the number of lines in the text
+func (e *TextEdit) LineCount() int {
+	return len(e.lines)
+}
+
+// CursorPos returns the line number and the character number in the line
+// of the cursor
+func (e *TextEdit) CursorPos() (line int, col int) {
+	return e.row, e.col
+}
+
+// SetCursorPos moves the cursor to the given line and character in the
+// line and removes selection. Too big values move the cursor to the end
+// of the line or text
+func (e *TextEdit) SetCursorPos(line, col int) {
+	e.Invalidate()
+	e.marking = false
+	row, col := e.clampPos(line, col)
+	e.moveTo(row, col, false)
+}
+
+func (e *TextEdit) clampPos(row, col int) (int, int) {
+	if row >= len(e.lines) {
+		row = len(e.lines) - 1
+	}
+	if row < 0 {
+		row = 0
+	}
+	if col > len(e.lines[row]) {
+		col = len(e.lines[row])
+	}
+	if col < 0 {
+		col = 0
+	}
+	return row, col
+}
+
+// ReadOnly returns true if a user cannot change the text
+func (e *TextEdit) ReadOnly() bool {
+	return e.readonly
+}
+
+// SetReadOnly enables or disables text changes by a user. Read-only text
+// can be scrolled, selected and copied
+func (e *TextEdit) SetReadOnly(readonly bool) {
+	e.readonly = readonly
+}
+
+// WordWrap returns if the word wrap is enabled. In word wrap mode long
+// lines are displayed on a few control lines and there is no horizontal
+// scrollbar
+func (e *TextEdit) WordWrap() bool {
+	return e.wordWrap
+}
+
+// SetWordWrap enables or disables word wrap mode
+func (e *TextEdit) SetWordWrap(wrap bool) {
+	e.Invalidate()
+	if wrap == e.wordWrap {
+		return
+	}
+
+	e.wordWrap = wrap
+	e.topLine, e.leftShift = 0, 0
+	e.ensureVisible()
+}
+
+// TabSize returns the distance between tab stops
+func (e *TextEdit) TabSize() int {
+	return e.tabSize
+}
+
+// SetTabSize changes the distance between tab stops. It does not change
+// the existing text
+func (e *TextEdit) SetTabSize(size int) {
+	e.Invalidate()
+	if size > 0 {
+		e.tabSize = size
+	}
+}
+
+// WantTabs returns true if Tab key inserts spaces instead of moving focus
+// to the next control
+func (e *TextEdit) WantTabs() bool {
+	return e.wantTabs
+}
+
+// SetWantTabs changes the way Tab key is processed: if want is true the
+// key inserts spaces to the next tab stop, otherwise the key moves focus
+// to the next control
+func (e *TextEdit) SetWantTabs(want bool) {
+	e.wantTabs = want
+}
+
+// OnChange sets the callback that is called when the text is changed
+func (e *TextEdit) OnChange(fn func(Event)) {
+	e.onChange = fn
+}
+
+func (e *TextEdit) changed() {
+	if e.onChange != nil {
+		e.onChange(Event{Msg: e.Text()})
+	}
+}
+
+// text area size: the last column is the vertical scrollbar, the last
+// row is the horizontal one if word wrap is off
+func (e *TextEdit) textWidth() int {
+	if e.width < 2 {
+		return 1
+	}
+	return e.width - 1
+}
+
+func (e *TextEdit) textHeight() int {
+	if e.wordWrap || e.height < 2 {
+		return e.height
+	}
+	return e.height - 1
+}
+
+// rowStarts returns the screen columns where the rows of the line start.
+// In word wrap mode a wide character that does not fit the end of a row
+// moves to the next row
+func (e *TextEdit) rowStarts(id int) []int {
+	if !e.wordWrap {
+		return []int{0}
+	}
+	// the cursor after the last character needs a cell, too
+	return wrapColumns(string(e.lines[id])+" ", e.textWidth())
+}
+
+// lineRowCount returns the number of rows that the line takes
+func (e *TextEdit) lineRowCount(id int) int {
+	return len(e.rowStarts(id))
+}
+
+func (e *TextEdit) virtualHeight() int {
+	if !e.wordWrap {
+		return len(e.lines)
+	}
+
+	h := 0
+	for i := range e.lines {
+		h += e.lineRowCount(i)
+	}
+	return h
+}
+
+func (e *TextEdit) virtualWidth() int {
+	w := 0
+	for _, line := range e.lines {
+		if lw := TextWidth(string(line)) + 1; lw > w {
+			w = lw
+		}
+	}
+	return w
+}
+
+// toScreen converts a text position to a row and column in the whole
+// text area(not taking into account scrolling)
+func (e *TextEdit) toScreen(row, col int) (int, int) {
+	vcol := columnOf(runeWidths(e.lines[row]), col)
+	if !e.wordWrap {
+		return row, vcol
+	}
+
+	vrow := 0
+	for i := 0; i < row; i++ {
+		vrow += e.lineRowCount(i)
+	}
+	starts := e.rowStarts(row)
+	r := len(starts) - 1
+	for r > 0 && starts[r] > vcol {
+		r--
+	}
+	return vrow + r, vcol - starts[r]
+}
+
+// fromScreen converts a row and column of the text area to the closest
+// text position
+func (e *TextEdit) fromScreen(vrow, vcol int) (int, int) {
+	if vrow < 0 {
+		vrow = 0
+	}
+	if vcol < 0 {
+		vcol = 0
+	}
+
+	if !e.wordWrap {
+		row, _ := e.clampPos(vrow, 0)
+		return row, clusterAtColumn(runeWidths(e.lines[row]), vcol)
+	}
+
+	w := e.textWidth()
+	if vcol >= w {
+		vcol = w - 1
+	}
+	last := len(e.lines) - 1
+	for i, line := range e.lines {
+		starts := e.rowStarts(i)
+		if vrow < len(starts) || i == last {
+			if vrow >= len(starts) {
+				vrow = len(starts) - 1
+			}
+			widths := runeWidths(line)
+			col := clusterAtColumn(widths, starts[vrow]+vcol)
+			// a position after the end of a row is the beginning of
+			// the next row, so the cursor stays before the last character
+			if vrow+1 < len(starts) && columnOf(widths, col) >= starts[vrow+1] {
+				col = prevCluster(line, col)
+			}
+			return i, col
+		}
+		vrow -= len(starts)
+	}
+
+	return 0, 0
+}
+
+// ensureVisible scrolls the text to make the cursor visible
+func (e *TextEdit) ensureVisible() {
+	vrow, vcol := e.toScreen(e.row, e.col)
+	h := e.textHeight()
+	if vrow < e.topLine {
+		e.topLine = vrow
+	} else if vrow >= e.topLine+h {
+		e.topLine = vrow - h + 1
+	}
+
+	if e.wordWrap {
+		e.leftShift = 0
+		return
+	}
+	w := e.textWidth()
+	if vcol < e.leftShift {
+		e.leftShift = vcol
+	} else if vcol >= e.leftShift+w {
+		e.leftShift = vcol - w + 1
+	}
+}
+
+// selection returns the beginning and the end of the selected text
+func (e *TextEdit) selection() (row1, col1, row2, col2 int) {
+	row1, col1, row2, col2 = e.selRow, e.selCol, e.row, e.col
+	if row1 > row2 || (row1 == row2 && col1 > col2) {
+		row1, col1, row2, col2 = row2, col2, row1, col1
+	}
+	return
+}
+
+func (e *TextEdit) inSelection(row, col int) bool {
+	if !e.selected {
+		return false
+	}
+
+	row1, col1, row2, col2 := e.selection()
+	if row < row1 || row > row2 {
+		return false
+	}
+	if row == row1 && col < col1 {
+		return false
+	}
+	if row == row2 && col >= col2 {
+		return false
+	}
+	return true
+}
+
+// SelectedText returns the selected text or empty string if nothing
+// is selected
+func (e *TextEdit) SelectedText() string {
+	if !e.selected {
+		return ""
+	}
+
+	row1, col1, row2, col2 := e.selection()
+	if row1 == row2 {
+		return string(e.lines[row1][col1:col2])
+	}
+
+	parts := []string{string(e.lines[row1][col1:])}
+	for i := row1 + 1; i < row2; i++ {
+		parts = append(parts, string(e.lines[i]))
+	}
+	parts = append(parts, string(e.lines[row2][:col2]))
+	return strings.Join(parts, "\n")
+}
+
+// SelectAll selects the whole text and moves the cursor to its end
+func (e *TextEdit) SelectAll() {
+	e.marking = false
+	e.selected = true
+	e.selRow, e.selCol = 0, 0
+	last := len(e.lines) - 1
+	e.row, e.col = last, len(e.lines[last])
+	e.wantCol = e.col
+	e.ensureVisible()
+}
+
+// moveTo moves the cursor. If extend is true the selection is extended
+// to the new cursor position, otherwise the selection is removed
+func (e *TextEdit) moveTo(row, col int, extend bool) {
+	if !extend {
+		e.selected = false
+	} else if !e.selected {
+		e.selected = true
+		e.selRow, e.selCol = e.row, e.col
+	}
+
+	e.row, e.col = row, col
+	_, e.wantCol = e.toScreen(row, col)
+	e.lastEdit = editNone
+	e.ensureVisible()
+}
+
+func (e *TextEdit) charLeft() {
+	if e.col > 0 {
+		e.moveTo(e.row, prevCluster(e.lines[e.row], e.col), e.marking)
+	} else if e.row > 0 {
+		e.moveTo(e.row-1, len(e.lines[e.row-1]), e.marking)
+	}
+}
+
+func (e *TextEdit) charRight() {
+	if e.col < len(e.lines[e.row]) {
+		e.moveTo(e.row, nextCluster(e.lines[e.row], e.col), e.marking)
+	} else if e.row < len(e.lines)-1 {
+		e.moveTo(e.row+1, 0, e.marking)
+	}
+}
+
+func (e *TextEdit) wordLeft() {
+	if e.col == 0 {
+		e.charLeft()
+		return
+	}
+
+	e.moveTo(e.row, prevWordStart(e.lines[e.row], e.col), e.marking)
+}
+
+func (e *TextEdit) wordRight() {
+	if e.col == len(e.lines[e.row]) {
+		e.charRight()
+		return
+	}
+
+	e.moveTo(e.row, nextWordStart(e.lines[e.row], e.col), e.marking)
+}
+
+// moveVert moves the cursor dy rows down(or up if dy is negative)
+// keeping the column
+func (e *TextEdit) moveVert(dy int) {
+	vrow, _ := e.toScreen(e.row, e.col)
+	want := e.wantCol
+	row, col := e.fromScreen(vrow+dy, want)
+	e.moveTo(row, col, e.marking)
+	e.wantCol = want
+}
+
+// movePage scrolls the text one page down(or up if dir is negative) and
+// moves the cursor by the same number of rows
+func (e *TextEdit) movePage(dir int) {
+	h := e.textHeight()
+	top := e.topLine + dir*h
+	if maxTop := e.virtualHeight() - h; top > maxTop {
+		top = maxTop
+	}
+	if top < 0 {
+		top = 0
+	}
+
+	e.moveVert(dir * h)
+	e.topLine = top
+	e.ensureVisible()
+}
+
+func (e *TextEdit) state() textEditState {
+	lines := make([][]rune, len(e.lines))
+	for i, line := range e.lines {
+		lines[i] = append([]rune(nil), line...)
+	}
+	return textEditState{lines: lines, row: e.row, col: e.col}
+}
+
+func (e *TextEdit) restore(st textEditState) {
+	e.lines = st.lines
+	e.row, e.col = st.row, st.col
+	e.selected, e.marking = false, false
+	e.lastEdit = editNone
+	_, e.wantCol = e.toScreen(e.row, e.col)
+	e.ensureVisible()
+	e.changed()
+}
+
+// beginEdit saves the text for undo before a change. Consecutive typing
+// is saved only once. Returns false if the text cannot be changed
+func (e *TextEdit) beginEdit(kind editKind) bool {
+	if e.readonly {
+		return false
+	}
+
+	if kind != editTyping || e.lastEdit != editTyping {
+		e.undo = append(e.undo, e.state())
+		if len(e.undo) > maxUndoSteps {
+			e.undo = e.undo[1:]
+		}
+	}
+	e.redo = nil
+	e.lastEdit = kind
+	e.marking = false
+	return true
+}
+
+func (e *TextEdit) endEdit() {
+	_, e.wantCol = e.toScreen(e.row, e.col)
+	e.ensureVisible()
+	e.changed()
+}
+
+// expandTabs replaces tab characters with spaces. start is the screen
+// column of the first character of the line
+func (e *TextEdit) expandTabs(line []rune, start int) []rune {
+	res := make([]rune, 0, len(line))
+	for _, r := range line {
+		if r != '\t' {
+			res = append(res, r)
+			continue
+		}
+		for n := e.tabSize - (start+TextWidth(string(res)))%e.tabSize; n > 0; n-- {
+			res = append(res, ' ')
+		}
+	}
+	return res
+}
+
+// insertText inserts a text at the cursor position and moves the cursor
+// to the end of the inserted text
+func (e *TextEdit) insertText(text string) {
+	text = strings.Replace(text, "\r\n", "\n", -1)
+	parts := strings.Split(text, "\n")
+
+	line := e.lines[e.row]
+	head := append([]rune(nil), line[:e.col]...)
+	tail := append([]rune(nil), line[e.col:]...)
+	first := e.expandTabs([]rune(parts[0]), TextWidth(string(head)))
+
+	if len(parts) == 1 {
+		e.lines[e.row] = append(append(head, first...), tail...)
+		e.col += len(first)
+		return
+	}
+
+	added := make([][]rune, 0, len(parts))
+	added = append(added, append(head, first...))
+	for _, s := range parts[1 : len(parts)-1] {
+		added = append(added, e.expandTabs([]rune(s), 0))
+	}
+	last := e.expandTabs([]rune(parts[len(parts)-1]), 0)
+	col := len(last)
+	added = append(added, append(last, tail...))
+
+	rest := e.lines[e.row+1:]
+	e.lines = append(append(e.lines[:e.row:e.row], added...), rest...)
+	e.row += len(parts) - 1
+	e.col = col
+}
+
+// deleteRange removes the text between two positions and moves the cursor
+// to the beginning of the removed text
+func (e *TextEdit) deleteRange(row1, col1, row2, col2 int) {
+	line := append(append([]rune(nil), e.lines[row1][:col1]...), e.lines[row2][col2:]...)
+	rest := e.lines[row2+1:]
+	e.lines = append(append(e.lines[:row1:row1], line), rest...)
+	e.row, e.col = row1, col1
+}
+
+func (e *TextEdit) deleteSelection() bool {
+	if !e.selected {
+		return false
+	}
+
+	e.deleteRange(e.selection())
+	e.selected = false
+	return true
+}
+
+func (e *TextEdit) insertRune(ch rune) {
+	kind := editTyping
+	if e.selected {
+		kind = editOther
+	}
+	if !e.beginEdit(kind) {
+		return
+	}
+
+	e.deleteSelection()
+	e.insertText(string(ch))
+	e.endEdit()
+}
+
+// InsertText replaces the selected text with the given one, or inserts the
+// text at the cursor position if nothing is selected
+func (e *TextEdit) InsertText(text string) {
+	if !e.beginEdit(editOther) {
+		return
+	}
+
+	e.deleteSelection()
+	e.insertText(text)
+	e.endEdit()
+}
+
+func (e *TextEdit) insertTab() {
+	col := columnOf(runeWidths(e.lines[e.row]), e.col)
+	e.InsertText(strings.Repeat(" ", e.tabSize-col%e.tabSize))
+}
+
+func (e *TextEdit) backspace() {
+	if e.readonly || (!e.selected && e.row == 0 && e.col == 0) {
+		return
+	}
+
+	e.beginEdit(editOther)
+	if !e.deleteSelection() {
+		if e.col > 0 {
+			e.deleteRange(e.row, prevCluster(e.lines[e.row], e.col), e.row, e.col)
+		} else {
+			e.deleteRange(e.row-1, len(e.lines[e.row-1]), e.row, 0)
+		}
+	}
+	e.endEdit()
+}
+
+func (e *TextEdit) del() {
+	last := len(e.lines) - 1
+	if e.readonly || (!e.selected && e.row == last && e.col == len(e.lines[last])) {
+		return
+	}
+
+	e.beginEdit(editOther)
+	if !e.deleteSelection() {
+		if e.col < len(e.lines[e.row]) {
+			e.deleteRange(e.row, e.col, e.row, nextCluster(e.lines[e.row], e.col))
+		} else {
+			e.deleteRange(e.row, e.col, e.row+1, 0)
+		}
+	}
+	e.endEdit()
+}
+
+// Copy puts the selected text to the clipboard
+func (e *TextEdit) Copy() {
+	if e.selected {
+		clipboardWrite(e.SelectedText())
+	}
+}
+
+// Cut puts the selected text to the clipboard and removes it
+func (e *TextEdit) Cut() {
+	if !e.selected || e.readonly {
+		return
+	}
+
+	e.Copy()
+	e.beginEdit(editOther)
+	e.deleteSelection()
+	e.endEdit()
+}
+
+// Paste replaces the selected text with the clipboard content
+func (e *TextEdit) Paste() {
+	if s, ok := clipboardRead(); ok && s != "" {
+		e.InsertText(s)
+	}
+}
+
+// Undo reverts the last change of the text. Returns false if there is
+// nothing to undo
+func (e *TextEdit) Undo() bool {
+	if len(e.undo) == 0 {
+		return false
+	}
+
+	e.redo = append(e.redo, e.state())
+	st := e.undo[len(e.undo)-1]
+	e.undo = e.undo[:len(e.undo)-1]
+	e.restore(st)
+	return true
+}
+
+// Redo restores the change reverted by Undo. Returns false if there is
+// nothing to redo
+func (e *TextEdit) Redo() bool {
+	if len(e.redo) == 0 {
+		return false
+	}
+
+	e.undo = append(e.undo, e.state())
+	st := e.redo[len(e.redo)-1]
+	e.redo = e.redo[:len(e.redo)-1]
+	e.restore(st)
+	return true
+}
+
+// Draw repaints the control on its View surface
+func (e *TextEdit) Draw() {
+	if e.hidden {
+		return
+	}
+
+	a := AppOf(e)
+	a.PushAttributes()
+	defer a.PopAttributes()
+
+	fg, bg := a.RealColor(e.fg, e.Style(), ColorEditText), a.RealColor(e.bg, e.Style(), ColorEditBack)
+	if !e.Enabled() {
+		fg, bg = a.RealColor(e.fg, e.Style(), ColorDisabledText), a.RealColor(e.bg, e.Style(), ColorDisabledBack)
+	} else if e.Active() {
+		fg, bg = a.RealColor(e.fg, e.Style(), ColorEditActiveText), a.RealColor(e.bg, e.Style(), ColorEditActiveBack)
+	}
+	fgSel, bgSel := a.RealColor(e.fgActive, e.Style(), ColorSelectionText), a.RealColor(e.bgActive, e.Style(), ColorSelectionBack)
+
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(e.x, e.y, e.width, e.height, ' ')
+
+	w, h := e.textWidth(), e.textHeight()
+	vrow := 0
+	for i, line := range e.lines {
+		if vrow >= e.topLine+h {
+			break
+		}
+
+		starts := e.rowStarts(i)
+		widths := runeWidths(line)
+		for r, start := range starts {
+			y := vrow + r - e.topLine
+			if y < 0 || y >= h {
+				continue
+			}
+
+			if !e.wordWrap {
+				start = e.leftShift
+			}
+			// a wide character cut by the left or right edge is not drawn
+			col := 0
+			for idx, ch := range line {
+				cw := widths[idx]
+				if cw == 0 || col < start {
+					col += cw
+					continue
+				}
+				if col+cw > start+w {
+					break
+				}
+				if e.inSelection(i, idx) {
+					a.SetTextColor(fgSel)
+					a.SetBackColor(bgSel)
+				} else {
+					a.SetTextColor(fg)
+					a.SetBackColor(bg)
+				}
+				a.PutChar(e.x+col-start, e.y+y, ch)
+				col += cw
+			}
+		}
+		vrow += len(starts)
+	}
+
+	// scrollbar thumbs follow the cursor
+	crow, ccol := e.toScreen(e.row, e.col)
+	pos := ThumbPosition(crow, e.virtualHeight(), h)
+	a.DrawScrollBar(e.x+e.width-1, e.y, 1, h, pos)
+	if !e.wordWrap {
+		pos = ThumbPosition(ccol, e.virtualWidth(), w)
+		a.DrawScrollBar(e.x, e.y+e.height-1, w, 1, pos)
+	}
+
+	if e.Active() {
+		crow -= e.topLine
+		ccol -= e.leftShift
+		if crow >= 0 && crow < h && ccol >= 0 && ccol < w {
+			a.SetCursorPos(e.x+ccol, e.y+crow)
+		}
+	}
+}
+
+func (e *TextEdit) processMouse(ev Event) bool {
+	if ev.Key == term.MouseRelease {
+		e.dragging = false
+		return true
+	}
+	if ev.Key != term.MouseLeft {
+		return false
+	}
+
+	w, h := e.textWidth(), e.textHeight()
+	dx, dy := ev.X-e.x, ev.Y-e.y
+
+	if ev.Mod == term.ModMotion {
+		if !e.dragging {
+			return true
+		}
+		row, col := e.fromScreen(e.topLine+dy, e.leftShift+dx)
+		e.moveTo(row, col, true)
+		return true
+	}
+
+	// vertical scrollbar
+	if dx == e.width-1 && dy >= 0 && dy < h {
+		maxTop := e.virtualHeight() - h
+		if dy == 0 {
+			e.topLine--
+		} else if dy == h-1 {
+			e.topLine++
+		} else if pos := ItemByThumbPosition(dy, maxTop+1, h); pos >= 0 {
+			e.topLine = pos
+		}
+		e.topLine = clampScroll(e.topLine, maxTop)
+		return true
+	}
+
+	// horizontal scrollbar
+	if !e.wordWrap && dy == e.height-1 {
+		maxShift := e.virtualWidth() - w
+		if dx == 0 {
+			e.leftShift--
+		} else if dx == w-1 {
+			e.leftShift++
+		} else if pos := ItemByThumbPosition(dx, maxShift+1, w); pos >= 0 {
+			e.leftShift = pos
+		}
+		e.leftShift = clampScroll(e.leftShift, maxShift)
+		return true
+	}
+
+	if dx < 0 || dx >= w || dy < 0 || dy >= h {
+		return false
+	}
+
+	e.marking = false
+	row, col := e.fromScreen(e.topLine+dy, e.leftShift+dx)
+	e.moveTo(row, col, false)
+	e.dragging = true
+	return true
+}
+
+// wheelScroll scrolls the text without moving the cursor
+func (e *TextEdit) wheelScroll(dx, dy int) bool {
+	if dx != 0 && e.wordWrap {
+		return false
+	}
+
+	if dy != 0 {
+		e.topLine = clampScroll(e.topLine+dy, e.virtualHeight()-e.textHeight())
+	} else {
+		e.leftShift = clampScroll(e.leftShift+dx, e.virtualWidth()-e.textWidth())
+	}
+	return true
+}
+
+func (e *TextEdit) processKey(ev Event) bool {
+	if ev.Mod&term.ModAlt != 0 {
+		switch ev.Key {
+		case term.KeyArrowLeft:
+			e.wordLeft()
+		case term.KeyArrowRight:
+			e.wordRight()
+		case term.KeyHome:
+			e.moveTo(0, 0, e.marking)
+		case term.KeyEnd:
+			last := len(e.lines) - 1
+			e.moveTo(last, len(e.lines[last]), e.marking)
+		default:
+			return false
+		}
+		return true
+	}
+
+	if ev.Ch != 0 {
+		e.insertRune(ev.Ch)
+		return true
+	}
+
+	switch ev.Key {
+	case term.KeyArrowLeft:
+		e.charLeft()
+	case term.KeyArrowRight:
+		e.charRight()
+	case term.KeyArrowUp:
+		e.moveVert(-1)
+	case term.KeyArrowDown:
+		e.moveVert(1)
+	case term.KeyHome:
+		e.moveTo(e.row, 0, e.marking)
+	case term.KeyEnd:
+		e.moveTo(e.row, len(e.lines[e.row]), e.marking)
+	case term.KeyPgup:
+		e.movePage(-1)
+	case term.KeyPgdn:
+		e.movePage(1)
+	case term.KeySpace:
+		e.insertRune(' ')
+	case term.KeyEnter:
+		e.InsertText("\n")
+	case term.KeyTab:
+		if !e.wantTabs {
+			return false
+		}
+		e.insertTab()
+	case term.KeyBackspace, term.KeyBackspace2:
+		e.backspace()
+	case term.KeyDelete:
+		e.del()
+	case term.KeyCtrlA:
+		e.SelectAll()
+	case term.KeyCtrlC:
+		e.Copy()
+	case term.KeyCtrlX:
+		e.Cut()
+	case term.KeyCtrlV:
+		e.Paste()
+	case term.KeyCtrlZ:
+		e.Undo()
+	case term.KeyCtrlY:
+		e.Redo()
+	case term.KeyCtrlSpace:
+		e.marking = !e.marking
+		if e.marking {
+			e.selected = false
+		}
+	case term.KeyEsc:
+		if !e.selected && !e.marking {
+			return false
+		}
+		e.selected, e.marking = false, false
+	default:
+		return false
+	}
+
+	return true
+}
+
+/*
+ProcessEvent processes all events come from the control parent. If a control
+processes an event it should return true. If the method returns false it means
+that the control do not want or cannot process the event and the caller sends
+the event to the control parent
+*/
+func (e *TextEdit) ProcessEvent(event Event) bool {
+	if dx, dy := WheelDelta(event); dx != 0 || dy != 0 {
+		return e.Enabled() && e.wheelScroll(dx, dy)
+	}
+
+	if !e.Active() || !e.Enabled() {
+		return false
+	}
+
+	switch event.Type {
+	case EventKey:
+		return e.processKey(event)
+	case EventMouse:
+		return e.processMouse(event)
+	}
+
+	return false
+}
@@ -0,0 +1,111 @@
+package clui
+
+import (
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func typeText(s string) {
+	for _, ch := range s {
+		if ch == ' ' {
+			SimulateKey(term.KeySpace, 0)
+		} else {
+			SimulateKey(0, ch)
+		}
+	}
+}
+
+func TestTextEdit(t *testing.T) {
+	b := initHeadless(t, 30, 10)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 20, 7, "Edit")
+	edit := CreateTextEdit(wnd, 10, 5, 1)
+	ActivateControl(wnd, edit)
+
+	changes := 0
+	edit.OnChange(func(ev Event) {
+		changes++
+	})
+
+	typeText("hello world")
+	SimulateKey(term.KeyEnter, 0)
+	SimulateKey(term.KeyTab, 0)
+	typeText("second")
+	if edit.Text() != "hello world\n    second" {
+		t.Errorf("Invalid text: %q", edit.Text())
+	}
+	if changes != 19 {
+		t.Errorf("OnChange must be called for every change: %v", changes)
+	}
+
+	// word movement and deletion
+	SimulateEvent(Event{Type: EventKey, Key: term.KeyArrowLeft, Mod: term.ModAlt})
+	SimulateKey(term.KeyBackspace2, 0)
+	if edit.Text() != "hello world\n   second" {
+		t.Errorf("Invalid text after backspace: %q", edit.Text())
+	}
+	SimulateKey(term.KeyArrowUp, 0)
+	SimulateKey(term.KeyEnd, 0)
+	SimulateKey(term.KeyDelete, 0)
+	if edit.Text() != "hello world   second" || edit.LineCount() != 1 {
+		t.Errorf("Delete at the end of line must join lines: %q", edit.Text())
+	}
+
+	// undo restores the whole typed word at once
+	edit.Undo()
+	edit.Undo()
+	if edit.Text() != "hello world\n    second" {
+		t.Errorf("Invalid text after undo: %q", edit.Text())
+	}
+	edit.Undo()
+	if edit.Text() != "hello world\n    " {
+		t.Errorf("Typing must be undone as a single step: %q", edit.Text())
+	}
+	edit.Redo()
+	if edit.Text() != "hello world\n    second" {
+		t.Errorf("Invalid text after redo: %q", edit.Text())
+	}
+
+	// keyboard selection
+	edit.SetCursorPos(0, 0)
+	SimulateKey(term.KeyCtrlSpace, 0)
+	SimulateEvent(Event{Type: EventKey, Key: term.KeyArrowRight, Mod: term.ModAlt})
+	if edit.SelectedText() != "hello " {
+		t.Errorf("Invalid selection: %q", edit.SelectedText())
+	}
+	typeText("bye ")
+	if edit.Text() != "bye world\n    second" || edit.SelectedText() != "" {
+		t.Errorf("Typing must replace selection: %q", edit.Text())
+	}
+
+	// mouse selection
+	x, y := edit.Pos()
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseLeft, X: x + 4, Y: y})
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseLeft, Mod: term.ModMotion, X: x + 6, Y: y + 1})
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseRelease, X: x + 6, Y: y + 1})
+	if edit.SelectedText() != "world\n    se" {
+		t.Errorf("Invalid mouse selection: %q", edit.SelectedText())
+	}
+	edit.Cut()
+	if edit.Text() != "bye cond" {
+		t.Errorf("Invalid text after cut: %q", edit.Text())
+	}
+
+	edit.SetReadOnly(true)
+	typeText("x")
+	if edit.Text() != "bye cond" {
+		t.Error("Read-only text must not change")
+	}
+	edit.SetReadOnly(false)
+
+	edit.SetText("a long line that wraps\n\tindented")
+	edit.SetWordWrap(true)
+	RefreshScreen()
+	checkSnapshot(t, b, "textedit")
+	SimulateKey(term.KeyArrowDown, 0)
+	if row, col := edit.CursorPos(); row != 0 || col != 17 {
+		t.Errorf("Down must move the cursor by a screen row in wrap mode: %v %v", row, col)
+	}
+}
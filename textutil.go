@@ -1,10 +1,12 @@
 package clui
 
 import (
-	xs "github.com/huandu/xstrings"
+	"fmt"
 	term "github.com/nsf/termbox-go"
 	"regexp"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 var (
@@ -24,41 +26,103 @@ var (
 		"underlined": term.AttrUnderline,
 		"reverse":    term.AttrReverse,
 	}
+
+	// color(N) and rgb(r, g, b) may contain spaces that must be removed
+	// before splitting a color description into parts
+	rxColorFunc = regexp.MustCompile(`(?i)(color|rgb)\([^)]*\)`)
 )
 
+// wrapColumns returns the columns where the rows of the text start when
+// the text is wrapped to lines of the given width. A wide character that
+// does not fit the end of a row moves to the next row. An empty text
+// takes one row
+func wrapColumns(str string, width int) []int {
+	starts := []int{0}
+	if width <= 0 {
+		return starts
+	}
+
+	var st graphemeState
+	curr, rowStart := 0, 0
+	for _, r := range str {
+		w := st.width(r)
+		if w == 0 {
+			continue
+		}
+		if curr+w-rowStart > width {
+			rowStart = curr
+			starts = append(starts, rowStart)
+		}
+		curr += w
+	}
+	return starts
+}
+
+// isWordRune returns true if the character is a part of a word. Combining
+// marks belong to the word of their base character
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r)
+}
+
+// prevWordStart returns the position of the beginning of the word before
+// pos. Non-word characters between the word and pos are skipped
+func prevWordStart(text []rune, pos int) int {
+	for pos > 0 && !isWordRune(text[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(text[pos-1]) {
+		pos--
+	}
+	return pos
+}
+
+// nextWordStart returns the position of the beginning of the word after
+// pos, or the text length if there is no word after pos
+func nextWordStart(text []rune, pos int) int {
+	for pos < len(text) && isWordRune(text[pos]) {
+		pos++
+	}
+	for pos < len(text) && !isWordRune(text[pos]) {
+		pos++
+	}
+	return pos
+}
+
 // Ellipsize truncates text to maxWidth by replacing a
 // substring in the middle with ellipsis and keeping
 // the beginning and ending of the string untouched.
 // If maxWidth is less than 5 then no ellipsis is
 // added, the text is just truncated from the right.
+// Widths are measured in screen cells(see TextWidth)
 func Ellipsize(str string, maxWidth int) string {
-	ln := xs.Len(str)
+	ln := TextWidth(str)
 	if ln <= maxWidth {
 		return str
 	}
 
 	if maxWidth < 5 {
-		return xs.Slice(str, 0, maxWidth)
+		return sliceByWidth(str, 0, maxWidth)
 	}
 
 	left := int((maxWidth - 3) / 2)
 	right := maxWidth - left - 3
-	return xs.Slice(str, 0, left) + "..." + xs.Slice(str, ln-right, -1)
+	return sliceByWidth(str, 0, left) + "..." + sliceByWidth(str, ln-right, -1)
 }
 
-// CutText makes a text no longer than maxWidth
+// CutText makes a text no longer than maxWidth screen cells.
+// A wide character that does not fit is removed completely
 func CutText(str string, maxWidth int) string {
-	ln := xs.Len(str)
+	ln := TextWidth(str)
 	if ln <= maxWidth {
 		return str
 	}
 
-	return xs.Slice(str, 0, maxWidth)
+	return sliceByWidth(str, 0, maxWidth)
 }
 
 // AlignText calculates the initial position of the text
-// output depending on str length and available width.
-// The str is truncated in case of its lenght greater than
+// output depending on str width and available width.
+// The str is truncated in case of its width greater than
 // width. Function returns shift that should be added to
 // original label position before output instead of padding
 // the string with spaces. The reason is to make possible
@@ -66,7 +130,7 @@ func CutText(str string, maxWidth int) string {
 // and ending. If you do not need transparency you can
 // add spaces manually using the returned shift value
 func AlignText(str string, width int, align Align) (shift int, out string) {
-	length := xs.Len(str)
+	length := TextWidth(str)
 
 	if length >= width {
 		return 0, CutText(str, width)
@@ -88,7 +152,7 @@ func AlignText(str string, width int, align Align) (shift int, out string) {
 // it everywhere
 func AlignColorizedText(str string, width int, align Align) (int, string) {
 	rawText := UnColorizeText(str)
-	length := xs.Len(rawText)
+	length := TextWidth(rawText)
 
 	if length <= width {
 		shift, _ := AlignText(rawText, width, align)
@@ -102,48 +166,13 @@ func AlignColorizedText(str string, width int, align Align) (int, string) {
 		skip = (length - width) / 2
 	}
 
-	fgChanged, bgChanged := false, false
-	curr := 0
-	parser := NewColorParser(str, term.ColorBlack, term.ColorBlack)
-	out := ""
-	for curr < skip+width {
-		elem := parser.NextElement()
-
-		if elem.Type == ElemEndOfText {
-			break
-		}
-
-		if elem.Type == ElemPrintable {
-			curr++
-			if curr == skip+1 {
-				if fgChanged {
-					out += "<t:" + ColorToString(elem.Fg) + ">"
-				}
-				if bgChanged {
-					out += "<b:" + ColorToString(elem.Bg) + ">"
-				}
-				out += string(elem.Ch)
-			} else if curr > skip+1 {
-				out += string(elem.Ch)
-			}
-		} else if elem.Type == ElemTextColor {
-			fgChanged = true
-			if curr > skip+1 {
-				out += "<t:" + ColorToString(elem.Fg) + ">"
-			}
-		} else if elem.Type == ElemBackColor {
-			bgChanged = true
-			if curr > skip+1 {
-				out += "<b:" + ColorToString(elem.Bg) + ">"
-			}
-		}
-	}
-
-	return 0, out
+	return 0, SliceColorized(str, skip, skip+width)
 }
 
 // SliceColorized returns a slice of text with correct color
-// tags. start and end are real printable rune indices
+// tags. start and end are screen columns of the printable
+// text(see TextWidth). A wide character that is cut by start
+// or end is removed completely
 func SliceColorized(str string, start, end int) string {
 	if str == "" {
 		return str
@@ -155,40 +184,60 @@ func SliceColorized(str string, start, end int) string {
 	fgChanged, bgChanged := false, false
 	curr := 0
 	parser := NewColorParser(str, term.ColorBlack, term.ColorBlack)
-	var out string
+	var st graphemeState
+	// started is true after the first printable character is added.
+	// included is true if the current grapheme cluster is in the slice
+	started, included := false, false
+	var out, tags string
 	for {
-		if end != -1 && curr >= end {
-			break
-		}
 		elem := parser.NextElement()
 		if elem.Type == ElemEndOfText {
+			// tags after the last character are kept only if the
+			// text is not cut
+			out += tags
 			break
 		}
 
 		switch elem.Type {
 		case ElemTextColor:
 			fgChanged = true
-			if out != "" {
-				out += "<t:" + ColorToString(elem.Fg) + ">"
+			if started {
+				tags += "<t:" + ColorToString(elem.Fg) + ">"
 			}
 		case ElemBackColor:
 			bgChanged = true
-			if out != "" {
-				out += "<b:" + ColorToString(elem.Bg) + ">"
+			if started {
+				tags += "<b:" + ColorToString(elem.Bg) + ">"
 			}
 		case ElemPrintable:
-			if curr == start {
+			w := st.width(elem.Ch)
+			if w == 0 {
+				if included {
+					out += tags + string(elem.Ch)
+					tags = ""
+				}
+				continue
+			}
+			if end != -1 && curr+w > end {
+				return out
+			}
+
+			included = curr >= start
+			curr += w
+			if !included {
+				continue
+			}
+			if !started {
 				if fgChanged {
 					out += "<t:" + ColorToString(elem.Fg) + ">"
 				}
 				if bgChanged {
 					out += "<b:" + ColorToString(elem.Bg) + ">"
 				}
+				started = true
 			}
-			if curr >= start {
-				out += string(elem.Ch)
-			}
-			curr++
+			out += tags + string(elem.Ch)
+			tags = ""
 		}
 	}
 
@@ -209,11 +258,19 @@ func UnColorizeText(str string) string {
 // black, white, red, green, blue, magenta, yellow, cyan
 // and a few modifiers:
 // bold or bright, underline or underlined, reverse
+// Besides base colors, a color can be set as an index of 256-color
+// palette - color(202), or as a 24-bit RGB value - #ff8800, #f80,
+// or rgb(255,136,0). If the terminal does not support that many colors
+// the closest available color is displayed
 // Note: some terminals do not support all modifiers, e.g,
 // Windows one understands only bold/bright - it makes the
 // color brighter with the modidierA
-// Examples: "red bold", "green+underline+bold"
+// Examples: "red bold", "green+underline+bold", "#ff8800 bold"
 func StringToColor(str string) term.Attribute {
+	str = rxColorFunc.ReplaceAllStringFunc(str, func(s string) string {
+		return strings.Replace(s, " ", "", -1)
+	})
+
 	var parts []string
 	if strings.ContainsRune(str, '+') {
 		parts = strings.Split(str, "+")
@@ -231,6 +288,9 @@ func StringToColor(str string) term.Attribute {
 		item = strings.ToLower(item)
 
 		c, ok := colorMap[item]
+		if !ok {
+			c, ok = parseExtendedColor(item)
+		}
 		if ok {
 			clr |= c
 		}
@@ -239,6 +299,51 @@ func StringToColor(str string) term.Attribute {
 	return clr
 }
 
+// parseExtendedColor converts 256-color palette and RGB color descriptions
+// to attributes: color(N), #rrggbb, #rgb, and rgb(r,g,b)
+func parseExtendedColor(item string) (term.Attribute, bool) {
+	if strings.HasPrefix(item, "#") {
+		hex := item[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			return 0, false
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return 0, false
+		}
+		return ColorRGB(uint8(v>>16), uint8(v>>8), uint8(v)), true
+	}
+
+	if strings.HasPrefix(item, "color(") && strings.HasSuffix(item, ")") {
+		v, err := strconv.ParseUint(item[6:len(item)-1], 10, 8)
+		if err != nil {
+			return 0, false
+		}
+		return Color256(uint8(v)), true
+	}
+
+	if strings.HasPrefix(item, "rgb(") && strings.HasSuffix(item, ")") {
+		vals := strings.Split(item[4:len(item)-1], ",")
+		if len(vals) != 3 {
+			return 0, false
+		}
+		var rgb [3]uint8
+		for i, s := range vals {
+			v, err := strconv.ParseUint(s, 10, 8)
+			if err != nil {
+				return 0, false
+			}
+			rgb[i] = uint8(v)
+		}
+		return ColorRGB(rgb[0], rgb[1], rgb[2]), true
+	}
+
+	return 0, false
+}
+
 // GetColorMap returns the color map (id is the color name, value its code)
 func GetColorMap() map[string]term.Attribute {
 	return colorMap
@@ -249,12 +354,19 @@ func SetColorMap(cmap map[string]term.Attribute) {
 	colorMap = cmap
 }
 
-// ColorToString returns string representation of the attribute
+// ColorToString returns string representation of the attribute.
+// Colors from 256-color palette are returned as color(N), and RGB
+// colors as #rrggbb
 func ColorToString(attr term.Attribute) string {
 	var out string
 
-	rawClr := attr & 15
-	if rawClr < 8 {
+	rawClr := attr &^ attrMask
+	if isRGBColor(rawClr) {
+		r, g, b := term.AttributeToRGB(rawClr)
+		out += fmt.Sprintf("#%02x%02x%02x ", r, g, b)
+	} else if isPaletteColor(rawClr) {
+		out += fmt.Sprintf("color(%d) ", paletteIndex(rawClr))
+	} else if rawClr <= term.ColorWhite {
 		for k, v := range colorMap {
 			if v == rawClr {
 				out += k + " "
@@ -2,6 +2,8 @@ package clui
 
 import (
 	"testing"
+
+	term "github.com/nsf/termbox-go"
 )
 
 func TestEllipsize(t *testing.T) {
@@ -141,3 +143,45 @@ func TestUnColorizeText(t *testing.T) {
 		}
 	}
 }
+
+func TestStringToColor(t *testing.T) {
+	cases := []struct {
+		in   string
+		want term.Attribute
+	}{
+		{"red", ColorRed},
+		{"green bold", ColorGreenBold},
+		{"blue+underline", ColorBlue | term.AttrUnderline},
+		{"#ff8800", ColorRGB(255, 136, 0)},
+		{"#F80 bold", ColorRGB(255, 136, 0) | term.AttrBold},
+		{"rgb(1, 2, 3)", ColorRGB(1, 2, 3)},
+		{"color(202)", Color256(202)},
+		{"color( 17 )+bold", Color256(17) | term.AttrBold},
+		{"color(256)", 0},
+		{"#12345", 0},
+	}
+
+	for _, c := range cases {
+		got := StringToColor(c.in)
+		if got != c.want {
+			t.Errorf("StringToColor (%v) == <%v>, want <%v>", c.in, got, c.want)
+		}
+	}
+}
+
+func TestColorToString(t *testing.T) {
+	cases := []term.Attribute{
+		ColorRed,
+		ColorWhiteBold,
+		ColorRGB(255, 136, 0),
+		Color256(202) | term.AttrUnderline,
+	}
+
+	for _, c := range cases {
+		str := ColorToString(c)
+		got := StringToColor(str)
+		if got != c {
+			t.Errorf("ColorToString (%v) == <%v> that converts back to <%v>", c, str, got)
+		}
+	}
+}
@@ -2,7 +2,6 @@ package clui
 
 import (
 	"bufio"
-	xs "github.com/huandu/xstrings"
 	term "github.com/nsf/termbox-go"
 	"os"
 	"strings"
@@ -24,6 +23,8 @@ type TextView struct {
 	// own listbox members
 	lines   []string
 	lengths []int
+	// the number of rows every line takes
+	rows []int
 	// for up/down scroll
 	topLine int
 	// for side scroll
@@ -83,28 +84,30 @@ func (l *TextView) outputHeight() int {
 func (l *TextView) drawScrolls() {
 	height := l.outputHeight()
 	pos := ThumbPosition(l.topLine, l.virtualHeight-l.outputHeight(), height)
-	DrawScrollBar(l.x+l.width-1, l.y, 1, height, pos)
+	a := AppOf(l)
+	a.DrawScrollBar(l.x+l.width-1, l.y, 1, height, pos)
 
 	if !l.wordWrap {
 		pos = ThumbPosition(l.leftShift, l.virtualWidth-l.width+1, l.width-1)
-		DrawScrollBar(l.x, l.y+l.height-1, l.width-1, 1, pos)
+		a.DrawScrollBar(l.x, l.y+l.height-1, l.width-1, 1, pos)
 	}
 }
 
 func (l *TextView) drawText() {
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	maxWidth := l.width - 1
 	maxHeight := l.outputHeight()
 
-	bg, fg := RealColor(l.bg, l.Style(), ColorEditBack), RealColor(l.fg, l.Style(), ColorEditText)
+	bg, fg := a.RealColor(l.bg, l.Style(), ColorEditBack), a.RealColor(l.fg, l.Style(), ColorEditText)
 	if l.Active() {
-		bg, fg = RealColor(l.bg, l.Style(), ColorEditActiveBack), RealColor(l.fg, l.Style(), ColorEditActiveText)
+		bg, fg = a.RealColor(l.bg, l.Style(), ColorEditActiveBack), a.RealColor(l.fg, l.Style(), ColorEditActiveText)
 	}
 
-	SetTextColor(fg)
-	SetBackColor(bg)
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
 	if l.wordWrap {
 		lineID := l.posToItemNo(l.topLine)
 		linePos := l.itemNoToPos(lineID)
@@ -115,24 +118,17 @@ func (l *TextView) drawText() {
 				break
 			}
 
-			remained := l.lengths[lineID]
-			start := 0
-			for remained > 0 {
-				var s string
-				s = SliceColorized(l.lines[lineID], start, start+maxWidth)
-
+			starts := wrapColumns(UnColorizeText(l.lines[lineID]), maxWidth)
+			for row := 0; row < len(starts) && y < maxHeight; row++ {
 				if linePos >= l.topLine {
-					DrawText(l.x, l.y+y, s)
+					end := -1
+					if row < len(starts)-1 {
+						end = starts[row+1]
+					}
+					a.DrawText(l.x, l.y+y, SliceColorized(l.lines[lineID], starts[row], end))
+					y++
 				}
-
-				remained -= maxWidth
-				y++
 				linePos++
-				start += maxWidth
-
-				if y >= maxHeight {
-					break
-				}
 			}
 
 			lineID++
@@ -161,7 +157,7 @@ func (l *TextView) drawText() {
 					str = SliceColorized(str, l.leftShift, maxWidth+l.leftShift)
 				}
 			}
-			DrawText(l.x, l.y+y, str)
+			a.DrawText(l.x, l.y+y, str)
 
 			y++
 		}
@@ -174,20 +170,21 @@ func (l *TextView) Draw() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(l)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	x, y := l.Pos()
 	w, h := l.Size()
 
-	bg, fg := RealColor(l.bg, l.Style(), ColorEditBack), RealColor(l.fg, l.Style(), ColorEditText)
+	bg, fg := a.RealColor(l.bg, l.Style(), ColorEditBack), a.RealColor(l.fg, l.Style(), ColorEditText)
 	if l.Active() {
-		bg, fg = RealColor(l.bg, l.Style(), ColorEditActiveBack), RealColor(l.fg, l.Style(), ColorEditActiveText)
+		bg, fg = a.RealColor(l.bg, l.Style(), ColorEditActiveBack), a.RealColor(l.fg, l.Style(), ColorEditActiveText)
 	}
 
-	SetTextColor(fg)
-	SetBackColor(bg)
-	FillRect(x, y, w, h, ' ')
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(x, y, w, h, ' ')
 	l.drawText()
 	l.drawScrolls()
 }
@@ -316,6 +313,23 @@ that the control do not want or cannot process the event and the caller sends
 the event to the control parent
 */
 func (l *TextView) ProcessEvent(event Event) bool {
+	if dx, dy := WheelDelta(event); dx != 0 || dy != 0 {
+		if !l.Enabled() || (dx != 0 && l.wordWrap) {
+			return false
+		}
+		switch {
+		case dy < 0:
+			l.moveUp(1)
+		case dy > 0:
+			l.moveDown(1)
+		case dx < 0:
+			l.moveLeft()
+		default:
+			l.moveRight()
+		}
+		return true
+	}
+
 	if !l.Active() || !l.Enabled() {
 		return false
 	}
@@ -363,17 +377,15 @@ func (l *TextView) calculateVirtualSize() {
 	l.virtualHeight = 0
 
 	l.lengths = make([]int, len(l.lines))
+	l.rows = make([]int, len(l.lines))
 	for idx, str := range l.lines {
 		str = UnColorizeText(str)
 
-		sz := xs.Len(str)
+		sz := TextWidth(str)
+		l.rows[idx] = 1
 		if l.wordWrap {
-			n := sz / w
-			r := sz % w
-			l.virtualHeight += n
-			if r != 0 {
-				l.virtualHeight++
-			}
+			l.rows[idx] = len(wrapColumns(str, w))
+			l.virtualHeight += l.rows[idx]
 		} else {
 			l.virtualHeight++
 			if sz > l.virtualWidth {
@@ -384,8 +396,11 @@ func (l *TextView) calculateVirtualSize() {
 	}
 }
 
-// SetText replaces existing content of the control
+// SetText replaces existing content of the control.
+// The method is not thread safe: to call it from other goroutine
+// wrap the call with Invoke
 func (l *TextView) SetText(text []string) {
+	l.Invalidate()
 	l.lines = make([]string, len(text))
 	copy(l.lines, text)
 
@@ -399,15 +414,8 @@ func (l *TextView) SetText(text []string) {
 
 func (l *TextView) posToItemNo(pos int) int {
 	id := 0
-	for idx, item := range l.lengths {
-		if l.virtualWidth >= item {
-			pos--
-		} else {
-			pos -= item / l.virtualWidth
-			if item%l.virtualWidth != 0 {
-				pos--
-			}
-		}
+	for idx, rows := range l.rows {
+		pos -= rows
 
 		if pos <= 0 {
 			id = idx
@@ -421,14 +429,7 @@ func (l *TextView) posToItemNo(pos int) int {
 func (l *TextView) itemNoToPos(id int) int {
 	pos := 0
 	for i := 0; i < id; i++ {
-		if l.virtualWidth >= l.lengths[i] {
-			pos++
-		} else {
-			pos += l.lengths[i] / l.virtualWidth
-			if l.lengths[i]%l.virtualWidth != 0 {
-				pos++
-			}
-		}
+		pos += l.rows[i]
 	}
 
 	return pos
@@ -455,6 +456,7 @@ func (l *TextView) recalculateTopLine() {
 
 // SetWordWrap enables or disables wordwrap mode
 func (l *TextView) SetWordWrap(wrap bool) {
+	l.Invalidate()
 	if wrap != l.wordWrap {
 		l.wordWrap = wrap
 		l.calculateVirtualSize()
@@ -507,8 +509,11 @@ func (l *TextView) SetAutoScroll(auto bool) {
 
 // AddText appends a text to the end of the control content.
 // View position may be changed automatically depending on
-// value of AutoScroll
+// value of AutoScroll.
+// The method is not thread safe: to call it from other goroutine
+// wrap the call with Invoke
 func (l *TextView) AddText(text []string) {
+	l.Invalidate()
 	l.lines = append(l.lines, text...)
 	l.applyLimit()
 	l.calculateVirtualSize()
@@ -0,0 +1,209 @@
+package clui
+
+import (
+	"unicode"
+
+	rw "github.com/mattn/go-runewidth"
+)
+
+// widthCondition measures runes the same way termbox does: ambiguous
+// width runes take one cell regardless of the locale
+var widthCondition = &rw.Condition{EastAsianWidth: false}
+
+// zero width joiner glues characters to one grapheme cluster
+const runeZWJ = '\u200d'
+
+// RuneWidth returns the number of screen cells a character takes: 2 for
+// wide East Asian characters and emoji, 0 for characters that join the
+// previous one(combining marks, zero width joiner, variation selectors,
+// emoji modifiers), and 1 for the rest including control characters
+func RuneWidth(r rune) int {
+	if isClusterExtender(r) {
+		return 0
+	}
+	if widthCondition.RuneWidth(r) == 2 {
+		return 2
+	}
+	return 1
+}
+
+/*
+TextWidth returns the number of screen cells the text takes. The text must
+not contain color tags - use UnColorizeText to remove them.
+
+The text is a sequence of grapheme clusters: a character followed by
+combining marks, variation selectors, emoji modifiers, or characters
+joined with zero width joiner(e.g, family emoji), a pair of regional
+indicators(a flag), or CR LF. A cluster takes as many cells as its first
+character(see RuneWidth). A screen cell keeps only one character, so
+a cluster is displayed as its first character: the other characters are
+kept in the text but they are not drawn.
+*/
+func TextWidth(str string) int {
+	width := 0
+	var st graphemeState
+	for _, r := range str {
+		width += st.width(r)
+	}
+	return width
+}
+
+// isClusterExtender returns true if the rune never starts a grapheme
+// cluster
+func isClusterExtender(r rune) bool {
+	switch {
+	case r == runeZWJ:
+		return true
+	case r >= 0xfe00 && r <= 0xfe0f, r >= 0xe0100 && r <= 0xe01ef:
+		// variation selectors
+		return true
+	case r >= 0x1f3fb && r <= 0x1f3ff:
+		// emoji skin tone modifiers
+		return true
+	case r >= 0xe0020 && r <= 0xe007f:
+		// tags used by flags of regions
+		return true
+	case r >= 0x1160 && r <= 0x11ff, r >= 0xd7b0 && r <= 0xd7ff:
+		// Hangul vowel and final consonant jamo
+		return true
+	}
+	return unicode.Is(unicode.M, r)
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
+
+// isPictographic returns true if the rune is a symbol that can be joined
+// to an emoji with zero width joiner
+func isPictographic(r rune) bool {
+	return r >= 0x1f000 || (r >= 0x2190 && r <= 0x2bff) || r == 0x00a9 || r == 0x00ae
+}
+
+// graphemeState splits a stream of runes into grapheme clusters. The zero
+// value is ready to use
+type graphemeState struct {
+	prev    rune
+	started bool
+	// the previous rune is the first one of a regional indicator pair
+	riOpen bool
+}
+
+// width returns the number of cells r adds to the text: the width of the
+// cluster if r starts a new one, and 0 if r joins the current cluster
+func (st *graphemeState) width(r rune) int {
+	prev, started := st.prev, st.started
+	st.prev, st.started = r, true
+
+	if started {
+		switch {
+		case prev == '\r' && r == '\n':
+			return 0
+		case prev == runeZWJ && isPictographic(r):
+			return 0
+		case isClusterExtender(r):
+			return 0
+		case isRegionalIndicator(r) && st.riOpen:
+			st.riOpen = false
+			return 0
+		}
+	}
+
+	st.riOpen = isRegionalIndicator(r)
+	if w := RuneWidth(r); w > 0 {
+		return w
+	}
+	// a combining mark without a base character is displayed alone
+	return 1
+}
+
+// runeWidths returns the number of cells every rune of the text adds to
+// the text width. Only the first rune of a grapheme cluster has non-zero
+// width
+func runeWidths(text []rune) []int {
+	widths := make([]int, len(text))
+	var st graphemeState
+	for i, r := range text {
+		widths[i] = st.width(r)
+	}
+	return widths
+}
+
+// columnOf returns the screen column of the rune at pos
+func columnOf(widths []int, pos int) int {
+	col := 0
+	for i := 0; i < pos && i < len(widths); i++ {
+		col += widths[i]
+	}
+	return col
+}
+
+// clusterAtColumn returns the index of the first grapheme cluster that
+// starts at or after the column col
+func clusterAtColumn(widths []int, col int) int {
+	curr := 0
+	for i, w := range widths {
+		if w == 0 {
+			continue
+		}
+		if curr >= col {
+			return i
+		}
+		curr += w
+	}
+	return len(widths)
+}
+
+// nextCluster returns the index of the rune after the grapheme cluster
+// that contains the rune at pos
+func nextCluster(text []rune, pos int) int {
+	widths := runeWidths(text)
+	for pos++; pos < len(text) && widths[pos] == 0; pos++ {
+	}
+	if pos > len(text) {
+		return len(text)
+	}
+	return pos
+}
+
+// prevCluster returns the index of the first rune of the grapheme cluster
+// before pos
+func prevCluster(text []rune, pos int) int {
+	widths := runeWidths(text)
+	if pos > len(text) {
+		pos = len(text)
+	}
+	for pos--; pos > 0 && widths[pos] == 0; pos-- {
+	}
+	if pos < 0 {
+		pos = 0
+	}
+	return pos
+}
+
+// sliceByWidth returns the grapheme clusters of the text that are
+// displayed between columns start and end. A wide cluster that is cut by
+// start or end is excluded. end equal -1 means the end of the text
+func sliceByWidth(str string, start, end int) string {
+	var st graphemeState
+	from, to := len(str), len(str)
+	curr := 0
+	for i, r := range str {
+		w := st.width(r)
+		if w == 0 {
+			continue
+		}
+		if from == len(str) && curr >= start {
+			from = i
+		}
+		if end != -1 && curr+w > end {
+			to = i
+			break
+		}
+		curr += w
+	}
+	if from > to {
+		return ""
+	}
+	return str[from:to]
+}
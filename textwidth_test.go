@@ -0,0 +1,180 @@
+package clui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestTextWidth(t *testing.T) {
+	cases := []struct {
+		in    string
+		width int
+	}{
+		{"abc", 3},
+		{"日本語", 6},
+		{"한국어", 6},
+		{"ひらがな", 8},
+		{"ＡＢ", 4},
+		{"e\u0301te\u0301", 3},
+		{"\u1100\u1161\u11a8", 2},
+		{"😀!", 3},
+		{"👍\U0001f3fd", 2},
+		{"\U0001f468\u200d\U0001f469\u200d\U0001f467", 2},
+		{"🇯🇵🇫🇷", 2},
+		{"\u0301", 1},
+		{"├─┤", 3},
+	}
+
+	for _, c := range cases {
+		if w := TextWidth(c.in); w != c.width {
+			t.Errorf("TextWidth(%q) == %v, want %v", c.in, w, c.width)
+		}
+	}
+}
+
+func TestWideTextSlicing(t *testing.T) {
+	if s := CutText("日本語", 3); s != "日" {
+		t.Errorf("A cut wide character must be removed: %q", s)
+	}
+	if s := CutText("ae\u0301b", 2); s != "ae\u0301" {
+		t.Errorf("Combining marks must be kept with the base: %q", s)
+	}
+	if s := Ellipsize("日本語のテキスト", 9); s != "日...ト" {
+		t.Errorf("Invalid ellipsized text: %q", s)
+	}
+	if sh, s := AlignText("日本", 7, AlignRight); sh != 3 || s != "日本" {
+		t.Errorf("Invalid aligned text: %q with shift %v", s, sh)
+	}
+	if s := SliceColorized("a<t:red>日本<t:blue>語", 1, 7); s != "<t:red>日本<t:blue>語" {
+		t.Errorf("Invalid slice: %q", s)
+	}
+	if s := SliceColorized("日本語", 1, 5); s != "本" {
+		t.Errorf("Wide characters cut by slice bounds must be removed: %q", s)
+	}
+	if starts := wrapColumns("ab日本語", 3); !reflect.DeepEqual(starts, []int{0, 2, 4, 6}) {
+		t.Errorf("Invalid wrapped rows: %v", starts)
+	}
+}
+
+func TestDrawWideText(t *testing.T) {
+	b := initHeadless(t, 12, 3)
+	defer DeinitLibrary()
+
+	DrawText(0, 0, "日本<t:red>語e\u0301")
+	if c := b.Cell(4, 0); c.Ch != '語' || c.Fg != ColorRed {
+		t.Errorf("Wide characters must take two cells: %v", c)
+	}
+	if c := b.Cell(6, 0); c.Ch != 'e' {
+		t.Errorf("A cluster must be drawn as its first character: %v", c)
+	}
+
+	PushClip()
+	SetClipRect(1, 1, 4, 1)
+	DrawRawText(0, 1, "日本語")
+	PopClip()
+	if c := b.Cell(1, 1); c.Ch != ' ' {
+		t.Errorf("A wide character cut by clipping rectangle must be a space: %v", c)
+	}
+	if c := b.Cell(4, 1); c.Ch != ' ' {
+		t.Errorf("A wide character cut by clipping rectangle must be a space: %v", c)
+	}
+
+	Flush()
+	if line := strings.Split(b.Snapshot(), "\n")[0]; line != "日本語e" {
+		t.Errorf("Snapshot must skip the cells after wide characters: %q", line)
+	}
+}
+
+func TestEditFieldWideText(t *testing.T) {
+	b := initHeadless(t, 20, 5)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 12, 3, "Edit")
+	edit := CreateEditField(wnd, 8, "ab日本語e\u0301", Fixed)
+	ActivateControl(wnd, edit)
+
+	row := func() string {
+		RefreshScreen()
+		_, y := edit.Pos()
+		return strings.Split(b.Snapshot(), "\n")[y]
+	}
+
+	if s := row(); !strings.Contains(s, "←本語e") {
+		t.Errorf("The end of the text must be visible: %q", s)
+	}
+	if x, _ := b.CursorPos(); x != edit.x+6 {
+		t.Errorf("Cursor must be after the last character: %v", x)
+	}
+	SimulateKey(term.KeyHome, 0)
+	if s := row(); !strings.Contains(s, "ab日本 →") {
+		t.Errorf("The beginning of the text must be visible: %q", s)
+	}
+
+	// a combining mark is deleted together with its base
+	SimulateKey(term.KeyEnd, 0)
+	SimulateKey(term.KeyBackspace2, 0)
+	if edit.Title() != "ab日本語" {
+		t.Errorf("Backspace must delete the whole cluster: %q", edit.Title())
+	}
+	SimulateKey(term.KeyArrowLeft, 0)
+	SimulateKey(term.KeyArrowLeft, 0)
+	if x, _ := b.CursorPos(); edit.cursorPos != 3 || x != edit.x+3 {
+		t.Errorf("Cursor must move by characters: %v at %v", edit.cursorPos, x)
+	}
+
+	SimulateKey(term.KeyHome, 0)
+	SimulateKey(term.KeyDelete, 0)
+	if edit.Title() != "b日本語" {
+		t.Errorf("Delete must remove the character: %q", edit.Title())
+	}
+}
+
+func TestTextEditWideText(t *testing.T) {
+	b := initHeadless(t, 20, 8)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 16, 6, "Edit")
+	edit := CreateTextEdit(wnd, 12, 3, Fixed)
+	ActivateControl(wnd, edit)
+	for _, ch := range "日本語ab" {
+		SimulateKey(0, ch)
+	}
+
+	row := func(dy int) string {
+		RefreshScreen()
+		return strings.Split(b.Snapshot(), "\n")[edit.y+dy]
+	}
+	if s := row(0); !strings.Contains(s, "日本語ab") {
+		t.Errorf("Wide characters must take two cells: %q", s)
+	}
+	if x, _ := b.CursorPos(); x != edit.x+8 {
+		t.Errorf("Cursor must be after the last character: %v", x)
+	}
+
+	// a wide character that does not fit the row moves to the next one
+	edit.SetConstraints(6, 3)
+	edit.SetSize(6, 3)
+	edit.SetWordWrap(true)
+	if s := row(0); !strings.Contains(s, "日本 ") {
+		t.Errorf("Invalid first wrapped row: %q", s)
+	}
+	if s := row(1); !strings.Contains(s, "語ab") {
+		t.Errorf("Invalid second wrapped row: %q", s)
+	}
+	SimulateKey(term.KeyArrowLeft, 0)
+	SimulateKey(term.KeyArrowLeft, 0)
+	if x, y := b.CursorPos(); x != edit.x+2 || y != edit.y+1 {
+		t.Errorf("Cursor must move by characters: %v:%v", x, y)
+	}
+	SimulateKey(term.KeyArrowUp, 0)
+	if _, col := edit.CursorPos(); col != 1 {
+		t.Errorf("Cursor must keep the screen column: %v", col)
+	}
+	SimulateKey(term.KeyBackspace2, 0)
+	if edit.Text() != "本語ab" {
+		t.Errorf("Backspace must delete the wide character: %q", edit.Text())
+	}
+}
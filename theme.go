@@ -45,7 +45,11 @@ Theme file is a simple text file that has similar to INI file format:
         constants, just drop 'Color' at the beginning of key name.
         Rules of converting text to color:
         1. If the value does not end neither with 'Back' nor with 'Text'
-            it is considered as raw attribute value(e.g, 'green bold')
+            it is considered as raw attribute value(e.g, 'green bold').
+            Besides 8 base colors, the value can be a color from
+            256-color palette(e.g, 'color(202)') or a 24-bit color
+            (e.g, '#ff8800' or 'rgb(255,136,0)'). If a terminal does not
+            support that many colors, the closest color is displayed
         2. If the value ends with 'Back' or 'Text' it means that one
             of earlier defined attribute must be used. If the current
             scheme does not have that attribute defined (e.g, it is
@@ -81,16 +85,12 @@ type ThemeManager struct {
 	current   string
 	themePath string
 	version   string
+	mtx       sync.RWMutex
 }
 
 const defaultTheme = "default"
 const themeSuffix = ".theme"
 
-var (
-	themeManager *ThemeManager
-	thememtx     sync.RWMutex
-)
-
 // ThemeDesc is a detailed information about theme:
 // title, author, version number
 type ThemeDesc struct {
@@ -115,20 +115,21 @@ type theme struct {
 	objects map[string]string
 }
 
-// NewThemeManager creates a new theme manager
-func initThemeManager() {
-	themeManager = new(ThemeManager)
-	ThemeReset()
+// newThemeManager creates a new theme manager
+func newThemeManager() *ThemeManager {
+	s := new(ThemeManager)
+	s.ThemeReset()
+	return s
 }
 
 // ThemeReset removes all loaded themes from cache and reinitialize
 // the default theme
-func ThemeReset() {
-	thememtx.Lock()
-	defer thememtx.Unlock()
+func (s *ThemeManager) ThemeReset() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
 
-	themeManager.current = defaultTheme
-	themeManager.themes = make(map[string]theme, 0)
+	s.current = defaultTheme
+	s.themes = make(map[string]theme, 0)
 
 	defTheme := theme{parent: "", title: "Default Theme", author: "Vladimir V. Markelov", version: "1.0"}
 	defTheme.colors = make(map[string]term.Attribute, 0)
@@ -146,6 +147,8 @@ func ThemeReset() {
 	defTheme.objects[ObjSparkChart] = "█"
 	defTheme.objects[ObjTableView] = "─│┼▼▲"
 	defTheme.objects[ObjButton] = "▀█"
+	defTheme.objects[ObjMenu] = "─├┤√►"
+	defTheme.objects[ObjTreeView] = "▼► √"
 
 	defTheme.colors[ColorDisabledText] = ColorBlackBold
 	defTheme.colors[ColorDisabledBack] = ColorWhite
@@ -176,6 +179,8 @@ func ThemeReset() {
 	defTheme.colors[ColorEditActiveBack] = ColorYellow
 	defTheme.colors[ColorSelectionText] = ColorYellow
 	defTheme.colors[ColorSelectionBack] = ColorBlue
+	defTheme.colors[ColorEditErrorText] = ColorWhiteBold
+	defTheme.colors[ColorEditErrorBack] = ColorRed
 
 	defTheme.colors[ColorScrollBack] = ColorBlack
 	defTheme.colors[ColorScrollText] = ColorWhite
@@ -208,24 +213,42 @@ func ThemeReset() {
 	defTheme.colors[ColorTableHeaderText] = ColorWhite
 	defTheme.colors[ColorTableHeaderBack] = ColorBlack
 
-	themeManager.themes[defaultTheme] = defTheme
+	defTheme.colors[ColorMenuBack] = ColorWhite
+	defTheme.colors[ColorMenuText] = ColorBlack
+	defTheme.colors[ColorMenuActiveBack] = ColorGreen
+	defTheme.colors[ColorMenuActiveText] = ColorBlack
+	defTheme.colors[ColorMenuDisabledText] = ColorBlackBold
+
+	defTheme.colors[ColorStatusBack] = ColorCyan
+	defTheme.colors[ColorStatusText] = ColorBlack
+	defTheme.colors[ColorStatusKeyText] = ColorWhiteBold
+
+	defTheme.colors[ColorTooltipBack] = ColorYellow
+	defTheme.colors[ColorTooltipText] = ColorBlack
+
+	defTheme.colors[ColorTabBack] = ColorBlue
+	defTheme.colors[ColorTabText] = ColorWhite
+	defTheme.colors[ColorTabActiveBack] = ColorWhite
+	defTheme.colors[ColorTabActiveText] = ColorBlack
+
+	s.themes[defaultTheme] = defTheme
 }
 
 // SysColor returns attribute by its id for the current theme.
 // The method panics if theme loop is detected - check if
 // parent attribute is correct
-func SysColor(color string) term.Attribute {
-	thememtx.RLock()
-	sch, ok := themeManager.themes[themeManager.current]
+func (s *ThemeManager) SysColor(color string) term.Attribute {
+	s.mtx.RLock()
+	sch, ok := s.themes[s.current]
 	if !ok {
-		sch = themeManager.themes[defaultTheme]
+		sch = s.themes[defaultTheme]
 	}
-	thememtx.RUnlock()
+	s.mtx.RUnlock()
 
 	clr, okclr := sch.colors[color]
 	if !okclr {
 		visited := make(map[string]int, 0)
-		visited[themeManager.current] = 1
+		visited[s.current] = 1
 		if !ok {
 			visited[defaultTheme] = 1
 		}
@@ -234,11 +257,11 @@ func SysColor(color string) term.Attribute {
 			if sch.parent == "" {
 				break
 			}
-			themeManager.loadTheme(sch.parent)
-			thememtx.RLock()
-			sch = themeManager.themes[sch.parent]
+			s.loadTheme(sch.parent)
+			s.mtx.RLock()
+			sch = s.themes[sch.parent]
 			clr, okclr = sch.colors[color]
-			thememtx.RUnlock()
+			s.mtx.RUnlock()
 
 			if ok {
 				break
@@ -259,18 +282,18 @@ func SysColor(color string) term.Attribute {
 // theme. E.g, border lines for frame or arrows for scrollbar.
 // The method panics if theme loop is detected - check if
 // parent attribute is correct
-func SysObject(object string) string {
-	thememtx.RLock()
-	sch, ok := themeManager.themes[themeManager.current]
+func (s *ThemeManager) SysObject(object string) string {
+	s.mtx.RLock()
+	sch, ok := s.themes[s.current]
 	if !ok {
-		sch = themeManager.themes[defaultTheme]
+		sch = s.themes[defaultTheme]
 	}
-	thememtx.RUnlock()
+	s.mtx.RUnlock()
 
 	obj, okobj := sch.objects[object]
 	if !okobj {
 		visited := make(map[string]int, 0)
-		visited[themeManager.current] = 1
+		visited[s.current] = 1
 		if !ok {
 			visited[defaultTheme] = 1
 		}
@@ -280,11 +303,11 @@ func SysObject(object string) string {
 				break
 			}
 
-			themeManager.loadTheme(sch.parent)
-			thememtx.RLock()
-			sch = themeManager.themes[sch.parent]
+			s.loadTheme(sch.parent)
+			s.mtx.RLock()
+			sch = s.themes[sch.parent]
 			obj, okobj = sch.objects[object]
-			thememtx.RUnlock()
+			s.mtx.RUnlock()
 
 			if ok {
 				break
@@ -302,17 +325,17 @@ func SysObject(object string) string {
 }
 
 // ThemeNames returns the list of short theme names (file names)
-func ThemeNames() []string {
+func (s *ThemeManager) ThemeNames() []string {
 	var str []string
 	str = append(str, defaultTheme)
 
-	path := themeManager.themePath
+	path := s.themePath
 	if path == "" {
 		path = "." + string(os.PathSeparator)
 	}
 	files, err := ioutil.ReadDir(path)
 	if err != nil {
-		panic("Failed to read theme directory: " + themeManager.themePath)
+		panic("Failed to read theme directory: " + s.themePath)
 	}
 
 	for _, f := range files {
@@ -326,61 +349,61 @@ func ThemeNames() []string {
 }
 
 // CurrentTheme returns name of the current theme
-func CurrentTheme() string {
-	thememtx.RLock()
-	defer thememtx.RUnlock()
+func (s *ThemeManager) CurrentTheme() string {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
 
-	return themeManager.current
+	return s.current
 }
 
 // SetCurrentTheme changes the current theme.
 // Returns false if changing failed - e.g, theme does not exist
-func SetCurrentTheme(name string) bool {
-	thememtx.RLock()
-	_, ok := themeManager.themes[name]
-	thememtx.RUnlock()
+func (s *ThemeManager) SetCurrentTheme(name string) bool {
+	s.mtx.RLock()
+	_, ok := s.themes[name]
+	s.mtx.RUnlock()
 
 	if !ok {
-		tnames := ThemeNames()
+		tnames := s.ThemeNames()
 		for _, theme := range tnames {
 			if theme == name {
-				themeManager.loadTheme(theme)
+				s.loadTheme(theme)
 				break
 			}
 		}
 	}
 
-	thememtx.Lock()
-	defer thememtx.Unlock()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
 
-	if _, ok := themeManager.themes[name]; ok {
-		themeManager.current = name
+	if _, ok := s.themes[name]; ok {
+		s.current = name
 		return true
 	}
 	return false
 }
 
 // ThemePath returns the current directory with theme inside it
-func ThemePath() string {
-	return themeManager.themePath
+func (s *ThemeManager) ThemePath() string {
+	return s.themePath
 }
 
 // SetThemePath changes the directory that contains themes.
 // If new path does not equal old one, theme list reloads
-func SetThemePath(path string) {
-	if path == themeManager.themePath {
+func (s *ThemeManager) SetThemePath(path string) {
+	if path == s.themePath {
 		return
 	}
 
-	themeManager.themePath = path
-	ThemeReset()
+	s.themePath = path
+	s.ThemeReset()
 }
 
 // loadTheme loads the theme if it is not in the cache already.
 // If theme is in the cache loadTheme does nothing
 func (s *ThemeManager) loadTheme(name string) {
-	thememtx.Lock()
-	defer thememtx.Unlock()
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
 
 	if _, ok := s.themes[name]; ok {
 		return
@@ -456,7 +479,7 @@ func (s *ThemeManager) loadTheme(name string) {
 				}
 			} else {
 				c := StringToColor(value)
-				if c%32 == 0 {
+				if !isColorSet(c) {
 					panic("Failed to read color: " + value)
 				}
 				theme.colors[key] = c
@@ -472,30 +495,30 @@ func (s *ThemeManager) loadTheme(name string) {
 // ReloadTheme refresh cache entry for the theme with new
 // data loaded from file. Use it to apply theme changes on
 // the fly without resetting manager or restarting application
-func ReloadTheme(name string) {
+func (s *ThemeManager) ReloadTheme(name string) {
 	if name == defaultTheme {
 		// default theme cannot be reloaded
 		return
 	}
 
-	thememtx.Lock()
-	if _, ok := themeManager.themes[name]; ok {
-		delete(themeManager.themes, name)
+	s.mtx.Lock()
+	if _, ok := s.themes[name]; ok {
+		delete(s.themes, name)
 	}
-	thememtx.Unlock()
+	s.mtx.Unlock()
 
-	themeManager.loadTheme(name)
+	s.loadTheme(name)
 }
 
 // ThemeInfo returns detailed info about theme
-func ThemeInfo(name string) ThemeDesc {
-	themeManager.loadTheme(name)
+func (s *ThemeManager) ThemeInfo(name string) ThemeDesc {
+	s.loadTheme(name)
 
-	thememtx.RLock()
-	defer thememtx.RUnlock()
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
 
 	var theme ThemeDesc
-	if t, ok := themeManager.themes[name]; !ok {
+	if t, ok := s.themes[name]; !ok {
 		theme.parent = t.parent
 		theme.title = t.title
 		theme.version = t.version
@@ -524,7 +547,7 @@ func ThemeInfo(name string) ThemeDesc {
 // clr - current object color
 // style - the theme prefix style set
 // id - color ID in theme
-func RealColor(clr term.Attribute, style string, id string) term.Attribute {
+func (s *ThemeManager) RealColor(clr term.Attribute, style string, id string) term.Attribute {
 	var prefix string
 
 	if style != "" {
@@ -534,7 +557,7 @@ func RealColor(clr term.Attribute, style string, id string) term.Attribute {
 	ccolor := fmt.Sprintf("%s%s", prefix, id)
 
 	if clr == ColorDefault {
-		clr = SysColor(ccolor)
+		clr = s.SysColor(ccolor)
 	}
 
 	if clr == ColorDefault {
@@ -543,3 +566,58 @@ func RealColor(clr term.Attribute, style string, id string) term.Attribute {
 
 	return clr
 }
+
+// ThemeReset calls ThemeManager.ThemeReset of the default App
+func ThemeReset() {
+	defaultApp.ThemeReset()
+}
+
+// SysColor calls ThemeManager.SysColor of the default App
+func SysColor(color string) term.Attribute {
+	return defaultApp.SysColor(color)
+}
+
+// SysObject calls ThemeManager.SysObject of the default App
+func SysObject(object string) string {
+	return defaultApp.SysObject(object)
+}
+
+// ThemeNames calls ThemeManager.ThemeNames of the default App
+func ThemeNames() []string {
+	return defaultApp.ThemeNames()
+}
+
+// CurrentTheme calls ThemeManager.CurrentTheme of the default App
+func CurrentTheme() string {
+	return defaultApp.CurrentTheme()
+}
+
+// SetCurrentTheme calls ThemeManager.SetCurrentTheme of the default App
+func SetCurrentTheme(name string) bool {
+	return defaultApp.SetCurrentTheme(name)
+}
+
+// ThemePath calls ThemeManager.ThemePath of the default App
+func ThemePath() string {
+	return defaultApp.ThemePath()
+}
+
+// SetThemePath calls ThemeManager.SetThemePath of the default App
+func SetThemePath(path string) {
+	defaultApp.SetThemePath(path)
+}
+
+// ReloadTheme calls ThemeManager.ReloadTheme of the default App
+func ReloadTheme(name string) {
+	defaultApp.ReloadTheme(name)
+}
+
+// ThemeInfo calls ThemeManager.ThemeInfo of the default App
+func ThemeInfo(name string) ThemeDesc {
+	return defaultApp.ThemeInfo(name)
+}
+
+// RealColor calls ThemeManager.RealColor of the default App
+func RealColor(clr term.Attribute, style string, id string) term.Attribute {
+	return defaultApp.RealColor(clr, style, id)
+}
@@ -0,0 +1,164 @@
+package clui
+
+import (
+	"sync"
+	"time"
+)
+
+/*
+Timer is a callback scheduled with AfterFunc or Every. When the time comes,
+the timer sends EventTimer to the main loop and the callback is called on the
+main loop goroutine, so it is safe to change controls inside the callback
+without Invoke. The areas invalidated by the callback are repainted at the
+next frame.
+*/
+type Timer struct {
+	id       int
+	interval time.Duration
+	repeat   bool
+	fn       func()
+	timer    *time.Timer
+	stopped  bool
+	// the list of the App that runs the timer
+	list *timerList
+}
+
+// timerList keeps all active timers by their IDs. EventTimer refers to a
+// timer by its ID in X field
+type timerList struct {
+	mtx    sync.Mutex
+	nextID int
+	timers map[int]*Timer
+	// the main loop that receives EventTimer
+	loop *mainLoop
+}
+
+func newTimerList(loop *mainLoop) *timerList {
+	return &timerList{timers: make(map[int]*Timer), loop: loop}
+}
+
+// AfterFunc calls fn on the App main loop goroutine once after the
+// duration has elapsed. The call can be cancelled with Stop method of the
+// returned timer
+func (a *App) AfterFunc(d time.Duration, fn func()) *Timer {
+	return a.timers.add(d, fn, false)
+}
+
+// Every calls fn on the App main loop goroutine periodically until the
+// timer is stopped. The interval is measured from the moment the previous
+// call finishes, so a slow callback never piles up unprocessed ticks
+func (a *App) Every(interval time.Duration, fn func()) *Timer {
+	return a.timers.add(interval, fn, true)
+}
+
+// AfterFunc calls App.AfterFunc of the default App
+func AfterFunc(d time.Duration, fn func()) *Timer {
+	return defaultApp.AfterFunc(d, fn)
+}
+
+// Every calls App.Every of the default App
+func Every(interval time.Duration, fn func()) *Timer {
+	return defaultApp.Every(interval, fn)
+}
+
+func (l *timerList) add(d time.Duration, fn func(), repeat bool) *Timer {
+	t := &Timer{interval: d, repeat: repeat, fn: fn, list: l}
+	if fn == nil {
+		t.stopped = true
+		return t
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	l.nextID++
+	t.id = l.nextID
+	l.timers[t.id] = t
+	t.start()
+
+	return t
+}
+
+// start must be called with the list locked
+func (t *Timer) start() {
+	id, l := t.id, t.list.loop
+	t.timer = time.AfterFunc(t.interval, func() {
+		l.put(Event{Type: EventTimer, X: id})
+	})
+}
+
+// fire is called by the main loop when EventTimer arrives
+func (l *timerList) fire(id int) {
+	l.mtx.Lock()
+	t, ok := l.timers[id]
+	if ok && !t.repeat {
+		delete(l.timers, id)
+	}
+	l.mtx.Unlock()
+
+	if !ok {
+		return
+	}
+
+	t.fn()
+
+	if t.repeat {
+		l.mtx.Lock()
+		if !t.stopped {
+			t.start()
+		}
+		l.mtx.Unlock()
+	}
+}
+
+// Stop cancels the timer. Returns false if the timer has already been
+// stopped or if it was created by AfterFunc and has already fired. After
+// Stop is called from the main loop goroutine the callback is never called
+// again. Stop can be called from any goroutine
+func (t *Timer) Stop() bool {
+	if t.list == nil {
+		return false
+	}
+	t.list.mtx.Lock()
+	defer t.list.mtx.Unlock()
+
+	if t.stopped {
+		return false
+	}
+	t.stopped = true
+
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	if _, ok := t.list.timers[t.id]; !ok {
+		return false
+	}
+	delete(t.list.timers, t.id)
+	return true
+}
+
+// Active returns true if the timer is going to call its callback
+func (t *Timer) Active() bool {
+	if t.list == nil {
+		return false
+	}
+	t.list.mtx.Lock()
+	defer t.list.mtx.Unlock()
+
+	_, ok := t.list.timers[t.id]
+	return ok && !t.stopped
+}
+
+// stopAll cancels all active timers
+func (l *timerList) stopAll() {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	for id, t := range l.timers {
+		t.stopped = true
+		if t.timer != nil {
+			t.timer.Stop()
+		}
+		delete(l.timers, id)
+	}
+}
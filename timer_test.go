@@ -0,0 +1,70 @@
+package clui
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimers(t *testing.T) {
+	initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	done := make(chan struct{})
+	go func() {
+		MainLoop()
+		close(done)
+	}()
+
+	fired := make(chan struct{})
+	once := AfterFunc(5*time.Millisecond, func() {
+		close(fired)
+	})
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("AfterFunc callback was not called")
+	}
+	if once.Active() {
+		t.Error("Fired AfterFunc timer must be inactive")
+	}
+	if once.Stop() {
+		t.Error("Stop must return false for fired timer")
+	}
+
+	cancelled := AfterFunc(20*time.Millisecond, func() {
+		t.Error("Cancelled timer must not fire")
+	})
+	if !cancelled.Stop() {
+		t.Error("Stop must return true for pending timer")
+	}
+
+	ticks := 0
+	ticked := make(chan struct{})
+	var tick *Timer
+	InvokeSync(func() {
+		tick = Every(2*time.Millisecond, func() {
+			ticks++
+			if ticks == 3 {
+				tick.Stop()
+				close(ticked)
+			}
+		})
+	})
+	select {
+	case <-ticked:
+	case <-time.After(time.Second):
+		t.Fatal("Every callback was not called three times")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	var count int
+	InvokeSync(func() {
+		count = ticks
+	})
+	if count != 3 {
+		t.Errorf("Stopped timer must not fire: %v ticks instead of 3", count)
+	}
+
+	Stop()
+	<-done
+}
@@ -0,0 +1,207 @@
+package clui
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	term "github.com/nsf/termbox-go"
+)
+
+// DefaultTooltipDelay is the time the mouse cursor must rest over a control
+// before its tooltip is displayed
+const DefaultTooltipDelay = 700 * time.Millisecond
+
+// TooltipDelay returns the time the mouse cursor must rest over a control
+// before its tooltip is displayed
+func (a *App) TooltipDelay() time.Duration {
+	return time.Duration(atomic.LoadInt64(&a.tooltipDelay))
+}
+
+// SetTooltipDelay changes the time the mouse cursor must rest over a
+// control before its tooltip is displayed. Zero delay displays tooltips
+// immediately, negative one turns tooltips off
+func (a *App) SetTooltipDelay(delay time.Duration) {
+	atomic.StoreInt64(&a.tooltipDelay, int64(delay))
+}
+
+// TooltipDelay calls App.TooltipDelay of the default App
+func TooltipDelay() time.Duration {
+	return defaultApp.TooltipDelay()
+}
+
+// SetTooltipDelay calls App.SetTooltipDelay of the default App
+func SetTooltipDelay(delay time.Duration) {
+	defaultApp.SetTooltipDelay(delay)
+}
+
+// hoverHandler is a control that has a callback for mouse hover changes
+type hoverHandler interface {
+	hoverChanged(hover bool)
+}
+
+// tooltipOwner is a control that can have a tooltip
+type tooltipOwner interface {
+	Tooltip() string
+}
+
+// isHoverEvent returns true if the mouse moves without pressed buttons
+func isHoverEvent(ev Event) bool {
+	return ev.Key == term.MouseRelease && ev.Mod == term.ModMotion
+}
+
+// controlUnderMouse returns the control under the mouse cursor that can
+// receive hover events, or nil if the cursor is over a menu or a drop-down
+// list, or outside windows
+func (c *Composer) controlUnderMouse(x, y int) Control {
+	if c.menuActive() {
+		return nil
+	}
+	if d := c.dropDown; d != nil {
+		lx, ly := d.list.Pos()
+		lw, lh := d.list.Size()
+		if x >= lx && x < lx+lw && y >= ly && y < ly+lh {
+			return nil
+		}
+	}
+
+	view, _ := c.checkWindowUnderMouse(x, y)
+	if view == nil {
+		return nil
+	}
+	if top := c.topWindow(); view != top && top.Modal() {
+		return nil
+	}
+
+	return ChildAt(view, x, y)
+}
+
+// trackHover sends EventMouseLeave to the control the mouse cursor has
+// left and EventMouseEnter to the control under the cursor. Any mouse event
+// except hovering hides the tooltip
+func (c *Composer) trackHover(ev Event) {
+	if !isHoverEvent(ev) {
+		c.hideTooltip()
+	}
+	if c.dragType != DragNone {
+		return
+	}
+
+	ctrl := c.controlUnderMouse(ev.X, ev.Y)
+	// the displayed tooltip stays where it has appeared
+	if !c.tooltipVisible() {
+		c.tipX, c.tipY = ev.X, ev.Y
+	}
+	if ctrl == c.hover {
+		return
+	}
+
+	old := c.hover
+	c.hover = ctrl
+	c.hideTooltip()
+	if old != nil {
+		old.ProcessEvent(Event{Type: EventMouseLeave, Target: old})
+		if h, ok := old.(hoverHandler); ok {
+			h.hoverChanged(false)
+		}
+	}
+	if ctrl != nil {
+		x, y := ctrl.Pos()
+		ctrl.ProcessEvent(Event{Type: EventMouseEnter, X: ev.X - x, Y: ev.Y - y, Mod: ev.Mod, Target: ctrl})
+		if h, ok := ctrl.(hoverHandler); ok {
+			h.hoverChanged(true)
+		}
+		if isHoverEvent(ev) {
+			c.scheduleTooltip(ctrl)
+		}
+	}
+}
+
+// scheduleTooltip displays the tooltip of the control or its closest parent
+// that has a tooltip after the tooltip delay
+func (c *Composer) scheduleTooltip(ctrl Control) {
+	text := ""
+	for ; ctrl != nil && text == ""; ctrl = ctrl.Parent() {
+		if owner, ok := ctrl.(tooltipOwner); ok {
+			text = owner.Tooltip()
+		}
+	}
+	delay := c.app.TooltipDelay()
+	if text == "" || delay < 0 {
+		return
+	}
+
+	if delay == 0 {
+		c.tipText = text
+		c.app.InvalidateScreen()
+		return
+	}
+	c.tipTimer = c.app.AfterFunc(delay, func() {
+		c.tipTimer = nil
+		c.tipText = text
+		c.app.InvalidateScreen()
+	})
+}
+
+// hideTooltip closes the tooltip or cancels its displaying
+func (c *Composer) hideTooltip() {
+	if c.tipTimer != nil {
+		c.tipTimer.Stop()
+		c.tipTimer = nil
+	}
+	if c.tipText != "" {
+		c.tipText = ""
+		c.app.InvalidateScreen()
+	}
+}
+
+// tooltipVisible returns true if a tooltip is displayed
+func (c *Composer) tooltipVisible() bool {
+	return c.tipText != ""
+}
+
+// drawTooltip displays the tooltip under the mouse cursor. If there is not
+// enough space, the tooltip is moved to the left or above the cursor
+func (c *Composer) drawTooltip() {
+	if c.tipText == "" {
+		return
+	}
+
+	lines := strings.Split(c.tipText, "\n")
+	width := 0
+	for _, line := range lines {
+		if l := TextWidth(UnColorizeText(line)); l > width {
+			width = l
+		}
+	}
+	width += 2
+	height := len(lines)
+
+	sw, sh := c.app.ScreenSize()
+	x, y := c.tipX, c.tipY+1
+	if x+width > sw {
+		x = sw - width
+	}
+	if x < 0 {
+		x = 0
+	}
+	if y+height > sh {
+		y = c.tipY - height
+	}
+	if y < 0 {
+		y = 0
+	}
+
+	c.app.PushAttributes()
+	defer c.app.PopAttributes()
+	c.app.PushClip()
+	defer c.app.PopClip()
+	c.app.SetClipRect(0, 0, sw, sh)
+
+	c.app.SetBackColor(c.app.SysColor(ColorTooltipBack))
+	c.app.SetTextColor(c.app.SysColor(ColorTooltipText))
+	c.app.FillRect(x, y, width, height, ' ')
+	for i, line := range lines {
+		c.app.DrawText(x+1, y+i, line)
+	}
+}
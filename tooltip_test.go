@@ -0,0 +1,153 @@
+package clui
+
+import (
+	"testing"
+	"time"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func hover(x, y int) {
+	SimulateEvent(Event{Type: EventMouse, Key: term.MouseRelease, Mod: term.ModMotion, X: x, Y: y})
+}
+
+func TestHover(t *testing.T) {
+	initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 30, 8, "Form")
+	wnd.SetPack(Vertical)
+	btn := CreateButton(wnd, 10, 4, "Save", Fixed)
+	label := CreateLabel(wnd, 10, 1, "Ready", Fixed)
+
+	var events []bool
+	btn.OnHover(func(hover bool) {
+		events = append(events, hover)
+	})
+	clicked := 0
+	btn.OnClick(func(ev Event) {
+		clicked++
+	})
+
+	bx, by := btn.Pos()
+	hover(bx+1, by+1)
+	hover(bx+2, by+1)
+	if len(events) != 1 || !events[0] || defaultApp.comp.hover != btn {
+		t.Fatalf("Mouse must enter the button once: %v", events)
+	}
+	if clicked != 0 {
+		t.Error("Hovering must not click the button")
+	}
+
+	lx, ly := label.Pos()
+	hover(lx+1, ly)
+	if len(events) != 2 || events[1] || defaultApp.comp.hover != label {
+		t.Errorf("Mouse must leave the button: %v", events)
+	}
+
+	hover(35, 10)
+	if defaultApp.comp.hover != nil {
+		t.Error("No control is under the cursor outside windows")
+	}
+}
+
+func TestTooltip(t *testing.T) {
+	b := initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+	defer SetTooltipDelay(DefaultTooltipDelay)
+
+	wnd := AddWindow(0, 0, 30, 8, "Form")
+	wnd.SetPack(Vertical)
+	name := CreateEditField(wnd, 20, "", Fixed)
+	name.SetTooltip("Your full name")
+	frame := CreateFrame(wnd, 20, 3, BorderThin, Fixed)
+	frame.SetTooltip("Options\nof the form")
+	check := CreateCheckBox(frame, 10, "Hidden", Fixed)
+
+	SetTooltipDelay(time.Hour)
+	nx, ny := name.Pos()
+	hover(nx+2, ny)
+	if defaultApp.comp.tooltipVisible() || defaultApp.comp.tipTimer == nil {
+		t.Fatal("Tooltip must wait for the delay")
+	}
+	SimulateKey(term.KeyArrowLeft, 0)
+	if defaultApp.comp.tipTimer != nil {
+		t.Error("Key press must cancel the tooltip")
+	}
+
+	SetTooltipDelay(0)
+	hover(35, 10)
+	hover(nx+2, ny)
+	if defaultApp.comp.tipText != "Your full name" {
+		t.Errorf("Invalid tooltip: '%v'", defaultApp.comp.tipText)
+	}
+	SimulateClick(nx+2, ny)
+	if defaultApp.comp.tooltipVisible() {
+		t.Error("Click must hide the tooltip")
+	}
+
+	// the check box inherits the frame tooltip
+	cx, cy := check.Pos()
+	hover(cx+1, cy)
+	if defaultApp.comp.tipText != "Options\nof the form" {
+		t.Errorf("Invalid tooltip: '%v'", defaultApp.comp.tipText)
+	}
+	hover(cx+2, cy)
+	RefreshScreen()
+	checkSnapshot(t, b, "tooltip")
+
+	SetTooltipDelay(-1)
+	hover(nx+2, ny)
+	if defaultApp.comp.tooltipVisible() {
+		t.Error("Negative delay must turn tooltips off")
+	}
+}
+
+// eventProbe is a control that records types of events it receives
+type eventProbe struct {
+	BaseControl
+	events []EventType
+}
+
+func (p *eventProbe) Draw() {
+}
+
+func (p *eventProbe) ProcessEvent(ev Event) bool {
+	p.events = append(p.events, ev.Type)
+	return false
+}
+
+func TestHoverContainer(t *testing.T) {
+	initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 30, 8, "Form")
+	frame := CreateFrame(wnd, 20, 4, BorderThin, Fixed)
+	probe := &eventProbe{BaseControl: NewBaseControl()}
+	probe.SetSize(5, 1)
+	probe.SetConstraints(5, 1)
+	probe.parent = frame
+	frame.AddChild(probe)
+	ActivateControl(wnd, probe)
+	RefreshScreen()
+
+	// empty space of the frame and of the window
+	fx, fy := frame.Pos()
+	hover(fx+10, fy+2)
+	hover(25, 3)
+	if defaultApp.comp.hover != wnd {
+		t.Fatalf("Window must be under the cursor: %v", defaultApp.comp.hover)
+	}
+	for _, tp := range probe.events {
+		if tp == EventMouseEnter || tp == EventMouseLeave {
+			t.Errorf("Active control must not receive hover events of its parents: %v", probe.events)
+			break
+		}
+	}
+
+	px, py := probe.Pos()
+	hover(px+1, py)
+	if len(probe.events) == 0 || probe.events[len(probe.events)-1] != EventMouseEnter {
+		t.Errorf("Control under the cursor must receive EventMouseEnter: %v", probe.events)
+	}
+}
@@ -0,0 +1,806 @@
+package clui
+
+import (
+	"strings"
+
+	term "github.com/nsf/termbox-go"
+)
+
+/*
+TreeView is a control to display a hierarchy of nodes. A node with
+children can be expanded and collapsed. Content is scrollable with arrow
+keys, mouse wheel and scrollbars: the vertical scrollbar is always
+displayed, the horizontal one appears when nodes do not fit the control.
+
+Children can be loaded lazily: if TreeView has OnLoadChildren callback,
+the callback is called the first time a user expands a node, and it adds
+the node children with TreeNode.AddNode. Until then every node that is
+not marked as a leaf(see TreeNode.SetLeaf) is displayed as expandable.
+
+Node glyphs are taken from theme object TreeView: expanded node,
+collapsed node, leaf node, and the mark of a selected node.
+
+Predefined hotkeys:
+  Up, Down, PgUp, PgDn, Home, End - move the cursor
+  Right, + - expand the node. Right on the expanded node moves the cursor
+        to its first child
+  Left, - - collapse the node. Left on the collapsed node moves the cursor
+        to its parent
+  Enter, double click - toggle the node that has children, or emit
+        OnActivateNode for a leaf
+  Space, click with Ctrl - select or deselect the node in multi-select mode
+  Insert - select or deselect the node in multi-select mode and move the
+        cursor down
+
+Events:
+  OnLoadChildren - called before a node is expanded the first time
+  OnSelectNode - called after the cursor moves to another node
+  OnActivateNode - called after a user presses Enter or double clicks
+        a leaf node
+  OnKeyPress - called every time a user presses a key. Callback should
+        return true if TreeView must skip internal key processing
+*/
+type TreeView struct {
+	BaseControl
+	root        *TreeNode
+	current     *TreeNode
+	topLine     int
+	leftCol     int
+	multiSelect bool
+	buttonPos   int
+
+	onLoadChildren func(*TreeNode)
+	onSelectNode   func(*TreeNode)
+	onActivateNode func(*TreeNode)
+	onKeyPress     func(term.Key) bool
+}
+
+// TreeNode is a node of TreeView. Text may contain color tags, Data is
+// any value an application associates with the node
+type TreeNode struct {
+	Text string
+	Data interface{}
+
+	tree     *TreeView
+	parent   *TreeNode
+	children []*TreeNode
+	expanded bool
+	loaded   bool
+	leaf     bool
+	selected bool
+}
+
+/*
+CreateTreeView creates a new empty TreeView.
+parent - is container that keeps the control.
+width and height - are minimal size of the control.
+scale - the way of scaling the control when the parent is resized. Use DoNotScale constant if the
+control should keep its original size.
+*/
+func CreateTreeView(parent Control, width, height int, scale int) *TreeView {
+	t := new(TreeView)
+	t.BaseControl = NewBaseControl()
+
+	if height == AutoSize {
+		height = 3
+	}
+	if width == AutoSize {
+		width = 10
+	}
+
+	t.SetSize(width, height)
+	t.SetConstraints(width, height)
+	t.root = &TreeNode{tree: t, expanded: true, loaded: true}
+	t.parent = parent
+	t.buttonPos = -1
+
+	t.SetTabStop(true)
+	t.SetScale(scale)
+
+	if parent != nil {
+		parent.AddChild(t)
+	}
+
+	return t
+}
+
+// AddNode adds a new child node to the end of the node children and
+// returns it
+func (n *TreeNode) AddNode(text string) *TreeNode {
+	n.tree.Invalidate()
+	child := &TreeNode{Text: text, tree: n.tree, parent: n}
+	n.children = append(n.children, child)
+	n.loaded = true
+	return child
+}
+
+// Parent returns the parent node or nil for a top level node
+func (n *TreeNode) Parent() *TreeNode {
+	if n.parent == nil || n.parent == n.tree.root {
+		return nil
+	}
+	return n.parent
+}
+
+// Children returns the list of loaded children of the node
+func (n *TreeNode) Children() []*TreeNode {
+	res := make([]*TreeNode, len(n.children))
+	copy(res, n.children)
+	return res
+}
+
+// ChildCount returns the number of loaded children of the node
+func (n *TreeNode) ChildCount() int {
+	return len(n.children)
+}
+
+// Level returns the depth of the node: 0 for top level nodes
+func (n *TreeNode) Level() int {
+	level := -1
+	for p := n.parent; p != nil; p = p.parent {
+		level++
+	}
+	return level
+}
+
+// HasChildren returns true if the node has children or its children are
+// not loaded yet
+func (n *TreeNode) HasChildren() bool {
+	if len(n.children) > 0 {
+		return true
+	}
+	return !n.leaf && !n.loaded && n.tree.onLoadChildren != nil
+}
+
+// Leaf returns true if the node is marked as a leaf
+func (n *TreeNode) Leaf() bool {
+	return n.leaf
+}
+
+// SetLeaf marks the node as a node that never has children: it is not
+// displayed as expandable and its children are never loaded
+func (n *TreeNode) SetLeaf(leaf bool) {
+	n.tree.Invalidate()
+	n.leaf = leaf
+	if leaf {
+		n.SetExpanded(false)
+	}
+}
+
+// Expanded returns true if the node children are displayed
+func (n *TreeNode) Expanded() bool {
+	return n.expanded
+}
+
+// SetExpanded expands or collapses the node. Expanding the node loads
+// its children if they are not loaded yet. A node without children
+// cannot be expanded
+func (n *TreeNode) SetExpanded(expanded bool) {
+	n.tree.Invalidate()
+	t := n.tree
+	if !expanded {
+		if n.expanded && t.current != nil && t.current != n && t.current.isDescendantOf(n) {
+			t.current = n
+		}
+		n.expanded = false
+		t.clampScroll()
+		return
+	}
+
+	if n.leaf {
+		return
+	}
+	if !n.loaded && t.onLoadChildren != nil {
+		n.loaded = true
+		t.onLoadChildren(n)
+	}
+	n.expanded = len(n.children) > 0
+}
+
+// Selected returns true if the node is selected in multi-select mode
+func (n *TreeNode) Selected() bool {
+	return n.selected
+}
+
+// SetSelected selects or deselects the node
+func (n *TreeNode) SetSelected(selected bool) {
+	n.tree.Invalidate()
+	n.selected = selected
+}
+
+// Remove deletes the node and all its children from the tree
+func (n *TreeNode) Remove() {
+	n.tree.Invalidate()
+	p := n.parent
+	if p == nil {
+		return
+	}
+
+	for i, child := range p.children {
+		if child == n {
+			p.children = append(p.children[:i], p.children[i+1:]...)
+			break
+		}
+	}
+
+	t := n.tree
+	if t.current != nil && t.current.isDescendantOf(n) {
+		t.current = nil
+		if p != t.root {
+			t.current = p
+		} else if len(p.children) > 0 {
+			t.current = p.children[0]
+		}
+	}
+	n.parent = nil
+	if len(p.children) == 0 {
+		p.expanded = false
+	}
+	t.clampScroll()
+}
+
+// Clear deletes all children of the node and collapses it. If TreeView
+// has OnLoadChildren callback, the children are loaded again the next time
+// the node is expanded
+func (n *TreeNode) Clear() {
+	n.tree.Invalidate()
+	n.SetExpanded(false)
+	for _, child := range n.children {
+		child.parent = nil
+	}
+	n.children = nil
+	n.loaded = false
+	n.tree.clampScroll()
+}
+
+// isDescendantOf returns true if the node is inside subtree of node p or
+// it is the node p
+func (n *TreeNode) isDescendantOf(p *TreeNode) bool {
+	for node := n; node != nil; node = node.parent {
+		if node == p {
+			return true
+		}
+	}
+	return false
+}
+
+// AddNode adds a new node to the top level of the tree and returns it
+func (t *TreeView) AddNode(text string) *TreeNode {
+	t.Invalidate()
+	n := t.root.AddNode(text)
+	if t.current == nil {
+		t.current = n
+	}
+	return n
+}
+
+// Nodes returns the list of top level nodes
+func (t *TreeView) Nodes() []*TreeNode {
+	return t.root.Children()
+}
+
+// Clear deletes all nodes
+func (t *TreeView) Clear() {
+	t.Invalidate()
+	t.root.children = nil
+	t.current = nil
+	t.topLine, t.leftCol = 0, 0
+}
+
+// CurrentNode returns the node under the cursor or nil if the tree is
+// empty
+func (t *TreeView) CurrentNode() *TreeNode {
+	return t.current
+}
+
+// SetCurrentNode moves the cursor to the node. All parents of the node
+// are expanded and the tree scrolls to make the node visible
+func (t *TreeView) SetCurrentNode(n *TreeNode) {
+	t.Invalidate()
+	if n == nil || n.tree != t || !n.isDescendantOf(t.root) || n == t.root {
+		return
+	}
+
+	for p := n.parent; p != t.root; p = p.parent {
+		p.SetExpanded(true)
+	}
+	t.current = n
+	t.EnsureVisible()
+}
+
+// MultiSelect returns true if a user can select several nodes
+func (t *TreeView) MultiSelect() bool {
+	return t.multiSelect
+}
+
+// SetMultiSelect turns on and off multi-select mode. In this mode a
+// selection mark is displayed before the node text
+func (t *TreeView) SetMultiSelect(multi bool) {
+	t.Invalidate()
+	t.multiSelect = multi
+}
+
+// SelectedNodes returns the selected nodes in the order they are in the
+// tree. If multi-select mode is off, the result contains only the current
+// node
+func (t *TreeView) SelectedNodes() []*TreeNode {
+	if !t.multiSelect {
+		if t.current == nil {
+			return nil
+		}
+		return []*TreeNode{t.current}
+	}
+
+	var res []*TreeNode
+	var walk func(*TreeNode)
+	walk = func(n *TreeNode) {
+		for _, child := range n.children {
+			if child.selected {
+				res = append(res, child)
+			}
+			walk(child)
+		}
+	}
+	walk(t.root)
+	return res
+}
+
+// OnLoadChildren sets the callback that is called before a node is
+// expanded the first time. The callback should add the node children
+// with TreeNode.AddNode. A node that gets no children is displayed as
+// a leaf
+func (t *TreeView) OnLoadChildren(fn func(*TreeNode)) {
+	t.onLoadChildren = fn
+}
+
+// OnSelectNode sets the callback that is called every time the cursor
+// moves to another node
+func (t *TreeView) OnSelectNode(fn func(*TreeNode)) {
+	t.onSelectNode = fn
+}
+
+// OnActivateNode sets the callback that is called every time a user
+// presses Enter or double clicks a leaf node, e.g to open it
+func (t *TreeView) OnActivateNode(fn func(*TreeNode)) {
+	t.onActivateNode = fn
+}
+
+// OnKeyPress sets the callback that is called when a user presses a Key while
+// the controls is active. If a handler processes the key it should return
+// true. If handler returns false it means that the default handler will
+// process the key
+func (t *TreeView) OnKeyPress(fn func(term.Key) bool) {
+	t.onKeyPress = fn
+}
+
+// rows returns the list of displayed nodes
+func (t *TreeView) rows() []*TreeNode {
+	var res []*TreeNode
+	var walk func(*TreeNode)
+	walk = func(n *TreeNode) {
+		for _, child := range n.children {
+			res = append(res, child)
+			if child.expanded {
+				walk(child)
+			}
+		}
+	}
+	walk(t.root)
+	return res
+}
+
+// rowIndex returns the index of the node in the list of displayed nodes
+func rowIndex(rows []*TreeNode, n *TreeNode) int {
+	for i, r := range rows {
+		if r == n {
+			return i
+		}
+	}
+	return -1
+}
+
+// treeGlyphs returns glyphs of expanded, collapsed and leaf nodes, and
+// the mark of selected node
+func treeGlyphs(a *App) []rune {
+	parts := []rune(a.SysObject(ObjTreeView))
+	def := []rune("-+ *")
+	for len(parts) < len(def) {
+		parts = append(parts, def[len(parts)])
+	}
+	return parts
+}
+
+// nodeLine returns the displayed text of the node including indentation
+// and glyphs
+func (t *TreeView) nodeLine(n *TreeNode, glyphs []rune) string {
+	glyph := glyphs[2]
+	if n.HasChildren() {
+		glyph = glyphs[1]
+		if n.expanded {
+			glyph = glyphs[0]
+		}
+	}
+
+	line := strings.Repeat("  ", n.Level()) + string(glyph) + " "
+	if t.multiSelect {
+		mark := ' '
+		if n.selected {
+			mark = glyphs[3]
+		}
+		line += string(mark) + " "
+	}
+	return line + n.Text
+}
+
+// nodeWidth returns the width of the node line
+func (t *TreeView) nodeWidth(n *TreeNode) int {
+	w := n.Level()*2 + 2 + TextWidth(UnColorizeText(n.Text))
+	if t.multiSelect {
+		w += 2
+	}
+	return w
+}
+
+// contentWidth returns the width of the widest displayed node
+func (t *TreeView) contentWidth(rows []*TreeNode) int {
+	maxW := 0
+	for _, n := range rows {
+		if w := t.nodeWidth(n); w > maxW {
+			maxW = w
+		}
+	}
+	return maxW
+}
+
+// viewSize returns the size of the area for nodes and whether the
+// horizontal scrollbar is displayed
+func (t *TreeView) viewSize(rows []*TreeNode) (w, h int, hscroll bool) {
+	w, h = t.width-1, t.height
+	if t.contentWidth(rows) > w && h > 1 {
+		h--
+		hscroll = true
+	}
+	return w, h, hscroll
+}
+
+// Draw repaints the control on its View surface
+func (t *TreeView) Draw() {
+	if t.hidden {
+		return
+	}
+
+	a := AppOf(t)
+	a.PushAttributes()
+	defer a.PopAttributes()
+
+	t.clampScroll()
+	rows := t.rows()
+	viewW, viewH, hscroll := t.viewSize(rows)
+
+	fg, bg := a.RealColor(t.fg, t.Style(), ColorEditText), a.RealColor(t.bg, t.Style(), ColorEditBack)
+	if t.Active() {
+		fg, bg = a.RealColor(t.fg, t.Style(), ColorEditActiveText), a.RealColor(t.bg, t.Style(), ColorEditActiveBack)
+	}
+	fgSel, bgSel := a.RealColor(t.fgActive, t.Style(), ColorSelectionText), a.RealColor(t.bgActive, t.Style(), ColorSelectionBack)
+	a.SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.FillRect(t.x, t.y, t.width, t.height, ' ')
+
+	glyphs := treeGlyphs(a)
+	for dy := 0; dy < viewH && t.topLine+dy < len(rows); dy++ {
+		n := rows[t.topLine+dy]
+		if n == t.current {
+			a.SetTextColor(fgSel)
+			a.SetBackColor(bgSel)
+		} else {
+			a.SetTextColor(fg)
+			a.SetBackColor(bg)
+		}
+		a.FillRect(t.x, t.y+dy, viewW, 1, ' ')
+		line := SliceColorized(t.nodeLine(n, glyphs), t.leftCol, t.leftCol+viewW)
+		a.DrawText(t.x, t.y+dy, line)
+	}
+
+	curr := rowIndex(rows, t.current)
+	t.buttonPos = ThumbPosition(curr, len(rows), viewH)
+	a.DrawScrollBar(t.x+t.width-1, t.y, 1, viewH, t.buttonPos)
+	if hscroll {
+		maxLeft := t.contentWidth(rows) - viewW
+		pos := ThumbPosition(t.leftCol, maxLeft+1, viewW)
+		a.DrawScrollBar(t.x, t.y+t.height-1, viewW, 1, pos)
+		a.SetTextColor(fg)
+		a.SetBackColor(bg)
+		a.PutChar(t.x+t.width-1, t.y+t.height-1, ' ')
+	}
+}
+
+// clampScroll keeps the scroll positions inside the content
+func (t *TreeView) clampScroll() {
+	rows := t.rows()
+	viewW, viewH, _ := t.viewSize(rows)
+	t.topLine = clampScroll(t.topLine, len(rows)-viewH)
+	t.leftCol = clampScroll(t.leftCol, t.contentWidth(rows)-viewW)
+}
+
+// EnsureVisible scrolls the tree to make the current node visible
+func (t *TreeView) EnsureVisible() {
+	rows := t.rows()
+	curr := rowIndex(rows, t.current)
+	if curr == -1 {
+		return
+	}
+
+	viewW, viewH, _ := t.viewSize(rows)
+	if curr < t.topLine {
+		t.topLine = curr
+	} else if curr >= t.topLine+viewH {
+		t.topLine = curr - viewH + 1
+	}
+
+	// the node glyph must be visible
+	col := t.current.Level() * 2
+	if col < t.leftCol {
+		t.leftCol = col
+	} else if col+2 > t.leftCol+viewW {
+		t.leftCol = col + 2 - viewW
+	}
+	t.clampScroll()
+}
+
+// moveTo moves the cursor to the displayed node by its index
+func (t *TreeView) moveTo(idx int) {
+	rows := t.rows()
+	if len(rows) == 0 {
+		return
+	}
+	if idx < 0 {
+		idx = 0
+	} else if idx >= len(rows) {
+		idx = len(rows) - 1
+	}
+
+	t.selectNode(rows[idx])
+}
+
+// selectNode moves the cursor to the node and emits OnSelectNode event
+func (t *TreeView) selectNode(n *TreeNode) {
+	if n == t.current {
+		return
+	}
+
+	t.current = n
+	t.EnsureVisible()
+	if t.onSelectNode != nil {
+		t.onSelectNode(n)
+	}
+}
+
+// moveBy moves the cursor dy displayed nodes down(or up if dy is
+// negative)
+func (t *TreeView) moveBy(dy int) {
+	curr := rowIndex(t.rows(), t.current)
+	if curr == -1 {
+		curr = 0
+		dy = 0
+	}
+	t.moveTo(curr + dy)
+}
+
+// expandOrEnter expands the current node or moves the cursor to its first
+// child if the node is already expanded
+func (t *TreeView) expandOrEnter() {
+	n := t.current
+	if n == nil {
+		return
+	}
+	if !n.expanded {
+		n.SetExpanded(true)
+		t.EnsureVisible()
+		return
+	}
+	if len(n.children) > 0 {
+		t.selectNode(n.children[0])
+	}
+}
+
+// collapseOrLeave collapses the current node or moves the cursor to its
+// parent if the node is already collapsed
+func (t *TreeView) collapseOrLeave() {
+	n := t.current
+	if n == nil {
+		return
+	}
+	if n.expanded {
+		n.SetExpanded(false)
+		return
+	}
+	if p := n.Parent(); p != nil {
+		t.selectNode(p)
+	}
+}
+
+// activate toggles the node that has children or emits OnActivateNode
+// event for a leaf
+func (t *TreeView) activate(n *TreeNode) {
+	if n.HasChildren() {
+		n.SetExpanded(!n.expanded)
+		t.EnsureVisible()
+		return
+	}
+	if t.onActivateNode != nil {
+		t.onActivateNode(n)
+	}
+}
+
+// toggleSelected selects or deselects the current node in multi-select
+// mode
+func (t *TreeView) toggleSelected() bool {
+	if !t.multiSelect || t.current == nil {
+		return false
+	}
+	t.current.selected = !t.current.selected
+	return true
+}
+
+func (t *TreeView) processKey(event Event) bool {
+	if t.onKeyPress != nil && t.onKeyPress(event.Key) {
+		return true
+	}
+
+	_, viewH, _ := t.viewSize(t.rows())
+	switch {
+	case event.Ch == '+':
+		if t.current != nil {
+			t.current.SetExpanded(true)
+			t.EnsureVisible()
+		}
+	case event.Ch == '-':
+		if t.current != nil {
+			t.current.SetExpanded(false)
+		}
+	case event.Ch != 0:
+		return false
+	case event.Key == term.KeyArrowUp:
+		t.moveBy(-1)
+	case event.Key == term.KeyArrowDown:
+		t.moveBy(1)
+	case event.Key == term.KeyPgup:
+		t.moveBy(-viewH)
+	case event.Key == term.KeyPgdn:
+		t.moveBy(viewH)
+	case event.Key == term.KeyHome:
+		t.moveTo(0)
+	case event.Key == term.KeyEnd:
+		t.moveTo(len(t.rows()) - 1)
+	case event.Key == term.KeyArrowRight:
+		t.expandOrEnter()
+	case event.Key == term.KeyArrowLeft:
+		t.collapseOrLeave()
+	case event.Key == term.KeyEnter:
+		if t.current != nil {
+			t.activate(t.current)
+		}
+	case event.Key == term.KeySpace:
+		return t.toggleSelected()
+	case event.Key == term.KeyInsert:
+		if !t.toggleSelected() {
+			return false
+		}
+		t.moveBy(1)
+	default:
+		return false
+	}
+
+	return true
+}
+
+// glyphAt returns true if the local column x of the node row is the
+// expand glyph of the node
+func (t *TreeView) glyphAt(n *TreeNode, x int) bool {
+	return t.leftCol+x == n.Level()*2
+}
+
+// markAt returns true if the local column x of the node row is the
+// selection mark of the node
+func (t *TreeView) markAt(n *TreeNode, x int) bool {
+	return t.multiSelect && t.leftCol+x == n.Level()*2+2
+}
+
+func (t *TreeView) processMouseClick(ev Event) bool {
+	if ev.Key != term.MouseLeft {
+		return false
+	}
+
+	rows := t.rows()
+	viewW, viewH, hscroll := t.viewSize(rows)
+	dx := ev.X - t.x
+	dy := ev.Y - t.y
+	if dx < 0 || dx >= t.width || dy < 0 || dy >= t.height {
+		return true
+	}
+
+	if dx == t.width-1 {
+		if dy >= viewH || len(rows) < 2 {
+			return true
+		}
+		if dy == 0 {
+			t.moveBy(-1)
+		} else if dy == viewH-1 {
+			t.moveBy(1)
+		} else {
+			t.moveTo(ItemByThumbPosition(dy, len(rows), viewH))
+		}
+		return true
+	}
+
+	if hscroll && dy == t.height-1 {
+		maxLeft := t.contentWidth(rows) - viewW
+		if dx == 0 {
+			t.leftCol = clampScroll(t.leftCol-1, maxLeft)
+		} else if dx == viewW-1 {
+			t.leftCol = clampScroll(t.leftCol+1, maxLeft)
+		} else if pos := ItemByThumbPosition(dx, maxLeft+1, viewW); pos != -1 {
+			t.leftCol = pos
+		}
+		return true
+	}
+
+	if t.topLine+dy >= len(rows) {
+		return true
+	}
+
+	n := rows[t.topLine+dy]
+	t.selectNode(n)
+	if t.glyphAt(n, dx) && n.HasChildren() {
+		n.SetExpanded(!n.expanded)
+	} else if t.markAt(n, dx) || ev.Mod&ModCtrl != 0 {
+		t.toggleSelected()
+	}
+	return true
+}
+
+// wheelScroll scrolls the tree without moving the cursor
+func (t *TreeView) wheelScroll(dx, dy int) bool {
+	rows := t.rows()
+	viewW, viewH, _ := t.viewSize(rows)
+	t.topLine = clampScroll(t.topLine+dy, len(rows)-viewH)
+	t.leftCol = clampScroll(t.leftCol+dx, t.contentWidth(rows)-viewW)
+	return true
+}
+
+/*
+ProcessEvent processes all events come from the control parent. If a control
+processes an event it should return true. If the method returns false it means
+that the control do not want or cannot process the event and the caller sends
+the event to the control parent
+*/
+func (t *TreeView) ProcessEvent(event Event) bool {
+	if dx, dy := WheelDelta(event); dx != 0 || dy != 0 {
+		return t.Enabled() && t.wheelScroll(dx, dy)
+	}
+
+	if !t.Active() || !t.Enabled() {
+		return false
+	}
+
+	switch event.Type {
+	case EventKey:
+		return t.processKey(event)
+	case EventMouse:
+		return t.processMouseClick(event)
+	case EventDoubleClick:
+		// the first click has already moved the cursor
+		rows := t.rows()
+		_, viewH, _ := t.viewSize(rows)
+		if event.X >= t.width-1 || event.Y >= viewH || t.topLine+event.Y >= len(rows) {
+			return true
+		}
+		n := rows[t.topLine+event.Y]
+		if n == t.current && !t.glyphAt(n, event.X) && !t.markAt(n, event.X) {
+			t.activate(n)
+		}
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,132 @@
+package clui
+
+import (
+	"fmt"
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestTreeView(t *testing.T) {
+	b := initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 30, 8, "Files")
+	tree := CreateTreeView(wnd, 20, 5, 1)
+	loaded := 0
+	tree.OnLoadChildren(func(n *TreeNode) {
+		loaded++
+		if n.Level() >= 2 {
+			return
+		}
+		for i := 1; i <= 2; i++ {
+			n.AddNode(fmt.Sprintf("%v.%v", n.Text, i))
+		}
+		leaf := n.AddNode(n.Text + ".txt")
+		leaf.SetLeaf(true)
+	})
+	var activated []string
+	tree.OnActivateNode(func(n *TreeNode) {
+		activated = append(activated, n.Text)
+	})
+	tree.AddNode("src")
+	tree.AddNode("doc")
+	ActivateControl(wnd, tree)
+
+	if loaded != 0 {
+		t.Fatal("Children must be loaded only when a node is expanded")
+	}
+	SimulateKey(term.KeyArrowRight, 0)
+	SimulateKey(term.KeyArrowRight, 0)
+	if loaded != 1 || tree.CurrentNode().Text != "src.1" {
+		t.Fatalf("Right must expand the node and enter it: %v %q", loaded, tree.CurrentNode().Text)
+	}
+	SimulateKey(term.KeyArrowRight, 0)
+	SimulateKey(term.KeyArrowRight, 0)
+	SimulateKey(term.KeyArrowDown, 0)
+	SimulateKey(term.KeyArrowRight, 0)
+	if n := tree.CurrentNode(); n.Text != "src.1.2" || n.HasChildren() || loaded != 3 {
+		t.Errorf("Node without loaded children must be a leaf: %q", n.Text)
+	}
+	RefreshScreen()
+	checkSnapshot(t, b, "treeview")
+
+	// Left goes to the parent, the second Left collapses it
+	SimulateKey(term.KeyArrowLeft, 0)
+	SimulateKey(term.KeyArrowLeft, 0)
+	if n := tree.CurrentNode(); n.Text != "src.1" || n.Expanded() {
+		t.Errorf("Left must collapse the node: %q", n.Text)
+	}
+	SimulateKey(term.KeyEnd, 0)
+	SimulateKey(term.KeyEnter, 0)
+	if n := tree.CurrentNode(); n.Text != "doc" || !n.Expanded() {
+		t.Errorf("Enter must expand the node: %q", n.Text)
+	}
+	SimulateKey(term.KeyEnd, 0)
+	SimulateKey(term.KeyEnter, 0)
+	if len(activated) != 1 || activated[0] != "doc.txt" {
+		t.Errorf("Enter must activate a leaf: %v", activated)
+	}
+
+	// collapsing the parent of the current node moves the cursor
+	tree.CurrentNode().Parent().SetExpanded(false)
+	if tree.CurrentNode().Text != "doc" {
+		t.Errorf("Cursor must move to the collapsed node: %q", tree.CurrentNode().Text)
+	}
+
+	// mouse: a click on the glyph toggles the node
+	x, y := tree.Pos()
+	SimulateClick(x, y)
+	if n := tree.CurrentNode(); n.Text != "src" || n.Expanded() {
+		t.Errorf("Click on the glyph must collapse the node: %q", n.Text)
+	}
+}
+
+func TestTreeViewMultiSelect(t *testing.T) {
+	initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 30, 8, "Tree")
+	tree := CreateTreeView(wnd, 20, 5, Fixed)
+	root := tree.AddNode("root")
+	var last *TreeNode
+	for i := 0; i < 10; i++ {
+		last = root.AddNode(fmt.Sprintf("a long node name %v", i))
+	}
+	ActivateControl(wnd, tree)
+
+	if n := tree.SelectedNodes(); len(n) != 1 || n[0] != root {
+		t.Errorf("Single selection must return the current node: %v", n)
+	}
+
+	tree.SetMultiSelect(true)
+	tree.SetCurrentNode(last)
+	if !root.Expanded() || tree.topLine == 0 {
+		t.Errorf("Node must become visible: %v %v", root.Expanded(), tree.topLine)
+	}
+	SimulateKey(term.KeySpace, 0)
+	SimulateKey(term.KeyHome, 0)
+	SimulateKey(term.KeyInsert, 0)
+	SimulateKey(term.KeyInsert, 0)
+	sel := tree.SelectedNodes()
+	if len(sel) != 3 || sel[0] != root || sel[2] != last {
+		t.Errorf("Invalid selected nodes: %v", sel)
+	}
+
+	// the horizontal scrollbar is displayed for wide nodes
+	RefreshScreen()
+	_, viewH, hscroll := tree.viewSize(tree.rows())
+	if !hscroll || viewH != tree.height-1 {
+		t.Errorf("Horizontal scrollbar must be displayed: %v %v", hscroll, viewH)
+	}
+	tree.ProcessEvent(Event{Type: EventMouse, Key: term.MouseWheelDown, Mod: ModShift})
+	if tree.leftCol != 1 {
+		t.Errorf("Shift+wheel must scroll horizontally: %v", tree.leftCol)
+	}
+
+	last.Remove()
+	root.Clear()
+	if tree.CurrentNode() != root || root.ChildCount() != 0 || root.HasChildren() {
+		t.Errorf("Clear must remove children: %v", root.ChildCount())
+	}
+}
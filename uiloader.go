@@ -0,0 +1,630 @@
+package clui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// UI is a Window built from a document with LoadUI. It looks up controls
+// by their names and binds callbacks to them
+type UI struct {
+	// Window is the root window of the document
+	Window   *Window
+	controls map[string]Control
+	groups   map[string]*RadioGroup
+}
+
+// uiNode is a control described in a UI document
+type uiNode struct {
+	Type      string     `json:"type"`
+	Name      string     `json:"name"`
+	Title     string     `json:"title"`
+	Text      string     `json:"text"`
+	X         int        `json:"x"`
+	Y         int        `json:"y"`
+	Center    bool       `json:"center"`
+	Width     int        `json:"width"`
+	Height    int        `json:"height"`
+	Scale     int        `json:"scale"`
+	Pack      string     `json:"pack"`
+	Padding   *uiPair    `json:"padding"`
+	Gap       *uiPair    `json:"gap"`
+	Border    string     `json:"border"`
+	Style     string     `json:"style"`
+	Align     string     `json:"align"`
+	TextColor string     `json:"textColor"`
+	BackColor string     `json:"backColor"`
+	Hint      string     `json:"hint"`
+	Tooltip   string     `json:"tooltip"`
+	Enabled   *bool      `json:"enabled"`
+	Visible   *bool      `json:"visible"`
+	TabStop   *bool      `json:"tabStop"`
+	Active    bool       `json:"active"`
+	Modal     bool       `json:"modal"`
+	Buttons   []string   `json:"buttons"`
+	Multiline bool       `json:"multiline"`
+	Password  bool       `json:"password"`
+	Checked   bool       `json:"checked"`
+	Group     string     `json:"group"`
+	Items     []string   `json:"items"`
+	Selected  *int       `json:"selected"`
+	Columns   []uiColumn `json:"columns"`
+	Rows      int        `json:"rows"`
+	Min       int        `json:"min"`
+	Max       int        `json:"max"`
+	Value     int        `json:"value"`
+	Children  []*uiNode  `json:"children"`
+	// names of the properties set in the document
+	props []string
+}
+
+func (n *uiNode) UnmarshalJSON(data []byte) error {
+	type plainNode uiNode
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode((*plainNode)(n)); err != nil {
+		return err
+	}
+
+	var props map[string]json.RawMessage
+	if err := json.Unmarshal(data, &props); err != nil {
+		return err
+	}
+	n.props = n.props[:0]
+	for key := range props {
+		n.props = append(n.props, key)
+	}
+	sort.Strings(n.props)
+	return nil
+}
+
+// properties every control type supports
+const uiCommonProps = "type name width height scale align style textColor backColor hint tooltip enabled visible tabStop active"
+
+// properties that depend on control type
+var uiTypeProps = map[string]string{
+	"window":      "title x y center modal buttons pack padding gap children",
+	"frame":       "title border pack padding gap children",
+	"spacer":      "",
+	"page":        "title pack padding gap children",
+	"label":       "title multiline",
+	"button":      "title",
+	"editfield":   "text password",
+	"checkbox":    "title checked",
+	"radio":       "title checked group",
+	"listbox":     "items selected",
+	"combobox":    "text items selected",
+	"tableview":   "columns rows",
+	"textview":    "text",
+	"textedit":    "text",
+	"progressbar": "title min max value",
+	"tabview":     "children",
+}
+
+// checkProps returns an error if the document sets a property that the
+// control type does not support
+func checkProps(n *uiNode, typ, path string) error {
+	supported := strings.Fields(uiCommonProps + " " + uiTypeProps[typ])
+	for _, prop := range n.props {
+		found := false
+		for _, name := range supported {
+			if strings.EqualFold(prop, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%v.%v: property not supported by %v", path, prop, typ)
+		}
+	}
+	return nil
+}
+
+// uiColumn is a TableView column in a UI document
+type uiColumn struct {
+	Title string `json:"title"`
+	Width int    `json:"width"`
+	Align string `json:"align"`
+}
+
+// uiPair is a horizontal and vertical value, e.g paddings. A document can
+// set both values with one number or with a list of two numbers
+type uiPair [2]int
+
+func (p *uiPair) UnmarshalJSON(data []byte) error {
+	var n int
+	if err := json.Unmarshal(data, &n); err == nil {
+		p[0], p[1] = n, n
+		return nil
+	}
+
+	var list []int
+	if err := json.Unmarshal(data, &list); err != nil || len(list) != 2 {
+		return fmt.Errorf("expected a number or a list of two numbers instead of %s", data)
+	}
+	p[0], p[1] = list[0], list[1]
+	return nil
+}
+
+/*
+LoadUI creates a Window and its controls described by a JSON or YAML
+document. If the document starts with '{' it is JSON, otherwise it is
+YAML. The YAML parser supports mappings, lists, flow collections like
+[1, 0], quoted and plain scalars, and comments. Anchors, tags and block
+scalars(| and >) are not supported.
+
+The document describes the Window: an object with control properties and
+a list of child controls in "children". Every child is an object with the
+same properties and "type" - one of: frame, spacer(a Frame without border
+that fills space between controls), label, button, editfield, checkbox,
+radio, listbox, combobox, tableview, textview, textedit, progressbar,
+tabview, page(a page of tabview). Only window, frame, page and tabview
+can have children. Properties:
+
+	name - a name to look the control up with UI.Control
+	title - Window, Frame, Label, Button, CheckBox, Radio or page title
+	text - EditField, ComboBox, TextView or TextEdit text
+	width, height - minimal size, zero means AutoSize
+	scale - the way of scaling the control(see Control.SetScale)
+	pack - "vertical" or "horizontal" packing of children
+	padding, gap - a number or a list of horizontal and vertical values
+	border - Frame border: "none"(default), "thin", "thick" or "auto"
+	style, hint, tooltip - the same as Control setters
+	textColor, backColor - colors in StringToColor format
+	align - "left", "right" or "center": Label text or Button title
+	enabled, visible, tabStop - true or false
+	active - the control gets the keyboard focus
+	x, y, center, modal - Window position, or center it on the screen,
+	  and modal mode
+	buttons - Window title buttons: a list of "close", "bottom", "maximize"
+	multiline - Label displays multiline text
+	password - EditField hides its text
+	checked - CheckBox or Radio is checked
+	group - RadioGroup name: radios with the same group are exclusive
+	items, selected - ListBox and ComboBox items, and selected item
+	columns, rows - TableView columns(objects with title, width and
+	  align) and row count
+	min, max, value - ProgressBar limits and value
+
+Example of a confirmation dialog:
+
+	type: window
+	title: Confirm
+	center: true
+	modal: true
+	pack: vertical
+	children:
+	  - type: label
+	    title: Delete the file?
+	    scale: 1
+	  - type: frame
+	    pack: horizontal
+	    gap: 1
+	    children:
+	      - {type: spacer, scale: 1}
+	      - {type: button, name: yes, title: "Yes", active: true}
+	      - {type: button, name: no, title: "No"}
+
+Returns an error if the document is invalid, it has unknown properties, or
+properties that the control type does not support, e.g password of label.
+The function is not thread safe: call it from the main loop goroutine, or
+wrap with Invoke
+*/
+func (a *App) LoadUI(data []byte) (*UI, error) {
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte("{")) {
+		doc, err := parseYAML(data)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = json.Marshal(resolveYAML(doc, reflect.TypeOf(uiNode{}))); err != nil {
+			return nil, err
+		}
+	}
+
+	root := new(uiNode)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(root); err != nil {
+		return nil, err
+	}
+
+	u := &UI{controls: make(map[string]Control), groups: make(map[string]*RadioGroup)}
+	if err := u.buildWindow(a, root); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// LoadUIFile creates a Window from the JSON or YAML file(see LoadUI)
+func (a *App) LoadUIFile(path string) (*UI, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	u, err := a.LoadUI(data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	return u, nil
+}
+
+// LoadUI calls App.LoadUI of the default App
+func LoadUI(data []byte) (*UI, error) {
+	return defaultApp.LoadUI(data)
+}
+
+// LoadUIFile calls App.LoadUIFile of the default App
+func LoadUIFile(path string) (*UI, error) {
+	return defaultApp.LoadUIFile(path)
+}
+
+// Control returns the control by its name or nil if there is no control
+// with the name
+func (u *UI) Control(name string) Control {
+	return u.controls[name]
+}
+
+// RadioGroup returns the radio group by its name or nil if there is no
+// group with the name
+func (u *UI) RadioGroup(name string) *RadioGroup {
+	return u.groups[name]
+}
+
+// OnClick sets the callback of the Button. Returns false if there is no
+// Button with the name
+func (u *UI) OnClick(name string, fn func(Event)) bool {
+	btn, ok := u.controls[name].(*Button)
+	if ok {
+		btn.OnClick(fn)
+	}
+	return ok
+}
+
+// OnChange sets the callback that is called when the text of EditField,
+// TextEdit or ComboBox changes. Returns false if there is no such control
+// with the name
+func (u *UI) OnChange(name string, fn func(Event)) bool {
+	switch c := u.controls[name].(type) {
+	case *EditField:
+		c.OnChange(fn)
+	case *TextEdit:
+		c.OnChange(fn)
+	case *ComboBox:
+		c.OnChange(fn)
+	default:
+		return false
+	}
+	return true
+}
+
+// OnSelectItem sets the callback that is called when a user selects an
+// item of ListBox or ComboBox. Returns false if there is no such control
+// with the name
+func (u *UI) OnSelectItem(name string, fn func(Event)) bool {
+	switch c := u.controls[name].(type) {
+	case *ListBox:
+		c.OnSelectItem(fn)
+	case *ComboBox:
+		c.OnSelectItem(fn)
+	default:
+		return false
+	}
+	return true
+}
+
+func (u *UI) buildWindow(a *App, n *uiNode) error {
+	if !strings.EqualFold(n.Type, "window") {
+		return fmt.Errorf("the root control must be a window instead of %q", n.Type)
+	}
+	if err := checkProps(n, "window", "window"); err != nil {
+		return err
+	}
+
+	// the window is shown only after all its controls are created
+	wnd := a.comp.newWindow(n.X, n.Y, sizeOrAuto(n.Width), sizeOrAuto(n.Height), n.Title)
+	if n.Modal {
+		wnd.SetModal(true)
+	}
+	if n.Buttons != nil {
+		btns := ButtonDefault
+		for _, b := range n.Buttons {
+			switch strings.ToLower(b) {
+			case "close":
+				btns |= ButtonClose
+			case "bottom":
+				btns |= ButtonBottom
+			case "maximize":
+				btns |= ButtonMaximize
+			default:
+				return fmt.Errorf("window: unknown title button %q", b)
+			}
+		}
+		wnd.SetTitleButtons(btns)
+	}
+	if n.Scale != 0 {
+		wnd.SetScale(n.Scale)
+	}
+
+	var active Control
+	if err := u.setup(wnd, n, "window", "window"); err != nil {
+		return err
+	}
+	if err := u.buildChildren(wnd, n, "window", &active); err != nil {
+		return err
+	}
+
+	if n.Center {
+		sw, sh := a.ScreenSize()
+		w, h := wnd.Size()
+		wnd.SetPos((sw-w)/2, (sh-h)/2)
+	}
+	u.Window = wnd
+	a.comp.addWindow(wnd)
+	if active != nil {
+		ActivateControl(wnd, active)
+	}
+	return nil
+}
+
+func (u *UI) buildChildren(parent Control, n *uiNode, path string, active *Control) error {
+	for i, child := range n.Children {
+		childPath := fmt.Sprintf("%v.children[%v]", path, i)
+		if child == nil {
+			return fmt.Errorf("%v: empty control", childPath)
+		}
+		if err := u.build(parent, child, childPath, active); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// build creates the control and all its children
+func (u *UI) build(parent Control, n *uiNode, path string, active *Control) error {
+	w, h := sizeOrAuto(n.Width), sizeOrAuto(n.Height)
+	typ := strings.ToLower(n.Type)
+
+	tabs, isTabView := parent.(*TabView)
+	if isTabView != (typ == "page") {
+		return fmt.Errorf("%v: tabview can contain only pages and a page must be inside tabview", path)
+	}
+	if len(n.Children) != 0 && typ != "frame" && typ != "page" && typ != "tabview" {
+		return fmt.Errorf("%v: %v cannot have children", path, typ)
+	}
+	if _, ok := uiTypeProps[typ]; ok {
+		if err := checkProps(n, typ, path); err != nil {
+			return err
+		}
+	}
+
+	var ctrl Control
+	switch typ {
+	case "frame":
+		border, err := parseBorder(n.Border)
+		if err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+		frame := CreateFrame(parent, w, h, border, n.Scale)
+		frame.SetTitle(n.Title)
+		ctrl = frame
+	case "spacer":
+		if w == AutoSize {
+			w = 1
+		}
+		if h == AutoSize {
+			h = 1
+		}
+		ctrl = CreateFrame(parent, w, h, BorderNone, n.Scale)
+	case "page":
+		page := tabs.AddPage(n.Title)
+		page.SetScale(n.Scale)
+		ctrl = page
+	case "label":
+		lbl := CreateLabel(parent, w, h, n.Title, n.Scale)
+		lbl.SetMultiline(n.Multiline)
+		ctrl = lbl
+	case "button":
+		ctrl = CreateButton(parent, w, h, n.Title, n.Scale)
+	case "editfield":
+		edit := CreateEditField(parent, w, n.Text, n.Scale)
+		edit.SetPasswordMode(n.Password)
+		ctrl = edit
+	case "checkbox":
+		chk := CreateCheckBox(parent, w, n.Title, n.Scale)
+		if n.Checked {
+			chk.SetState(1)
+		}
+		ctrl = chk
+	case "radio":
+		radio := CreateRadio(parent, w, n.Title, n.Scale)
+		if n.Group != "" {
+			group, ok := u.groups[n.Group]
+			if !ok {
+				group = CreateRadioGroup()
+				u.groups[n.Group] = group
+			}
+			group.AddItem(radio)
+			if n.Checked {
+				group.SelectItem(radio)
+			}
+		} else if n.Checked {
+			radio.SetSelected(true)
+		}
+		ctrl = radio
+	case "listbox":
+		list := CreateListBox(parent, w, h, n.Scale)
+		for _, item := range n.Items {
+			list.AddItem(item)
+		}
+		if n.Selected != nil {
+			list.SelectItem(*n.Selected)
+		}
+		ctrl = list
+	case "combobox":
+		combo := CreateComboBox(parent, w, n.Text, n.Scale)
+		for _, item := range n.Items {
+			combo.AddItem(item)
+		}
+		if n.Selected != nil && *n.Selected >= 0 && *n.Selected < len(n.Items) {
+			combo.SetTitle(n.Items[*n.Selected])
+		}
+		ctrl = combo
+	case "tableview":
+		table := CreateTableView(parent, w, h, n.Scale)
+		cols := make([]Column, 0, len(n.Columns))
+		for _, col := range n.Columns {
+			align, err := parseAlign(col.Align)
+			if err != nil {
+				return fmt.Errorf("%v: %v", path, err)
+			}
+			width := col.Width
+			if width == 0 {
+				width = TextWidth(col.Title) + 2
+			}
+			cols = append(cols, Column{Title: col.Title, Width: width, Alignment: align})
+		}
+		table.SetColumns(cols)
+		table.SetRowCount(n.Rows)
+		ctrl = table
+	case "textview":
+		tv := CreateTextView(parent, w, h, n.Scale)
+		if n.Text != "" {
+			tv.SetText(strings.Split(n.Text, "\n"))
+		}
+		ctrl = tv
+	case "textedit":
+		te := CreateTextEdit(parent, w, h, n.Scale)
+		te.SetText(n.Text)
+		ctrl = te
+	case "progressbar":
+		bar := CreateProgressBar(parent, w, h, n.Scale)
+		if n.Min != 0 || n.Max != 0 {
+			bar.SetLimits(n.Min, n.Max)
+		}
+		bar.SetValue(n.Value)
+		bar.SetTitle(n.Title)
+		ctrl = bar
+	case "tabview":
+		ctrl = CreateTabView(parent, w, h, n.Scale)
+	case "window":
+		return fmt.Errorf("%v: a window can be only the root control", path)
+	default:
+		return fmt.Errorf("%v: unknown control type %q", path, n.Type)
+	}
+
+	if err := u.setup(ctrl, n, typ, path); err != nil {
+		return err
+	}
+	if n.Active {
+		*active = ctrl
+	}
+	return u.buildChildren(ctrl, n, path, active)
+}
+
+// setup applies properties that all controls have
+func (u *UI) setup(ctrl Control, n *uiNode, typ, path string) error {
+	if n.Name != "" {
+		if _, ok := u.controls[n.Name]; ok {
+			return fmt.Errorf("%v: duplicated name %q", path, n.Name)
+		}
+		u.controls[n.Name] = ctrl
+	}
+
+	switch strings.ToLower(n.Pack) {
+	case "":
+	case "vertical":
+		ctrl.SetPack(Vertical)
+	case "horizontal":
+		ctrl.SetPack(Horizontal)
+	default:
+		return fmt.Errorf("%v: unknown pack %q", path, n.Pack)
+	}
+	if n.Padding != nil {
+		ctrl.SetPaddings(n.Padding[0], n.Padding[1])
+	}
+	if n.Gap != nil {
+		ctrl.SetGaps(n.Gap[0], n.Gap[1])
+	}
+
+	if n.Align != "" {
+		align, err := parseAlign(n.Align)
+		if err != nil {
+			return fmt.Errorf("%v: %v", path, err)
+		}
+		if lbl, ok := ctrl.(*Label); ok {
+			lbl.SetTextDisplay(align)
+		} else {
+			ctrl.SetAlign(align)
+		}
+	}
+
+	if n.Style != "" {
+		ctrl.SetStyle(n.Style)
+	}
+	if n.TextColor != "" {
+		ctrl.SetTextColor(StringToColor(n.TextColor))
+	}
+	if n.BackColor != "" {
+		ctrl.SetBackColor(StringToColor(n.BackColor))
+	}
+	if n.Hint != "" {
+		ctrl.SetHint(n.Hint)
+	}
+	if n.Tooltip != "" {
+		tip, ok := ctrl.(interface{ SetTooltip(string) })
+		if !ok {
+			return fmt.Errorf("%v.tooltip: property not supported by %v", path, typ)
+		}
+		tip.SetTooltip(n.Tooltip)
+	}
+	if n.Enabled != nil {
+		ctrl.SetEnabled(*n.Enabled)
+	}
+	if n.Visible != nil {
+		ctrl.SetVisible(*n.Visible)
+	}
+	if n.TabStop != nil {
+		ctrl.SetTabStop(*n.TabStop)
+	}
+	return nil
+}
+
+// sizeOrAuto converts a size from a UI document: zero means AutoSize
+func sizeOrAuto(size int) int {
+	if size == 0 {
+		return AutoSize
+	}
+	return size
+}
+
+func parseBorder(s string) (BorderStyle, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return BorderNone, nil
+	case "thin":
+		return BorderThin, nil
+	case "thick":
+		return BorderThick, nil
+	case "auto":
+		return BorderAuto, nil
+	}
+	return BorderNone, fmt.Errorf("unknown border %q", s)
+}
+
+func parseAlign(s string) (Align, error) {
+	switch strings.ToLower(s) {
+	case "", "left":
+		return AlignLeft, nil
+	case "right":
+		return AlignRight, nil
+	case "center":
+		return AlignCenter, nil
+	}
+	return AlignLeft, fmt.Errorf("unknown align %q", s)
+}
@@ -0,0 +1,282 @@
+package clui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestParseYAML(t *testing.T) {
+	doc := `
+---
+# a comment
+title: "Log in" # a trailing comment
+width: 30
+center: true
+hint: ~
+gap: [1, 0]
+items:
+- one
+- 'it''s'
+- {a: 1, b: [x, "y"]}
+children:
+  - type: label
+    title: a:b #c
+  -
+    type: button
+`
+	want := map[string]interface{}{
+		"title":  "Log in",
+		"width":  30.0,
+		"center": true,
+		"hint":   nil,
+		"gap":    []interface{}{1.0, 0.0},
+		"items": []interface{}{"one", "it's",
+			map[string]interface{}{"a": 1.0, "b": []interface{}{"x", "y"}}},
+		"children": []interface{}{
+			map[string]interface{}{"type": "label", "title": "a:b"},
+			map[string]interface{}{"type": "button"},
+		},
+	}
+	got, err := parseYAML([]byte(doc))
+	if err != nil {
+		t.Fatalf("Failed to parse YAML: %v", err)
+	}
+	if got = resolveYAML(got, nil); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseYAML returned %#v, want %#v", got, want)
+	}
+
+	for _, bad := range []string{"a: 1\n  b: 2", "a: |\n  text", "a: [1, 2", "a: 1\na: 2", "just text\nb: 1", "a: b: c", "- a: b: c"} {
+		if _, err := parseYAML([]byte(bad)); err == nil {
+			t.Errorf("parseYAML(%q) must fail", bad)
+		}
+	}
+}
+
+const loginJSON = `{
+	"type": "window", "title": "Login", "pack": "vertical", "x": 2, "y": 1,
+	"buttons": ["close"],
+	"children": [
+		{"type": "label", "title": "User:"},
+		{"type": "editfield", "name": "user", "width": 16, "active": true},
+		{"type": "radio", "name": "local", "title": "Local", "group": "auth", "checked": true},
+		{"type": "radio", "name": "ldap", "title": "LDAP", "group": "auth"},
+		{"type": "frame", "pack": "horizontal", "gap": 1, "padding": [0, 0], "children": [
+			{"type": "spacer", "scale": 1},
+			{"type": "button", "name": "ok", "title": "OK"}
+		]}
+	]
+}`
+
+const loginYAML = `
+type: window
+title: Login
+pack: vertical
+x: 2
+y: 1
+buttons: [close]
+children:
+  - type: label
+    title: "User:"
+  - {type: editfield, name: user, width: 16, active: true}
+  - {type: radio, name: local, title: Local, group: auth, checked: true}
+  - {type: radio, name: ldap, title: LDAP, group: auth}
+  - type: frame
+    pack: horizontal
+    gap: 1
+    padding: [0, 0]
+    children:
+      - {type: spacer, scale: 1}
+      - type: button
+        name: ok
+        title: OK
+`
+
+func TestLoadUI(t *testing.T) {
+	var screens []string
+	for _, doc := range []string{loginJSON, loginYAML} {
+		b := initHeadless(t, 40, 14)
+
+		ui, err := LoadUI([]byte(doc))
+		if err != nil {
+			DeinitLibrary()
+			t.Fatalf("Failed to load UI: %v", err)
+		}
+		if ui.Window.Title() != "Login" || ui.Window.Pack() != Vertical {
+			t.Errorf("Window properties are not applied")
+		}
+		user, ok := ui.Control("user").(*EditField)
+		if !ok || !user.Active() {
+			t.Fatalf("EditField 'user' must exist and be active: %v", ui.Control("user"))
+		}
+		if ui.Control("missing") != nil || ui.OnClick("user", func(Event) {}) {
+			t.Errorf("Only existing buttons can be bound")
+		}
+		if group := ui.RadioGroup("auth"); group == nil || group.Selected() != 0 {
+			t.Errorf("Radio group must select the checked radio")
+		}
+
+		clicked := false
+		if !ui.OnClick("ok", func(Event) { clicked = true }) {
+			t.Errorf("Button 'ok' must be bound")
+		}
+		var text string
+		ui.OnChange("user", func(ev Event) { text = ev.Msg })
+		RefreshScreen()
+
+		SimulateKey(0, 'a')
+		SimulateKey(term.KeyTab, 0)
+		SimulateKey(term.KeyTab, 0)
+		SimulateKey(term.KeyTab, 0)
+		if !ui.Control("ok").Active() {
+			t.Fatalf("Tab must move focus to the button")
+		}
+		SimulateKey(term.KeySpace, 0)
+		if user.Title() != "a" || text != "a" || !clicked {
+			t.Errorf("Callbacks must be called: %q %q %v", user.Title(), text, clicked)
+		}
+
+		btn := ui.Control("ok")
+		wx, _ := ui.Window.Pos()
+		ww, _ := ui.Window.Size()
+		bx, _ := btn.Pos()
+		bw, _ := btn.Size()
+		if bx+bw != wx+ww-1 {
+			t.Errorf("Spacer must push the button to the right border: %v+%v, %v+%v", bx, bw, wx, ww)
+		}
+
+		screens = append(screens, b.Snapshot())
+		DeinitLibrary()
+	}
+
+	if screens[0] != screens[1] {
+		t.Errorf("JSON and YAML documents must build the same UI:\n%v\n%v", screens[0], screens[1])
+	}
+}
+
+func TestLoadUIErrors(t *testing.T) {
+	initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	cases := []struct {
+		doc string
+		err string
+	}{
+		{`{"type": "frame"}`, "root control must be a window"},
+		{`{"type": "window", "children": [{"type": "slider"}]}`, `children[0]: unknown control type "slider"`},
+		{`{"type": "window", "colour": "red"}`, "colour"},
+		{"type: window\nchildren:\n  - {type: button, name: a}\n  - {type: label, name: a}", `children[1]: duplicated name "a"`},
+		{"type: window\nchildren:\n  - type: label\n    children: [{type: button}]", "label cannot have children"},
+		{"type: window\nchildren:\n  - {type: page}", "page must be inside tabview"},
+		{"type: window\ngap: [1]", "list of two numbers"},
+		{"type: window\npack: diagonal", `unknown pack "diagonal"`},
+		{"type: window\nchildren:\n  - {type: label, password: true}", "children[0].password: property not supported by label"},
+		{"type: window\nchildren:\n  - {type: label, items: [a]}", "children[0].items: property not supported by label"},
+		{"type: window\nchildren:\n  - {type: button, Checked: false}", "children[0].Checked: property not supported by button"},
+		{"type: window\nborder: thin", "window.border: property not supported by window"},
+	}
+	for _, c := range cases {
+		_, err := LoadUI([]byte(c.doc))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("LoadUI(%q) must fail with %q: %v", c.doc, c.err, err)
+		}
+	}
+	if WindowManager().topWindow() != nil {
+		t.Errorf("Failed documents must not leave windows")
+	}
+}
+
+func TestLoadUITabView(t *testing.T) {
+	initHeadless(t, 50, 14)
+	defer DeinitLibrary()
+
+	ui, err := LoadUI([]byte(`
+type: window
+title: Settings
+center: true
+children:
+  - type: tabview
+    name: tabs
+    width: 30
+    height: 8
+    children:
+      - type: page
+        title: General
+        pack: vertical
+        children:
+          - {type: checkbox, name: autosave, title: Autosave, checked: true}
+          - {type: combobox, name: theme, items: [dark, light], selected: 1}
+      - type: page
+        title: Files
+        children:
+          - type: tableview
+            name: files
+            rows: 3
+            columns:
+              - {title: Name, width: 10}
+              - {title: Size, align: right}
+`))
+	if err != nil {
+		t.Fatalf("Failed to load UI: %v", err)
+	}
+	x, y := ui.Window.Pos()
+	w, h := ui.Window.Size()
+	if w < 32 || x != (50-w)/2 || y != (14-h)/2 {
+		t.Errorf("Window must fit the controls and be centered: %v:%v %vx%v", x, y, w, h)
+	}
+	if tabs, ok := ui.Control("tabs").(*TabView); !ok || tabs.PageCount() != 2 {
+		t.Fatalf("TabView must have two pages")
+	}
+	if chk := ui.Control("autosave").(*CheckBox); chk.State() != 1 {
+		t.Errorf("CheckBox must be checked")
+	}
+	if combo := ui.Control("theme").(*ComboBox); combo.Title() != "light" {
+		t.Errorf("ComboBox must show the selected item: %q", combo.Title())
+	}
+	table := ui.Control("files").(*TableView)
+	if cols := table.Columns(); len(cols) != 2 || cols[1].Width != 6 || cols[1].Alignment != AlignRight {
+		t.Errorf("TableView columns are not applied: %v", cols)
+	}
+	if table.RowCount() != 3 {
+		t.Errorf("TableView must have 3 rows: %v", table.RowCount())
+	}
+	if !ui.OnSelectItem("theme", func(Event) {}) || ui.OnSelectItem("files", func(Event) {}) {
+		t.Errorf("Only ListBox and ComboBox can be bound to OnSelectItem")
+	}
+}
+
+func TestLoadUIYAMLScalars(t *testing.T) {
+	initHeadless(t, 40, 12)
+	defer DeinitLibrary()
+
+	ui, err := LoadUI([]byte(`
+type: window
+title: 2024
+children:
+  - {type: listbox, name: sizes, items: [8, 10.50, true], height: 4}
+  - {type: label, name: flag, title: false}
+`))
+	if err != nil {
+		t.Fatalf("Numbers and booleans must be decoded to text properties: %v", err)
+	}
+	if ui.Window.Title() != "2024" {
+		t.Errorf("Invalid window title: %q", ui.Window.Title())
+	}
+	list := ui.Control("sizes").(*ListBox)
+	if _, h := list.Size(); h != 4 {
+		t.Errorf("Numbers must still be decoded to numeric properties: %v", h)
+	}
+	var items []string
+	for i := 0; i < list.ItemCount(); i++ {
+		item, _ := list.Item(i)
+		items = append(items, item)
+	}
+	if !reflect.DeepEqual(items, []string{"8", "10.50", "true"}) {
+		t.Errorf("Items must keep the scalar text: %q", items)
+	}
+	if title := ui.Control("flag").(*Label).Title(); title != "false" {
+		t.Errorf("Invalid label title: %q", title)
+	}
+}
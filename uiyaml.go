@@ -0,0 +1,456 @@
+package clui
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a non-empty line of YAML document without a comment
+type yamlLine struct {
+	no     int
+	indent int
+	text   string
+}
+
+// yamlParser parses the subset of YAML that is enough to describe a user
+// interface(see LoadUI): block mappings and sequences, flow sequences and
+// mappings, plain and quoted scalars, and comments. Anchors, tags, block
+// scalars(| and >) and multiple documents are not supported
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// yamlPlain is a plain scalar that is a number or a boolean. It keeps the
+// scalar text, so the value can be decoded to a string too(see
+// resolveYAML)
+type yamlPlain struct {
+	text  string
+	value interface{}
+}
+
+func (s yamlPlain) String() string {
+	return s.text
+}
+
+// parseYAML converts YAML document to map[string]interface{},
+// []interface{}, string, yamlPlain and nil values. Use resolveYAML to get
+// the values encoding/json uses
+func parseYAML(data []byte) (interface{}, error) {
+	p := new(yamlParser)
+	for i, text := range strings.Split(string(data), "\n") {
+		text = strings.TrimRight(stripYAMLComment(text), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || (len(p.lines) == 0 && trimmed == "---") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %v: tabs are not allowed for indentation", i+1)
+		}
+		p.lines = append(p.lines, yamlLine{no: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+
+	if len(p.lines) == 0 {
+		return nil, nil
+	}
+	val, err := p.parseBlock(p.lines[0].indent)
+	if err == nil && p.pos < len(p.lines) {
+		err = p.errorf("unexpected indentation")
+	}
+	return val, err
+}
+
+func (p *yamlParser) errorf(format string, args ...interface{}) error {
+	no := 0
+	if p.pos < len(p.lines) {
+		no = p.lines[p.pos].no
+	} else if len(p.lines) > 0 {
+		no = p.lines[len(p.lines)-1].no
+	}
+	return fmt.Errorf("line %v: %v", no, fmt.Sprintf(format, args...))
+}
+
+// parseBlock parses a mapping or a sequence which lines start at indent
+func (p *yamlParser) parseBlock(indent int) (interface{}, error) {
+	if isYAMLSeqItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+func isYAMLSeqItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+	list := make([]interface{}, 0)
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent != indent || !isYAMLSeqItem(line.text) {
+			break
+		}
+
+		rest := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		if rest == "" {
+			p.pos++
+			val, err := p.parseNested(indent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, val)
+			continue
+		}
+
+		if _, _, ok := splitYAMLKey(rest); ok {
+			// a mapping starts at the same line: its keys are aligned with
+			// the first one
+			p.lines[p.pos] = yamlLine{no: line.no, indent: indent + len(line.text) - len(rest), text: rest}
+			val, err := p.parseMapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, val)
+			continue
+		}
+
+		val, err := parseYAMLValue(rest)
+		if err != nil {
+			return nil, p.errorf("%v", err)
+		}
+		list = append(list, val)
+		p.pos++
+	}
+	return list, nil
+}
+
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+	m := make(map[string]interface{})
+	for p.pos < len(p.lines) {
+		line := p.lines[p.pos]
+		if line.indent < indent {
+			break
+		}
+		if line.indent > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if isYAMLSeqItem(line.text) {
+			break
+		}
+
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, p.errorf("expected 'key: value'")
+		}
+		if _, ok := m[key]; ok {
+			return nil, p.errorf("duplicated key %q", key)
+		}
+		p.pos++
+
+		if rest != "" {
+			val, err := parseYAMLValue(rest)
+			if err != nil {
+				p.pos--
+				return nil, p.errorf("%v", err)
+			}
+			m[key] = val
+			continue
+		}
+
+		// a sequence can be a value of the key at the same indentation
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSeqItem(p.lines[p.pos].text) {
+			val, err := p.parseSequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = val
+			continue
+		}
+		val, err := p.parseNested(indent)
+		if err != nil {
+			return nil, err
+		}
+		m[key] = val
+	}
+	return m, nil
+}
+
+// parseNested parses the block that is indented more than its parent. If
+// there is no such block the value is null
+func (p *yamlParser) parseNested(parentIndent int) (interface{}, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= parentIndent {
+		return nil, nil
+	}
+	return p.parseBlock(p.lines[p.pos].indent)
+}
+
+// splitYAMLKey splits "key: value" line. Returns false if the line is not
+// a mapping entry
+func splitYAMLKey(text string) (key, rest string, ok bool) {
+	if text == "" || strings.ContainsRune("[{", rune(text[0])) {
+		return "", "", false
+	}
+
+	end := 0
+	if text[0] == '"' || text[0] == '\'' {
+		end = quotedEnd(text)
+		if end < 0 {
+			return "", "", false
+		}
+	}
+	idx := strings.Index(text[end:], ":")
+	for idx >= 0 {
+		idx += end
+		if idx == len(text)-1 || text[idx+1] == ' ' {
+			break
+		}
+		end = idx + 1
+		idx = strings.Index(text[end:], ":")
+	}
+	if idx < 0 {
+		return "", "", false
+	}
+
+	keyVal, err := parseYAMLScalar(strings.TrimSpace(text[:idx]))
+	if err != nil {
+		return "", "", false
+	}
+	return fmt.Sprint(keyVal), strings.TrimSpace(text[idx+1:]), true
+}
+
+// quotedEnd returns the index after the closing quote of the string that
+// starts with a quote, or -1 if the string is not closed
+func quotedEnd(text string) int {
+	q := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case q == '"' && text[i] == '\\':
+			i++
+		case text[i] == q && q == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == q:
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// stripYAMLComment removes the comment: # at the line start or after
+// a space outside of quotes
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' ' || text[i-1] == '\t'):
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// parseYAMLValue parses a flow collection or a scalar
+func parseYAMLValue(text string) (interface{}, error) {
+	switch text[0] {
+	case '[', '{':
+		val, rest, err := parseYAMLFlow(text)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(rest) != "" {
+			return nil, fmt.Errorf("unexpected %q after %q", rest, text[:len(text)-len(rest)])
+		}
+		return val, nil
+	case '|', '>', '&', '*', '!':
+		return nil, fmt.Errorf("unsupported YAML syntax %q", text)
+	case '"', '\'':
+	default:
+		// "key: value: other" is not a valid mapping, the value must be quoted
+		if strings.Contains(text, ": ") {
+			return nil, fmt.Errorf("unexpected ':' in %q, quote the value", text)
+		}
+	}
+	return parseYAMLScalar(text)
+}
+
+// parseYAMLFlow parses the first flow collection or scalar in the text and
+// returns the rest of the text
+func parseYAMLFlow(text string) (interface{}, string, error) {
+	text = strings.TrimLeft(text, " ")
+	if text == "" {
+		return nil, "", fmt.Errorf("unexpected end of flow collection")
+	}
+
+	switch text[0] {
+	case '[':
+		list := make([]interface{}, 0)
+		rest := strings.TrimLeft(text[1:], " ")
+		for !strings.HasPrefix(rest, "]") {
+			val, tail, err := parseYAMLFlow(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			list = append(list, val)
+			if rest, err = flowSeparator(tail, ']'); err != nil {
+				return nil, "", err
+			}
+		}
+		return list, rest[1:], nil
+	case '{':
+		m := make(map[string]interface{})
+		rest := strings.TrimLeft(text[1:], " ")
+		for !strings.HasPrefix(rest, "}") {
+			key, tail, err := parseYAMLFlow(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			tail = strings.TrimLeft(tail, " ")
+			if !strings.HasPrefix(tail, ":") {
+				return nil, "", fmt.Errorf("expected ':' in %q", text)
+			}
+			val, tail, err := parseYAMLFlow(tail[1:])
+			if err != nil {
+				return nil, "", err
+			}
+			m[fmt.Sprint(key)] = val
+			if rest, err = flowSeparator(tail, '}'); err != nil {
+				return nil, "", err
+			}
+		}
+		return m, rest[1:], nil
+	case '"', '\'':
+		end := quotedEnd(text)
+		if end < 0 {
+			return nil, "", fmt.Errorf("unclosed quote in %q", text)
+		}
+		val, err := parseYAMLScalar(text[:end])
+		return val, text[end:], err
+	}
+
+	end := strings.IndexAny(text, ",]}:")
+	for end >= 0 && text[end] == ':' && end+1 < len(text) && text[end+1] != ' ' {
+		next := strings.IndexAny(text[end+1:], ",]}:")
+		if next < 0 {
+			end = -1
+			break
+		}
+		end += next + 1
+	}
+	if end < 0 {
+		end = len(text)
+	}
+	val, err := parseYAMLScalar(strings.TrimSpace(text[:end]))
+	return val, text[end:], err
+}
+
+// flowSeparator skips a comma between items of a flow collection. The
+// returned text starts with the next item or with the closing bracket
+func flowSeparator(text string, closing byte) (string, error) {
+	text = strings.TrimLeft(text, " ")
+	switch {
+	case strings.HasPrefix(text, ","):
+		return strings.TrimLeft(text[1:], " "), nil
+	case text != "" && text[0] == closing:
+		return text, nil
+	}
+	return "", fmt.Errorf("expected ',' or '%c' before %q", closing, text)
+}
+
+// parseYAMLScalar converts a plain or quoted scalar. Plain scalars can be
+// null, booleans, and numbers: the last two are returned as yamlPlain
+func parseYAMLScalar(text string) (interface{}, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	switch text[0] {
+	case '"':
+		if quotedEnd(text) != len(text) {
+			return nil, fmt.Errorf("invalid quoted string %s", text)
+		}
+		s, err := strconv.Unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted string %s", text)
+		}
+		return s, nil
+	case '\'':
+		if quotedEnd(text) != len(text) {
+			return nil, fmt.Errorf("invalid quoted string %s", text)
+		}
+		return strings.Replace(text[1:len(text)-1], "''", "'", -1), nil
+	}
+
+	switch text {
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return yamlPlain{text: text, value: true}, nil
+	case "false", "False", "FALSE":
+		return yamlPlain{text: text, value: false}, nil
+	}
+	if f, err := strconv.ParseFloat(text, 64); err == nil && !strings.ContainsAny(text, "xXnN_") {
+		return yamlPlain{text: text, value: f}, nil
+	}
+	return text, nil
+}
+
+// resolveYAML converts the values returned by parseYAML to the values
+// encoding/json uses: numbers and booleans become float64 and bool, or
+// their text if the field they are decoded to is a string, e.g a number
+// in a list of items. t is the type of the value is decoded to, nil if
+// unknown
+func resolveYAML(v interface{}, t reflect.Type) interface{} {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch v := v.(type) {
+	case yamlPlain:
+		if t != nil && t.Kind() == reflect.String {
+			return v.text
+		}
+		return v.value
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = resolveYAML(item, yamlFieldType(t, key))
+		}
+	case []interface{}:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for i, item := range v {
+			v[i] = resolveYAML(item, elem)
+		}
+	}
+	return v
+}
+
+// yamlFieldType returns the type of the struct field that encoding/json
+// decodes the key to, nil if there is no such field
+func yamlFieldType(t reflect.Type, key string) reflect.Type {
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" {
+			name = f.Name
+		}
+		if strings.EqualFold(name, key) {
+			return f.Type
+		}
+	}
+	return nil
+}
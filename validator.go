@@ -0,0 +1,237 @@
+package clui
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// errIncomplete is returned by EditField.Validate if not all required
+// positions of the input mask are filled
+var errIncomplete = errors.New("the value is incomplete")
+
+// validatable is a control that can check its content, e.g EditField
+type validatable interface {
+	Validate() error
+}
+
+// NumericValidator returns a validator that accepts a non-empty text that
+// contains only digits
+func NumericValidator() func(string) error {
+	return func(text string) error {
+		if text == "" {
+			return errors.New("the value is required")
+		}
+		for _, r := range text {
+			if !unicode.IsDigit(r) {
+				return errors.New("only digits are allowed")
+			}
+		}
+		return nil
+	}
+}
+
+// IntegerValidator returns a validator that accepts an integer number
+// between min and max inclusive. Leading and trailing spaces are ignored
+func IntegerValidator(min, max int64) func(string) error {
+	return func(text string) error {
+		n, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64)
+		if err != nil {
+			return errors.New("the value is not an integer number")
+		}
+		if n < min || n > max {
+			return fmt.Errorf("the value must be between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+// FloatValidator returns a validator that accepts a number between min
+// and max inclusive. Leading and trailing spaces are ignored
+func FloatValidator(min, max float64) func(string) error {
+	return func(text string) error {
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return errors.New("the value is not a number")
+		}
+		if f < min || f > max {
+			return fmt.Errorf("the value must be between %v and %v", min, max)
+		}
+		return nil
+	}
+}
+
+// RegexpValidator returns a validator that accepts a text that matches
+// the regular expression. Use ^ and $ to match the whole text. message is
+// the error text for a mismatched value
+func RegexpValidator(re *regexp.Regexp, message string) func(string) error {
+	if message == "" {
+		message = "the value is invalid"
+	}
+	return func(text string) error {
+		if !re.MatchString(text) {
+			return errors.New(message)
+		}
+		return nil
+	}
+}
+
+// IPv4Validator returns a validator that accepts an IPv4 address. Spaces
+// inside numbers are ignored, so the validator works with the mask
+// MaskIPv4
+func IPv4Validator() func(string) error {
+	return func(text string) error {
+		parts := strings.Split(text, ".")
+		if len(parts) != 4 {
+			return errors.New("the value is not an IP address")
+		}
+		for _, part := range parts {
+			n, err := strconv.Atoi(strings.Replace(part, " ", "", -1))
+			if err != nil || n < 0 || n > 255 {
+				return errors.New("the value is not an IP address")
+			}
+		}
+		return nil
+	}
+}
+
+// OptionalValidator returns a validator that accepts an empty text(or
+// a text of spaces) and checks any other text with fn
+func OptionalValidator(fn func(string) error) func(string) error {
+	return func(text string) error {
+		if strings.TrimSpace(text) == "" {
+			return nil
+		}
+		return fn(text)
+	}
+}
+
+// Predefined input masks
+const (
+	// MaskDate is a date in format DD/MM/YYYY or MM/DD/YYYY
+	MaskDate = "99/99/9999"
+	// MaskTime is a time in format HH:MM
+	MaskTime = "99:99"
+	// MaskIPv4 is an IPv4 address, a number can be shorter than 3 digits
+	MaskIPv4 = "###.###.###.###"
+)
+
+// maskPos is a position of an EditField input mask. It is either a literal
+// character or a placeholder for a character of a certain class
+type maskPos struct {
+	ch      rune
+	literal bool
+}
+
+// the character displayed in empty positions of an input mask
+const maskBlankChar = '_'
+
+// parseMask converts the mask description to the list of positions. See
+// EditField.SetMask for mask characters
+func parseMask(mask string) []maskPos {
+	var res []maskPos
+	escaped := false
+	for _, r := range mask {
+		switch {
+		case escaped:
+			res = append(res, maskPos{ch: r, literal: true})
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '9' || r == '#' || r == 'A' || r == 'X':
+			res = append(res, maskPos{ch: r})
+		default:
+			res = append(res, maskPos{ch: r, literal: true})
+		}
+	}
+	return res
+}
+
+// accepts returns true if the character can be put to the mask position
+func (m maskPos) accepts(r rune) bool {
+	switch m.ch {
+	case '9', '#':
+		return r >= '0' && r <= '9'
+	case 'A':
+		return unicode.IsLetter(r)
+	default:
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+}
+
+// required returns true if the position cannot be left blank
+func (m maskPos) required() bool {
+	return !m.literal && m.ch != '#'
+}
+
+// emptyMaskText returns a text that contains only literals of the mask
+// and spaces in all other positions
+func emptyMaskText(mask []maskPos) []rune {
+	text := make([]rune, len(mask))
+	for i, m := range mask {
+		if m.literal {
+			text[i] = m.ch
+		} else {
+			text[i] = ' '
+		}
+	}
+	return text
+}
+
+// maskType puts the typed character r to the first position of the mask
+// at or after pos that accepts it and returns the position after the
+// character. If r is a literal of the mask, the position after the
+// literal is returned, so a user can skip optional positions, e.g type
+// "10.1." for an IP address. ok is false if r cannot be typed
+func maskType(mask []maskPos, text []rune, pos int, r rune) (next int, ok bool) {
+	p := pos
+	for p < len(mask) && mask[p].literal {
+		if mask[p].ch == r {
+			return p + 1, true
+		}
+		p++
+	}
+	if p == len(mask) {
+		return pos, false
+	}
+	if mask[p].accepts(r) {
+		text[p] = r
+		return p + 1, true
+	}
+
+	for i := p; i < len(mask); i++ {
+		if mask[i].literal && mask[i].ch == r {
+			return i + 1, true
+		}
+	}
+	return pos, false
+}
+
+// applyMask converts text to a text that fits the mask. If the text
+// already fits, it is kept as is. Otherwise its characters are typed one
+// by one, and the characters that the mask does not accept are dropped
+func applyMask(mask []maskPos, text string) []rune {
+	runes := []rune(text)
+	if len(runes) == len(mask) {
+		fits := true
+		for i, m := range mask {
+			if (m.literal && runes[i] != m.ch) || (!m.literal && runes[i] != ' ' && !m.accepts(runes[i])) {
+				fits = false
+				break
+			}
+		}
+		if fits {
+			return runes
+		}
+	}
+
+	res := emptyMaskText(mask)
+	pos := 0
+	for _, r := range runes {
+		pos, _ = maskType(mask, res, pos, r)
+	}
+	return res
+}
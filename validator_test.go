@@ -0,0 +1,128 @@
+package clui
+
+import (
+	"regexp"
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func TestValidators(t *testing.T) {
+	cases := []struct {
+		name  string
+		fn    func(string) error
+		text  string
+		valid bool
+	}{
+		{"numeric", NumericValidator(), "0123", true},
+		{"numeric", NumericValidator(), "12a", false},
+		{"numeric", NumericValidator(), "", false},
+		{"integer", IntegerValidator(-5, 100), " -5 ", true},
+		{"integer", IntegerValidator(-5, 100), "101", false},
+		{"integer", IntegerValidator(-5, 100), "1.5", false},
+		{"float", FloatValidator(0, 1), "0.25", true},
+		{"float", FloatValidator(0, 1), "1.25", false},
+		{"float", FloatValidator(0, 1), "abc", false},
+		{"regexp", RegexpValidator(regexp.MustCompile(`^[a-z]+@[a-z]+$`), ""), "me@host", true},
+		{"regexp", RegexpValidator(regexp.MustCompile(`^[a-z]+@[a-z]+$`), ""), "me@", false},
+		{"ipv4", IPv4Validator(), "192.168.  1.  1", true},
+		{"ipv4", IPv4Validator(), "192.168.1.256", false},
+		{"ipv4", IPv4Validator(), "192.168.1", false},
+		{"optional", OptionalValidator(IntegerValidator(0, 9)), "  ", true},
+		{"optional", OptionalValidator(IntegerValidator(0, 9)), "10", false},
+	}
+
+	for _, c := range cases {
+		if err := c.fn(c.text); (err == nil) != c.valid {
+			t.Errorf("%s validator for %q returned %v", c.name, c.text, err)
+		}
+	}
+}
+
+func TestEditFieldMask(t *testing.T) {
+	initHeadless(t, 40, 10)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 30, 6, "Mask")
+	wnd.SetPack(Vertical)
+	ip := CreateEditField(wnd, 20, "", Fixed)
+	ip.SetMask(MaskIPv4)
+	ip.SetValidator(IPv4Validator())
+	date := CreateEditField(wnd, 20, "1a2/0345/2020", Fixed)
+	date.SetMask(MaskDate)
+	ActivateControl(wnd, ip)
+
+	if date.Title() != "12/03/4520" {
+		t.Errorf("Text must be converted to fit the mask: %q", date.Title())
+	}
+	if ip.Title() != "   .   .   .   " || ip.cursorPos != 0 {
+		t.Errorf("Empty mask text is invalid: %q at %v", ip.Title(), ip.cursorPos)
+	}
+
+	// a typed literal skips optional positions, other characters are ignored
+	typeText("10.x1.2.30")
+	if ip.Title() != "10 .1  .2  .30 " {
+		t.Errorf("Invalid masked text: %q", ip.Title())
+	}
+	if ip.Hint() != "" {
+		t.Errorf("Valid field must not display an error: %q", ip.Hint())
+	}
+
+	// backspace skips literals and empty positions
+	SimulateKey(term.KeyBackspace2, 0)
+	SimulateKey(term.KeyBackspace2, 0)
+	SimulateKey(term.KeyBackspace2, 0)
+	if ip.Title() != "10 .1  .   .   " || ip.cursorPos != 8 {
+		t.Errorf("Backspace must clear positions: %q at %v", ip.Title(), ip.cursorPos)
+	}
+	if ip.Hint() == "" {
+		t.Error("Invalid field must display the error as its hint")
+	}
+
+	// required positions
+	ActivateControl(wnd, date)
+	SimulateKey(term.KeyHome, 0)
+	SimulateKey(term.KeyDelete, 0)
+	if err := date.Validate(); err != errIncomplete {
+		t.Errorf("Date with an empty position must be incomplete: %v", err)
+	}
+	typeText("0")
+	if err := date.Validate(); err != nil {
+		t.Errorf("Date must be complete: %v", err)
+	}
+	SimulateKey(term.KeyCtrlZ, 0)
+	SimulateKey(term.KeyCtrlZ, 0)
+	if date.Title() != "12/03/4520" {
+		t.Errorf("Undo must restore the masked text: %q", date.Title())
+	}
+}
+
+func TestValidateOnClose(t *testing.T) {
+	initHeadless(t, 40, 10)
+	defer DeinitLibrary()
+
+	AddWindow(0, 0, 10, 5, "Main")
+	dlg := AddWindow(2, 1, 30, 6, "Dialog")
+	dlg.SetPack(Vertical)
+	name := CreateEditField(dlg, 20, "Joe", Fixed)
+	age := CreateEditField(dlg, 20, "", Fixed)
+	age.SetValidator(IntegerValidator(0, 150))
+	ActivateControl(dlg, name)
+
+	dlg.SetValidateOnClose(true)
+	SimulateKey(term.KeyCtrlW, 0)
+	SimulateKey(term.KeyCtrlC, 0)
+	if defaultApp.comp.topWindow() != dlg {
+		t.Fatal("Window with an invalid field must not close")
+	}
+	if !age.Active() {
+		t.Error("Invalid field must be activated")
+	}
+
+	typeText("42")
+	SimulateKey(term.KeyCtrlW, 0)
+	SimulateKey(term.KeyCtrlC, 0)
+	if defaultApp.comp.topWindow() == dlg {
+		t.Error("Window with valid fields must close")
+	}
+}
@@ -0,0 +1,94 @@
+package clui
+
+import (
+	"fmt"
+	"testing"
+
+	term "github.com/nsf/termbox-go"
+)
+
+func wheel(x, y int, key term.Key, mod term.Modifier) {
+	SimulateEvent(Event{Type: EventMouse, Key: key, X: x, Y: y, Mod: mod})
+}
+
+func TestMouseWheel(t *testing.T) {
+	initHeadless(t, 80, 25)
+	defer DeinitLibrary()
+
+	back := AddWindow(0, 0, 20, 8, "Back")
+	list := CreateListBox(back, 10, 4, 1)
+	for i := 0; i < 10; i++ {
+		list.AddItem(fmt.Sprintf("item %d", i))
+	}
+	list.SelectItem(0)
+
+	front := AddWindow(30, 0, 30, 8, "Front")
+	view := CreateTextView(front, 20, 4, 1)
+	view.SetText([]string{"a very long line that does not fit", "2", "3", "4", "5", "6"})
+
+	// the list is in the inactive window
+	lx, ly := list.Pos()
+	wheel(lx+1, ly+1, term.MouseWheelDown, 0)
+	wheel(lx+1, ly+1, term.MouseWheelDown, 0)
+	if list.topLine != 2 || list.SelectedItem() != 0 {
+		t.Errorf("Wheel must scroll the list without selection change: %v %v", list.topLine, list.SelectedItem())
+	}
+	if defaultApp.comp.topWindow() != front {
+		t.Error("Wheel must not activate the window")
+	}
+	for i := 0; i < 20; i++ {
+		wheel(lx+1, ly+1, term.MouseWheelDown, 0)
+	}
+	if _, h := list.Size(); list.topLine != 10-h {
+		t.Errorf("List must not scroll beyond the last item: %v", list.topLine)
+	}
+
+	vx, vy := view.Pos()
+	wheel(vx+1, vy+1, term.MouseWheelDown, 0)
+	wheel(vx+1, vy+1, term.MouseWheelDown, ModShift)
+	wheel(vx+1, vy+1, term.MouseWheelDown, ModShift)
+	if view.topLine != 1 || view.leftShift != 2 {
+		t.Errorf("Invalid TextView scroll: %v %v", view.topLine, view.leftShift)
+	}
+	wheel(vx+1, vy+1, term.MouseWheelUp, ModShift)
+	if view.leftShift != 1 {
+		t.Errorf("Shift+wheel up must scroll left: %v", view.leftShift)
+	}
+}
+
+func TestMouseWheelFrame(t *testing.T) {
+	initHeadless(t, 80, 25)
+	defer DeinitLibrary()
+
+	wnd := AddWindow(0, 0, 60, AutoSize, "Scrollable frame")
+	frm := CreateFrame(wnd, 50, 6, BorderNone, Fixed)
+	frm.SetPack(Vertical)
+	frm.SetScrollable(true)
+	var buttons []*Button
+	for i := 0; i < 10; i++ {
+		buttons = append(buttons, CreateButton(frm, 40, AutoSize, fmt.Sprintf("Button %d", i), 1))
+	}
+
+	_, cy, _, ch := frm.Clipper()
+	_, by := buttons[0].Pos()
+	fx, _ := frm.Pos()
+	wheel(fx+2, cy+1, term.MouseWheelDown, 0)
+	if _, y := buttons[0].Pos(); y != by-1 {
+		t.Errorf("Wheel must scroll the frame content: %v -> %v", by, y)
+	}
+	wheel(fx+2, cy+1, term.MouseWheelUp, 0)
+	wheel(fx+2, cy+1, term.MouseWheelUp, 0)
+	if _, y := buttons[0].Pos(); y != by {
+		t.Errorf("Frame must not scroll above its content: %v -> %v", by, y)
+	}
+
+	for i := 0; i < 100; i++ {
+		wheel(fx+2, cy+1, term.MouseWheelDown, 0)
+	}
+	last := buttons[len(buttons)-1]
+	_, ly := last.Pos()
+	_, lh := last.Size()
+	if ly+lh > cy+ch || ly+lh < cy+ch-1 {
+		t.Errorf("The last button must be at the bottom of the frame: %v+%v, %v+%v", ly, lh, cy, ch)
+	}
+}
@@ -1,7 +1,6 @@
 package clui
 
 import (
-	xs "github.com/huandu/xstrings"
 	term "github.com/nsf/termbox-go"
 )
 
@@ -20,8 +19,11 @@ type Window struct {
 	immovable  bool
 	fixedSize  bool
 	border     BorderStyle
+	// refuse closing while any field is invalid
+	validateOnClose bool
 
 	onClose        func(Event) bool
+	onQuit         func(Event) bool
 	onScreenResize func(Event)
 
 	onKeyDown *keyDownCb
@@ -33,8 +35,14 @@ type keyDownCb struct {
 }
 
 func CreateWindow(x, y, w, h int, title string) *Window {
+	return createWindow(nil, x, y, w, h, title)
+}
+
+// createWindow creates a Window of the App. nil App means the default one
+func createWindow(a *App, x, y, w, h int, title string) *Window {
 	wnd := new(Window)
 	wnd.BaseControl = NewBaseControl()
+	wnd.app = a
 
 	if w == AutoSize || w < 1 || w > 1000 {
 		w = 10
@@ -72,8 +80,9 @@ func (wnd *Window) buttonCount() (left, right int) {
 }
 
 func (wnd *Window) drawFrame() {
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(wnd)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	var bs BorderStyle
 	if wnd.border == BorderAuto {
@@ -87,12 +96,13 @@ func (wnd *Window) drawFrame() {
 		bs = wnd.border
 	}
 
-	DrawFrame(wnd.x, wnd.y, wnd.width, wnd.height, bs)
+	a.DrawFrame(wnd.x, wnd.y, wnd.width, wnd.height, bs)
 }
 
 func (wnd *Window) drawTitle() {
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(wnd)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
 	lb, rb := wnd.buttonCount()
 	maxw := wnd.width - 2
@@ -111,10 +121,10 @@ func (wnd *Window) drawTitle() {
 
 	fitTitle := wnd.title
 	rawText := UnColorizeText(fitTitle)
-	if xs.Len(rawText) > maxw {
+	if TextWidth(rawText) > maxw {
 		fitTitle = SliceColorized(fitTitle, 0, maxw-3) + "..."
 	}
-	DrawText(wnd.x+xshift, wnd.y, fitTitle)
+	a.DrawText(wnd.x+xshift, wnd.y, fitTitle)
 }
 
 func (wnd *Window) drawButtons() {
@@ -123,52 +133,54 @@ func (wnd *Window) drawButtons() {
 		return
 	}
 
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(wnd)
+	a.PushAttributes()
+	defer a.PopAttributes()
 
-	chars := []rune(SysObject(ObjViewButtons))
+	chars := []rune(a.SysObject(ObjViewButtons))
 	cMax, cBottom, cClose, cOpenB, cCloseB := chars[0], chars[1], chars[2], chars[3], chars[4]
 
 	// draw close button (rb can be either 1 or 0)
 	if rb != 0 {
 		pos := wnd.x + wnd.width - rb - 2
-		putCharUnsafe(pos, wnd.y, cOpenB)
-		putCharUnsafe(pos+1, wnd.y, cClose)
-		putCharUnsafe(pos+2, wnd.y, cCloseB)
+		a.putCharUnsafe(pos, wnd.y, cOpenB)
+		a.putCharUnsafe(pos+1, wnd.y, cClose)
+		a.putCharUnsafe(pos+2, wnd.y, cCloseB)
 	}
 
 	if lb > 0 {
 		pos := wnd.x + 1
-		putCharUnsafe(pos, wnd.y, cOpenB)
+		a.putCharUnsafe(pos, wnd.y, cOpenB)
 		pos += 1
 		if wnd.buttons&ButtonBottom == ButtonBottom {
-			putCharUnsafe(pos, wnd.y, cBottom)
+			a.putCharUnsafe(pos, wnd.y, cBottom)
 			pos += 1
 		}
 		if wnd.buttons&ButtonMaximize == ButtonMaximize {
-			putCharUnsafe(pos, wnd.y, cMax)
+			a.putCharUnsafe(pos, wnd.y, cMax)
 			pos += 1
 		}
-		putCharUnsafe(pos, wnd.y, cCloseB)
+		a.putCharUnsafe(pos, wnd.y, cCloseB)
 	}
 }
 
 // Draw repaints the control on the screen
 func (wnd *Window) Draw() {
-	WindowManager().BeginUpdate()
-	defer WindowManager().EndUpdate()
-	PushAttributes()
-	defer PopAttributes()
+	a := AppOf(wnd)
+	a.comp.BeginUpdate()
+	defer a.comp.EndUpdate()
+	a.PushAttributes()
+	defer a.PopAttributes()
 
-	fg, bg := RealColor(wnd.fg, wnd.Style(), ColorViewText), RealColor(wnd.bg, wnd.Style(), ColorViewBack)
-	SetBackColor(bg)
+	fg, bg := a.RealColor(wnd.fg, wnd.Style(), ColorViewText), a.RealColor(wnd.bg, wnd.Style(), ColorViewBack)
+	a.SetBackColor(bg)
 
-	FillRect(wnd.x, wnd.y, wnd.width, wnd.height, ' ')
+	a.FillRect(wnd.x, wnd.y, wnd.width, wnd.height, ' ')
 
 	wnd.DrawChildren()
 
-	SetBackColor(bg)
-	SetTextColor(fg)
+	a.SetBackColor(bg)
+	a.SetTextColor(fg)
 
 	wnd.drawFrame()
 	wnd.drawTitle()
@@ -258,12 +270,20 @@ func (c *Window) ProcessEvent(ev Event) bool {
 		c.ResizeChildren()
 		c.PlaceChildren()
 	case EventClose:
+		// X is 1 if a user closes the window
+		if ev.X == 1 && c.validateOnClose && !c.Validate() {
+			return false
+		}
 		if c.onClose != nil {
 			if !c.onClose(ev) {
 				return false
 			}
 		}
 		return true
+	case EventMouseEnter, EventMouseLeave:
+		// the mouse cursor is over the window itself, not over its
+		// active control
+		return true
 	case EventKey:
 		if ev.Key == term.KeyTab || ev.Key == term.KeyArrowUp || ev.Key == term.KeyArrowDown {
 			if SendEventToChild(c, ev) {
@@ -324,6 +344,59 @@ func (w *Window) OnClose(fn func(Event) bool) {
 	w.onClose = fn
 }
 
+// Validate checks all visible and enabled controls of the Window that can
+// validate their content(e.g, EditField with a validator or an input
+// mask). The first invalid control is activated, so a user can fix it.
+// Returns true if all controls are valid
+func (w *Window) Validate() bool {
+	ctrl := findInvalidControl(w)
+	if ctrl == nil {
+		return true
+	}
+
+	if !ctrl.Active() {
+		ActivateControl(w, ctrl)
+	}
+	a := AppOf(w)
+	a.InvalidateScreen()
+	return false
+}
+
+func findInvalidControl(parent Control) Control {
+	for _, ctrl := range parent.Children() {
+		if !ctrl.Visible() || !ctrl.Enabled() {
+			continue
+		}
+		if v, ok := ctrl.(validatable); ok && v.Validate() != nil {
+			return ctrl
+		}
+		if res := findInvalidControl(ctrl); res != nil {
+			return res
+		}
+	}
+	return nil
+}
+
+// ValidateOnClose returns true if a user cannot close the Window while
+// any of its controls is invalid
+func (w *Window) ValidateOnClose() bool {
+	return w.validateOnClose
+}
+
+// SetValidateOnClose makes the Window refuse closing with the close button
+// or a hotkey while any of its controls is invalid. See Validate
+func (w *Window) SetValidateOnClose(validate bool) {
+	w.validateOnClose = validate
+}
+
+// OnQuit sets the callback that is called when the application is about
+// to quit(e.g, Stop is called or the last window is closed). If the
+// callback returns false, the application keeps running. It is not called
+// when the main loop context is cancelled
+func (w *Window) OnQuit(fn func(Event) bool) {
+	w.onQuit = fn
+}
+
 // OnKeyDown sets the callback that is called when a user presses a key
 // while the Window is active
 func (w *Window) OnKeyDown(fn func(Event, interface{}) bool, data interface{}) {
@@ -356,12 +429,13 @@ func (w *Window) SetMaximized(maximize bool) {
 		return
 	}
 
+	a := AppOf(w)
 	if maximize {
 		w.origX, w.origY = w.Pos()
 		w.origWidth, w.origHeight = w.Size()
 		w.maximized = true
-		w.SetPos(0, 0)
-		width, height := ScreenSize()
+		x, y, width, height := a.WorkArea()
+		w.SetPos(x, y)
 		w.SetSize(width, height)
 	} else {
 		w.maximized = false
@@ -390,13 +464,15 @@ func (w *Window) SetVisible(visible bool) {
 	}
 
 	w.hidden = !visible
+	w.Invalidate()
+	a := AppOf(w)
 	if w.hidden {
 		w.SetModal(false)
-		if WindowManager().topWindow() == w {
-			WindowManager().moveActiveWindowToBottom()
+		if a.comp.topWindow() == w {
+			a.comp.moveActiveWindowToBottom()
 		}
 	} else {
-		WindowManager().activateWindow(w)
+		a.comp.activateWindow(w)
 	}
 }
 